@@ -62,12 +62,24 @@ CREATE TABLE IF NOT EXISTS payments (
   currency TEXT NOT NULL DEFAULT 'IDR',
   proof_of_payment TEXT,
   status TEXT NOT NULL DEFAULT 'pending',
+  -- Gateway that processes the payment and its identifiers there
+  provider TEXT NOT NULL DEFAULT '',
+  provider_order_id TEXT,
+  provider_transaction_id TEXT NOT NULL DEFAULT '',
+  payment_type TEXT NOT NULL DEFAULT '',
+  -- Status exactly as last reported by the gateway, before mapping to status
+  raw_status TEXT NOT NULL DEFAULT '',
+  redirect_url TEXT NOT NULL DEFAULT '',
+  -- Manual transfers: proof_of_payment holds the storage key of the receipt an admin reviews
+  reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  reviewed_at TIMESTAMPTZ,
+  review_note TEXT NOT NULL DEFAULT '',
   paid_at TIMESTAMPTZ,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
--- Invitation (1 customer = 1 invitation)
+-- Invitations (a customer may own several, up to the plan's max_invitations)
 CREATE TABLE IF NOT EXISTS invitations (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
   customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
@@ -77,27 +89,144 @@ CREATE TABLE IF NOT EXISTS invitations (
   event_date DATE,
   theme_key TEXT NOT NULL DEFAULT 'elegant',
   is_published BOOLEAN NOT NULL DEFAULT false,
+  -- content is the draft being edited; published_content is what guests see
   content JSONB NOT NULL DEFAULT '{}'::jsonb,
+  published_content JSONB,
+  published_at TIMESTAMPTZ,
+  -- publish_at / archive_at are applied by the in-process scheduler and cleared once done
+  publish_at TIMESTAMPTZ,
+  archive_at TIMESTAMPTZ,
+  require_wish_approval BOOLEAN NOT NULL DEFAULT false,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   UNIQUE (customer_id, slug)
 );
 
+-- Every saved version of an invitation's content; older rows are pruned per the plan's revision_history
+CREATE TABLE IF NOT EXISTS invitation_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  invitation_id UUID NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  content JSONB NOT NULL DEFAULT '{}'::jsonb,
+  author_type TEXT NOT NULL CHECK (author_type IN ('customer', 'admin', 'system')),
+  author_id TEXT NOT NULL DEFAULT '',
+  restored_from INTEGER,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (invitation_id, version)
+);
+
+-- Invited guests with a personal link token (?to=<token>)
+CREATE TABLE IF NOT EXISTS guests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  invitation_id UUID NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  phone TEXT,
+  group_name TEXT,
+  seat TEXT,
+  max_party_size INTEGER NOT NULL DEFAULT 1,
+  token TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
 CREATE TABLE IF NOT EXISTS rsvps (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
   invitation_id UUID NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+  guest_id UUID REFERENCES guests(id) ON DELETE SET NULL,
+  -- guest:<guest_id> for personal links, device:<device_id>:<name> otherwise
+  identity_key TEXT NOT NULL,
   guest_name TEXT NOT NULL,
   attendance TEXT NOT NULL DEFAULT 'attending',
   guests_count INTEGER NOT NULL DEFAULT 1,
   message TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (invitation_id, identity_key)
+);
+
+-- Previous answers of a resubmitted RSVP
+CREATE TABLE IF NOT EXISTS rsvp_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rsvp_id UUID NOT NULL REFERENCES rsvps(id) ON DELETE CASCADE,
+  guest_name TEXT NOT NULL,
+  attendance TEXT NOT NULL,
+  guests_count INTEGER NOT NULL,
+  message TEXT,
+  answered_at TIMESTAMPTZ NOT NULL,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
 CREATE TABLE IF NOT EXISTS wishes (
   id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
   invitation_id UUID NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+  rsvp_id UUID UNIQUE REFERENCES rsvps(id) ON DELETE SET NULL,
   guest_name TEXT NOT NULL,
   message TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'hidden')),
+  pinned BOOLEAN NOT NULL DEFAULT false,
+  -- Why the moderation filter held the wish for review
+  flag_reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Files uploaded by customers; invitation content may only embed media its customer owns
+CREATE TABLE IF NOT EXISTS media (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('image', 'audio')),
+  content_type TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  storage_key TEXT NOT NULL UNIQUE,
+  url TEXT NOT NULL,
+  original_name TEXT NOT NULL DEFAULT '',
+  -- Photos get resized variants from the background media workers; audio is ready on upload
+  width INTEGER,
+  height INTEGER,
+  variants JSONB NOT NULL DEFAULT '[]'::jsonb,
+  processing_status TEXT NOT NULL DEFAULT 'pending' CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed')),
+  processing_error TEXT NOT NULL DEFAULT '',
+  processing_started_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Domains customers bring for their invitations; only verified ones are served
+CREATE TABLE IF NOT EXISTS custom_domains (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  hostname TEXT NOT NULL,
+  -- Expected value of the DNS TXT record that proves control of the domain
+  verification_token TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'failed')),
+  verified_at TIMESTAMPTZ,
+  last_checked_at TIMESTAMPTZ,
+  last_error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (customer_id, hostname)
+);
+
+-- TLS certificates, the ACME account key and challenge tokens, shared by every instance
+CREATE TABLE IF NOT EXISTS acme_cache (
+  key TEXT PRIMARY KEY,
+  data BYTEA NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Every status notification a payment received, applied or not; gateways retry and reorder them
+CREATE TABLE IF NOT EXISTS payment_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL DEFAULT '',
+  -- webhook, poll (a progress check that changed the status), review or refund
+  source TEXT NOT NULL,
+  -- Admin who reviewed or refunded the payment
+  actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  -- Identifies a notification across retries; a repeated key is not applied twice
+  event_key TEXT,
+  raw_status TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  from_status TEXT NOT NULL,
+  applied BOOLEAN NOT NULL DEFAULT false,
+  payload TEXT NOT NULL DEFAULT '',
   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
@@ -105,19 +234,136 @@ CREATE INDEX IF NOT EXISTS idx_invitations_customer_slug ON invitations(customer
 CREATE INDEX IF NOT EXISTS idx_invitations_customer_event_date ON invitations(customer_id, event_date);
 CREATE INDEX IF NOT EXISTS idx_invitations_customer_search_name ON invitations(customer_id, search_name);
 CREATE INDEX IF NOT EXISTS idx_rsvps_invitation_id ON rsvps(invitation_id);
+CREATE INDEX IF NOT EXISTS idx_guests_invitation_id ON guests(invitation_id);
+CREATE INDEX IF NOT EXISTS idx_rsvp_revisions_rsvp_id ON rsvp_revisions(rsvp_id);
 CREATE INDEX IF NOT EXISTS idx_wishes_invitation_id ON wishes(invitation_id);
+CREATE INDEX IF NOT EXISTS idx_media_customer ON media(customer_id, created_at DESC);
+-- Several customers may claim a hostname, but only one can prove it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_domains_verified_hostname ON custom_domains(hostname) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_payment_events_payment ON payment_events(payment_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_events_key ON payment_events(provider, event_key);
+
+-- Upgrades for databases created before the columns above existed
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS guest_id UUID REFERENCES guests(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_rsvps_guest_id ON rsvps(guest_id);
+
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS identity_key TEXT;
+ALTER TABLE rsvps ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE wishes ADD COLUMN IF NOT EXISTS rsvp_id UUID UNIQUE REFERENCES rsvps(id) ON DELETE SET NULL;
+ALTER TABLE wishes ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'hidden'));
+ALTER TABLE wishes ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE wishes ADD COLUMN IF NOT EXISTS flag_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS require_wish_approval BOOLEAN NOT NULL DEFAULT false;
+DROP INDEX IF EXISTS idx_wishes_invitation_public;
+CREATE INDEX IF NOT EXISTS idx_wishes_invitation_public_keyset ON wishes(invitation_id, pinned DESC, created_at DESC, id DESC) WHERE status = 'approved';
+-- Collapse duplicate answers from the same invited guest into revisions of the latest one
+INSERT INTO rsvp_revisions (rsvp_id, guest_name, attendance, guests_count, message, answered_at)
+SELECT latest.id, old.guest_name, old.attendance, old.guests_count, old.message, old.created_at
+FROM rsvps old
+JOIN LATERAL (
+  SELECT r.id FROM rsvps r WHERE r.guest_id = old.guest_id ORDER BY r.created_at DESC LIMIT 1
+) latest ON latest.id <> old.id
+WHERE old.guest_id IS NOT NULL AND old.identity_key IS NULL;
+DELETE FROM rsvps old
+WHERE old.guest_id IS NOT NULL AND old.identity_key IS NULL
+  AND EXISTS (SELECT 1 FROM rsvps newer WHERE newer.guest_id = old.guest_id AND newer.created_at > old.created_at);
+UPDATE rsvps
+SET identity_key = CASE WHEN guest_id IS NOT NULL THEN 'guest:' || guest_id ELSE 'legacy:' || id END
+WHERE identity_key IS NULL;
+ALTER TABLE rsvps ALTER COLUMN identity_key SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rsvps_invitation_identity ON rsvps(invitation_id, identity_key);
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS published_content JSONB;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS archive_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_invitations_publish_at ON invitations(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_invitations_archive_at ON invitations(archive_at) WHERE archive_at IS NOT NULL;
+UPDATE invitations
+SET published_content = content, published_at = updated_at
+WHERE is_published AND published_content IS NULL;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS width INTEGER;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS height INTEGER;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_status TEXT NOT NULL DEFAULT 'pending' CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed'));
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMPTZ;
+UPDATE media SET processing_status = 'ready' WHERE kind = 'audio' AND processing_status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_media_processing ON media(created_at) WHERE processing_status IN ('pending', 'processing');
+-- Start the history of existing invitations from their current content
+INSERT INTO invitation_revisions (invitation_id, version, content, author_type, created_at)
+SELECT i.id, 1, i.content, 'system', i.updated_at
+FROM invitations i
+WHERE NOT EXISTS (SELECT 1 FROM invitation_revisions r WHERE r.invitation_id = i.id);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS provider_order_id TEXT;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS provider_transaction_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS payment_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS raw_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS redirect_url TEXT NOT NULL DEFAULT '';
+-- Parses text as JSON, or returns NULL when it is not valid JSON, so one malformed legacy value
+-- cannot abort the migration below
+CREATE OR REPLACE FUNCTION try_jsonb(value TEXT) RETURNS JSONB AS $$
+BEGIN
+  RETURN value::jsonb;
+EXCEPTION WHEN invalid_text_representation THEN
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- Move Midtrans details out of proof_of_payment, which used to hold them as JSON or as
+-- "midtrans:<order id>"; an order id seen on several payments goes to the newest one. Values
+-- that look like JSON but do not parse are left where they are. Only rows from before the provider
+-- column qualify: manual transfers keep their receipt key in proof_of_payment
+WITH parsed AS (
+  SELECT id, created_at, proof_of_payment,
+    CASE WHEN proof_of_payment LIKE '{%' THEN try_jsonb(proof_of_payment) END AS meta
+  FROM payments
+  WHERE provider = '' AND provider_order_id IS NULL AND COALESCE(proof_of_payment, '') <> ''
+), meta AS (
+  SELECT id, created_at,
+    CASE WHEN meta IS NOT NULL THEN COALESCE(NULLIF(meta ->> 'provider', ''), 'midtrans') ELSE 'midtrans' END AS provider,
+    CASE
+      WHEN proof_of_payment LIKE '{%' THEN meta ->> 'order_id'
+      WHEN proof_of_payment LIKE 'midtrans:%' THEN substr(proof_of_payment, length('midtrans:') + 1)
+      ELSE proof_of_payment
+    END AS order_id,
+    COALESCE(meta ->> 'redirect_url', '') AS redirect_url
+  FROM parsed
+), legacy AS (
+  SELECT DISTINCT ON (provider, order_id) id, provider, order_id, redirect_url
+  FROM meta
+  WHERE COALESCE(order_id, '') <> ''
+    AND NOT EXISTS (
+      SELECT 1 FROM payments taken
+      WHERE taken.provider = meta.provider AND taken.provider_order_id = meta.order_id
+    )
+  ORDER BY provider, order_id, created_at DESC
+)
+UPDATE payments p
+SET provider = legacy.provider,
+    provider_order_id = legacy.order_id,
+    redirect_url = legacy.redirect_url,
+    proof_of_payment = NULL
+FROM legacy
+WHERE p.id = legacy.id;
+-- Order ids are only unique within one gateway
+DROP INDEX IF EXISTS idx_payments_provider_order_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_order ON payments(provider, provider_order_id);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS review_note TEXT NOT NULL DEFAULT '';
+ALTER TABLE payment_events ADD COLUMN IF NOT EXISTS actor_id UUID REFERENCES users(id) ON DELETE SET NULL;
 
 -- Seed default plans (idempotent)
 INSERT INTO plans (code, name, price_amount, currency, features, limits) VALUES
   ('basic', 'Basic', 49000, 'IDR',
    '[{"label":"1 template undangan","included":true},{"label":"Countdown timer","included":true},{"label":"RSVP tamu","included":true},{"label":"Galeri foto (maks. 4)","included":true},{"label":"Musik latar","included":false},{"label":"Love story","included":false},{"label":"Fitur hadiah","included":false},{"label":"Custom domain","included":false}]'::jsonb,
-   '{"gallery_photos": 4, "templates": "1"}'::jsonb),
+   '{"gallery_photos": 4, "templates": "1", "max_invitations": 1, "revision_history": 5, "upload_max_mb": 2, "max_rsvps": 150, "max_wishes": 150, "max_content_kb": 64, "max_story_entries": 3, "custom_domain": false, "max_custom_domains": 0}'::jsonb),
   ('premium', 'Premium', 99000, 'IDR',
    '[{"label":"Semua template undangan","included":true},{"label":"Countdown timer","included":true},{"label":"RSVP tamu","included":true},{"label":"Galeri foto (maks. 8)","included":true},{"label":"Musik latar","included":true},{"label":"Love story","included":true},{"label":"Fitur hadiah","included":true},{"label":"Custom domain","included":false}]'::jsonb,
-   '{"gallery_photos": 8, "templates": "all"}'::jsonb),
+   '{"gallery_photos": 8, "templates": "all", "max_invitations": 3, "revision_history": 30, "upload_max_mb": 10, "max_rsvps": 500, "max_wishes": 500, "max_content_kb": 256, "max_story_entries": 10, "custom_domain": false, "max_custom_domains": 0}'::jsonb),
   ('exclusive', 'Exclusive', 150000, 'IDR',
    '[{"label":"Semua template undangan","included":true},{"label":"Countdown timer","included":true},{"label":"RSVP tamu","included":true},{"label":"Galeri foto (maks. 12)","included":true},{"label":"Musik latar","included":true},{"label":"Love story","included":true},{"label":"Fitur hadiah","included":true},{"label":"Custom domain","included":true}]'::jsonb,
-   '{"gallery_photos": 12, "templates": "all"}'::jsonb)
+   '{"gallery_photos": 12, "templates": "all", "max_invitations": 5, "revision_history": 100, "upload_max_mb": 20, "max_rsvps": 2000, "max_wishes": 2000, "max_content_kb": 512, "max_story_entries": 30, "custom_domain": true, "max_custom_domains": 2}'::jsonb)
 ON CONFLICT (code) DO UPDATE SET
   name = EXCLUDED.name,
   price_amount = EXCLUDED.price_amount,
@@ -9,7 +9,7 @@ INSERT INTO plans (code, name, price_amount, currency, features, limits) VALUES
      {"label": "Background musik undangan", "included": false},
      {"label": "Timeline cerita cinta (Love Story)", "included": false}
    ]'::jsonb,
-   '{"gallery_photos": 4, "love_story": false, "music": false, "gifts": false, "templates": "1"}'::jsonb),
+   '{"gallery_photos": 4, "love_story": false, "music": false, "gifts": false, "templates": "1", "max_invitations": 1, "revision_history": 5, "upload_max_mb": 2, "max_rsvps": 150, "max_wishes": 150, "max_content_kb": 64, "max_story_entries": 3, "custom_domain": false, "max_custom_domains": 0}'::jsonb),
 
   ('premium', 'Premium', 99000, 'IDR',
    '[
@@ -21,7 +21,7 @@ INSERT INTO plans (code, name, price_amount, currency, features, limits) VALUES
      {"label": "Background musik undangan", "included": true},
      {"label": "Timeline cerita cinta (Love Story)", "included": true}
    ]'::jsonb,
-   '{"gallery_photos": 8, "love_story": true, "music": true, "gifts": true, "templates": "all"}'::jsonb),
+   '{"gallery_photos": 8, "love_story": true, "music": true, "gifts": true, "templates": "all", "max_invitations": 3, "revision_history": 30, "upload_max_mb": 10, "max_rsvps": 500, "max_wishes": 500, "max_content_kb": 256, "max_story_entries": 10, "custom_domain": false, "max_custom_domains": 0}'::jsonb),
 
   ('exclusive', 'Exclusive', 150000, 'IDR',
    '[
@@ -34,7 +34,7 @@ INSERT INTO plans (code, name, price_amount, currency, features, limits) VALUES
      {"label": "Timeline cerita cinta (Love Story)", "included": true},
      {"label": "Reminder tamu otomatis", "included": true}
    ]'::jsonb,
-   '{"gallery_photos": 12, "love_story": true, "music": true, "gifts": true, "templates": "all"}'::jsonb)
+   '{"gallery_photos": 12, "love_story": true, "music": true, "gifts": true, "templates": "all", "max_invitations": 5, "revision_history": 100, "upload_max_mb": 20, "max_rsvps": 2000, "max_wishes": 2000, "max_content_kb": 512, "max_story_entries": 30, "custom_domain": true, "max_custom_domains": 2}'::jsonb)
 
 ON CONFLICT (code) DO UPDATE SET
   name = EXCLUDED.name,
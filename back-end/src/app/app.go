@@ -5,23 +5,109 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/config"
 )
 
+const shutdownTimeout = 15 * time.Second
+
 func Run() error {
-	handler, cleanup, err := buildHandler(context.Background())
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	parts, err := buildComponents(ctx)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		if closeErr := cleanup(); closeErr != nil {
+		if closeErr := parts.cleanup(); closeErr != nil {
 			log.Printf("close db: %v", closeErr)
 		}
 	}()
 
+	servers := newServers(parts)
+	for _, server := range servers {
+		// Shutdown waits for active connections to go idle, which live wishes streams never do.
+		server.RegisterOnShutdown(parts.broker.Close)
+	}
+
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		parts.scheduler.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		parts.media.Run(ctx)
+	}()
+
+	serveErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			if server.TLSConfig != nil {
+				log.Printf("server listening on %s (tls)", server.Addr)
+				serveErr <- server.ListenAndServeTLS("", "")
+				return
+			}
+			log.Printf("server listening on %s", server.Addr)
+			serveErr <- server.ListenAndServe()
+		}(server)
+	}
+
+	select {
+	case err := <-serveErr:
+		stop()
+		for _, server := range servers {
+			_ = server.Close()
+		}
+		background.Wait()
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("graceful shutdown of %s: %v; closing remaining connections", server.Addr, err)
+			_ = server.Close()
+		}
+	}
+	background.Wait()
+
+	return nil
+}
+
+// newServers listens on PORT, or with TLS_MODE=acme serves HTTPS on TLS_ADDR with certificates
+// issued on demand and answers ACME challenges on TLS_HTTP_ADDR, redirecting other plain HTTP
+// requests to HTTPS.
+func newServers(parts components) []*http.Server {
+	if parts.certs != nil {
+		return []*http.Server{
+			{
+				Addr:              parts.tls.Addr,
+				Handler:           parts.handler,
+				TLSConfig:         parts.serverTLS,
+				ReadHeaderTimeout: 5 * time.Second,
+			},
+			{
+				Addr:              parts.tls.HTTPAddr,
+				Handler:           parts.certs.HTTPHandler(nil),
+				ReadHeaderTimeout: 5 * time.Second,
+			},
+		}
+	}
+
 	port := strings.TrimSpace(config.GetEnv("PORT"))
 	if port == "" {
 		port = "8080"
@@ -29,17 +115,9 @@ func Run() error {
 	if !strings.HasPrefix(port, ":") {
 		port = ":" + port
 	}
-
-	server := &http.Server{
+	return []*http.Server{{
 		Addr:              port,
-		Handler:           handler,
+		Handler:           parts.handler,
 		ReadHeaderTimeout: 5 * time.Second,
-	}
-
-	log.Printf("server listening on %s", port)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("server: %w", err)
-	}
-
-	return nil
+	}}
 }
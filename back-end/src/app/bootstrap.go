@@ -2,47 +2,76 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/acme/autocert"
+	"gorm.io/gorm"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/auth"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/certs"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/config"
 	adminHandlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers/admin"
 	customerHandlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers/customer"
 	publicHandlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers/public"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/http/routes"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/moderation"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/realtime"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/scheduler"
 	serviceBootstrap "github.com/proxima-labs/wedding-invitation-back-end/src/service"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/worker"
 )
 
-func buildHandler(ctx context.Context) (http.Handler, func() error, error) {
+// components are the long-lived parts of the process that Run starts and stops.
+type components struct {
+	handler   http.Handler
+	scheduler *scheduler.Publication
+	media     *worker.MediaPool
+	// broker feeds the live wishes streams, which are closed when the server shuts down.
+	broker realtime.Broker
+	// certs is set when TLS_MODE=acme; the app then terminates TLS itself.
+	certs *autocert.Manager
+	// serverTLS serves the wildcard certificate for base-domain subdomains and certs for the rest.
+	serverTLS *tls.Config
+	tls       config.TLSConfig
+	cleanup   func() error
+}
+
+func buildComponents(ctx context.Context) (components, error) {
 	_ = godotenv.Load()
 
 	dbConn, err := config.NewDatabase(ctx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("db: %w", err)
+		return components{}, fmt.Errorf("db: %w", err)
 	}
 
 	sqlDB, err := dbConn.DB()
 	if err != nil {
-		return nil, nil, fmt.Errorf("sql db: %w", err)
+		return components{}, fmt.Errorf("sql db: %w", err)
 	}
 
 	jwtSecret, err := config.RequireEnv("ADMIN_JWT_SECRET")
 	if err != nil {
 		_ = sqlDB.Close()
-		return nil, nil, err
+		return components{}, err
 	}
 
 	customerJwtSecret, err := config.RequireEnv("CUSTOMER_JWT_SECRET")
 	if err != nil {
 		_ = sqlDB.Close()
-		return nil, nil, err
+		return components{}, err
 	}
 
 	jwtConfig := auth.Config{
@@ -59,22 +88,98 @@ func buildHandler(ctx context.Context) (http.Handler, func() error, error) {
 		RefreshTTL:   30 * 24 * time.Hour,
 	}
 
-	midtransConfig := config.BuildMidtransConfig()
-	var midtransService *external.MidtransService
-	if !midtransConfig.IsConfigured() {
-		log.Println("MIDTRANS_CLIENT_KEY or MIDTRANS_SERVER_KEY not set; customer payment API will be unavailable")
-	} else {
-		midtransService = external.NewMidtransService(
-			midtransConfig.BaseURL,
-			midtransConfig.ClientKey,
-			midtransConfig.ServerKey,
-			nil,
-		)
-		log.Printf("midtrans base url: %s", midtransConfig.BaseURL)
+	checkoutProvider := config.PaymentProvider()
+	paymentGateways, err := newPaymentGateways(checkoutProvider)
+	if err != nil {
+		_ = sqlDB.Close()
+		return components{}, err
+	}
+	var manualTransfer *customerService.ManualTransferAccount
+	if transferConfig := config.BuildManualTransferConfig(); transferConfig.IsConfigured() {
+		manualTransfer = &customerService.ManualTransferAccount{
+			BankName:      transferConfig.BankName,
+			AccountNumber: transferConfig.AccountNumber,
+			AccountName:   transferConfig.AccountName,
+		}
+	}
+
+	tenantCacheSize := repository.DefaultTenantCacheSize
+	if raw := config.GetEnv("TENANT_CACHE_SIZE"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			_ = sqlDB.Close()
+			return components{}, fmt.Errorf("TENANT_CACHE_SIZE must be a positive number")
+		}
+		tenantCacheSize = parsed
+	}
+	tenantCacheTTL := repository.DefaultTenantCacheTTL
+	if raw := config.GetEnv("TENANT_CACHE_TTL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < 0 {
+			_ = sqlDB.Close()
+			return components{}, fmt.Errorf("TENANT_CACHE_TTL must be a non-negative duration")
+		}
+		tenantCacheTTL = parsed
+	}
+	var tenantCache *repository.TenantCache
+	if tenantCacheTTL > 0 {
+		tenantCache = repository.NewTenantCache(tenantCacheSize, tenantCacheTTL)
 	}
 
-	repos := repository.NewRegistry(dbConn)
-	svc := serviceBootstrap.NewRegistry(repos, jwtConfig, customerJwtConfig, midtransService)
+	repos := repository.NewRegistry(dbConn, tenantCache)
+
+	wordList, err := moderation.NewWordList(strings.Split(config.GetEnv("MODERATION_WORDLIST_FILES"), ",")...)
+	if err != nil {
+		_ = sqlDB.Close()
+		return components{}, fmt.Errorf("moderation word list: %w", err)
+	}
+	moderator := moderation.Pipeline{
+		wordList,
+		moderation.LinkFilter{},
+		moderation.RepeatFilter{},
+		moderation.DuplicateFilter{History: repos.Wish},
+	}
+
+	listenCtx, stopListening := context.WithCancel(ctx)
+	broker, err := newWishBroker(listenCtx, dbConn)
+	if err != nil {
+		stopListening()
+		_ = sqlDB.Close()
+		return components{}, fmt.Errorf("wish broker: %w", err)
+	}
+
+	mediaConfig := config.BuildMediaConfig()
+	mediaStorage, err := newMediaStorage(mediaConfig)
+	if err != nil {
+		stopListening()
+		_ = sqlDB.Close()
+		return components{}, fmt.Errorf("media storage: %w", err)
+	}
+	receiptStorage, err := newReceiptStorage(mediaConfig)
+	if err != nil {
+		stopListening()
+		_ = sqlDB.Close()
+		return components{}, fmt.Errorf("receipt storage: %w", err)
+	}
+	if receiptStorage == nil && manualTransfer != nil {
+		log.Println("MEDIA_S3_PRIVATE_BUCKET not set; manual transfers are disabled")
+		manualTransfer = nil
+	}
+
+	mediaWorkers := worker.DefaultMediaWorkers
+	if raw := config.GetEnv("MEDIA_WORKERS"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			stopListening()
+			_ = sqlDB.Close()
+			return components{}, fmt.Errorf("MEDIA_WORKERS must be a positive number")
+		}
+		mediaWorkers = parsed
+	}
+	mediaPool := &worker.MediaPool{Repo: repos.Media, Storage: mediaStorage, Workers: mediaWorkers}
+
+	baseDomains := config.BaseDomains()
+	svc := serviceBootstrap.NewRegistry(repos, jwtConfig, customerJwtConfig, paymentGateways, checkoutProvider, manualTransfer, moderator, broker, mediaStorage, receiptStorage, mediaPool, baseDomains)
 
 
 	customerHandlers.ConfigureServices(customerHandlers.Services{
@@ -83,15 +188,22 @@ func buildHandler(ctx context.Context) (http.Handler, func() error, error) {
 		Payment:    svc.CustomerPayment,
 		Plan:       svc.CustomerPlan,
 		Enforcer:   svc.CustomerPlanEnforce,
+		Guest:      svc.Guest,
+		Rsvp:       svc.CustomerRsvp,
+		Wish:       svc.CustomerWish,
+		Media:      svc.CustomerMedia,
+		Domain:     svc.CustomDomain,
 		JwtConfig:  customerJwtConfig,
 	})
 	adminHandlers.ConfigureServices(adminHandlers.Services{
-		Auth:       svc.AdminAuth,
-		User:       svc.AdminUser,
-		Invitation: svc.AdminInvitation,
-		Customer:   svc.AdminCustomer,
-		Payment:    svc.AdminPayment,
-		JwtConfig:  jwtConfig,
+		Auth:        svc.AdminAuth,
+		User:        svc.AdminUser,
+		Invitation:  svc.AdminInvitation,
+		Customer:    svc.AdminCustomer,
+		Payment:     svc.AdminPayment,
+		Rsvp:        svc.AdminRsvp,
+		TenantCache: tenantCache,
+		JwtConfig:   jwtConfig,
 	})
 	publicHandlers.ConfigureServices(publicHandlers.Services{
 		Customer:         svc.Customer,
@@ -99,13 +211,205 @@ func buildHandler(ctx context.Context) (http.Handler, func() error, error) {
 		Payment:          svc.CustomerPayment,
 		PublicInvitation: svc.PublicInvitation,
 		Plan:             svc.PublicPlan,
+		Media:            svc.CustomerMedia,
 	})
 
 	router := routes.SetupRouter(dbConn)
+	if local, ok := mediaStorage.(*storage.Local); ok {
+		router.GET(local.RoutePath()+"/*key", gin.WrapH(local.Handler()))
+	}
+
+	interval := scheduler.DefaultInterval
+	if raw := strings.TrimSpace(config.GetEnv("SCHEDULER_INTERVAL")); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			stopListening()
+			_ = sqlDB.Close()
+			return components{}, fmt.Errorf("SCHEDULER_INTERVAL: %w", err)
+		}
+		interval = parsed
+	}
+
+	tlsConfig := config.BuildTLSConfig()
+	var certManager *autocert.Manager
+	var serverTLS *tls.Config
+	switch tlsConfig.Mode {
+	case config.TLSModeOff:
+	case config.TLSModeACME:
+		certManager, err = certs.NewManager(certs.Options{
+			DirectoryURL: tlsConfig.ACMEDirectory,
+			Email:        tlsConfig.ACMEEmail,
+			CABundle:     tlsConfig.ACMECABundle,
+			Hosts:        tlsConfig.Hosts,
+			BaseDomains:  baseDomains,
+		}, certs.Cache{Repo: repos.AcmeCache}, svc.Customer)
+		if err != nil {
+			stopListening()
+			_ = sqlDB.Close()
+			return components{}, fmt.Errorf("tls: %w", err)
+		}
+
+		var wildcard *certs.Wildcard
+		if tlsConfig.WildcardCert != "" || tlsConfig.WildcardKey != "" {
+			wildcard, err = certs.LoadWildcard(tlsConfig.WildcardCert, tlsConfig.WildcardKey, baseDomains)
+			if err != nil {
+				stopListening()
+				_ = sqlDB.Close()
+				return components{}, fmt.Errorf("tls: %w", err)
+			}
+		} else if len(baseDomains) > 0 {
+			log.Println("TLS_WILDCARD_CERT not set; customer subdomains of BASE_DOMAINS will fail the TLS handshake")
+		}
+		serverTLS = certs.TLSConfig(certManager, wildcard)
+	default:
+		stopListening()
+		_ = sqlDB.Close()
+		return components{}, fmt.Errorf("unknown TLS_MODE %q", tlsConfig.Mode)
+	}
 
 	cleanup := func() error {
+		stopListening()
 		return sqlDB.Close()
 	}
 
-	return router, cleanup, nil
+	return components{
+		handler:   router,
+		scheduler: &scheduler.Publication{Repo: repos.Invitation, Interval: interval},
+		media:     mediaPool,
+		broker:    broker,
+		certs:     certManager,
+		serverTLS: serverTLS,
+		tls:       tlsConfig,
+		cleanup:   cleanup,
+	}, nil
+}
+
+// newPaymentGateways returns every payment gateway with credentials set, so webhooks for earlier
+// payments keep working after PAYMENT_PROVIDER switches checkout to another one.
+func newPaymentGateways(checkoutProvider string) ([]external.PaymentGateway, error) {
+	if checkoutProvider != config.PaymentProviderMidtrans && checkoutProvider != config.PaymentProviderXendit {
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q", checkoutProvider)
+	}
+
+	gateways := make([]external.PaymentGateway, 0, 2)
+	midtransConfig := config.BuildMidtransConfig()
+	if midtransConfig.IsConfigured() {
+		gateways = append(gateways, external.NewMidtransService(
+			midtransConfig.BaseURL,
+			midtransConfig.ClientKey,
+			midtransConfig.ServerKey,
+			nil,
+		))
+		log.Printf("midtrans base url: %s", midtransConfig.BaseURL)
+	} else if checkoutProvider == config.PaymentProviderMidtrans {
+		log.Println("MIDTRANS_CLIENT_KEY or MIDTRANS_SERVER_KEY not set; customer payment API will be unavailable")
+	}
+
+	xenditConfig := config.BuildXenditConfig()
+	if xenditConfig.IsConfigured() {
+		xendit := external.NewXenditService(xenditConfig.BaseURL, xenditConfig.SecretKey, xenditConfig.CallbackToken, nil)
+		gateways = append(gateways, xendit)
+		log.Printf("xendit base url: %s", xendit.BaseURL())
+	} else if checkoutProvider == config.PaymentProviderXendit {
+		log.Println("XENDIT_SECRET_KEY or XENDIT_CALLBACK_TOKEN not set; customer payment API will be unavailable")
+	}
+
+	return gateways, nil
+}
+
+// newMediaStorage returns where uploaded photos and music are kept. MEDIA_DRIVER=s3 works with
+// any S3-compatible service, including a local MinIO for development.
+func newMediaStorage(cfg config.MediaConfig) (storage.Storage, error) {
+	switch cfg.Driver {
+	case config.MediaDriverLocal:
+		log.Printf("media stored in %s, served from %s", cfg.LocalDir, cfg.PublicURL)
+		return &storage.Local{Dir: cfg.LocalDir, BaseURL: cfg.PublicURL}, nil
+	case config.MediaDriverS3:
+		store, err := storage.NewS3(storage.S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			PublicURL: cfg.PublicURL,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("media stored in s3 bucket %s", cfg.S3Bucket)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_DRIVER %q", cfg.Driver)
+	}
+}
+
+// newReceiptStorage returns where manual transfer receipts are kept. They hold bank and account
+// names, so unlike media they are never served publicly and only reach admins through the payment
+// proof endpoint. It returns nil when the s3 driver has no private bucket.
+func newReceiptStorage(cfg config.MediaConfig) (storage.Storage, error) {
+	switch cfg.Driver {
+	case config.MediaDriverLocal:
+		if insideDir(cfg.LocalDir, cfg.PrivateDir) {
+			return nil, fmt.Errorf("MEDIA_PRIVATE_DIR %s must not be inside the served MEDIA_LOCAL_DIR %s", cfg.PrivateDir, cfg.LocalDir)
+		}
+		log.Printf("receipts stored in %s", cfg.PrivateDir)
+		return &storage.Local{Dir: cfg.PrivateDir}, nil
+	case config.MediaDriverS3:
+		if cfg.S3PrivateBucket == "" {
+			return nil, nil
+		}
+		if cfg.S3PrivateBucket == cfg.S3Bucket {
+			return nil, fmt.Errorf("MEDIA_S3_PRIVATE_BUCKET must differ from the public MEDIA_S3_BUCKET")
+		}
+		store, err := storage.NewS3(storage.S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3PrivateBucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("receipts stored in s3 bucket %s", cfg.S3PrivateBucket)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_DRIVER %q", cfg.Driver)
+	}
+}
+
+// insideDir reports whether path is dir or somewhere below it.
+func insideDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// newWishBroker returns the pub/sub used by the live wishes feed. WISHES_PUBSUB=postgres relays
+// events between instances through LISTEN/NOTIFY; the default only reaches this instance.
+func newWishBroker(ctx context.Context, db *gorm.DB) (realtime.Broker, error) {
+	if !strings.EqualFold(config.GetEnv("WISHES_PUBSUB"), "postgres") {
+		return realtime.NewHub(), nil
+	}
+
+	dsn, err := config.RequireEnv("DATABASE_URL")
+	if err != nil {
+		return nil, err
+	}
+	broker, err := realtime.NewPostgresBroker(db, dsn, "wishes_feed")
+	if err != nil {
+		return nil, err
+	}
+	go broker.Listen(ctx)
+	log.Println("wishes feed relayed through postgres LISTEN/NOTIFY")
+	return broker, nil
 }
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/config"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
+)
+
+func TestNewReceiptStorage(t *testing.T) {
+	dir := t.TempDir()
+	s3Config := config.MediaConfig{
+		Driver:      config.MediaDriverS3,
+		S3Endpoint:  "http://localhost:9000",
+		S3Bucket:    "media",
+		S3AccessKey: "key",
+		S3SecretKey: "secret",
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.MediaConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			name: "local private dir beside media",
+			cfg:  config.MediaConfig{Driver: config.MediaDriverLocal, LocalDir: filepath.Join(dir, "uploads"), PrivateDir: filepath.Join(dir, "private-uploads")},
+		},
+		{
+			name:    "local private dir inside served media",
+			cfg:     config.MediaConfig{Driver: config.MediaDriverLocal, LocalDir: filepath.Join(dir, "uploads"), PrivateDir: filepath.Join(dir, "uploads", "receipts")},
+			wantErr: true,
+		},
+		{
+			name:    "local private dir is the media dir",
+			cfg:     config.MediaConfig{Driver: config.MediaDriverLocal, LocalDir: filepath.Join(dir, "uploads"), PrivateDir: filepath.Join(dir, "uploads") + "/"},
+			wantErr: true,
+		},
+		{
+			name:    "s3 without private bucket",
+			cfg:     s3Config,
+			wantNil: true,
+		},
+		{
+			name: "s3 private bucket",
+			cfg: func() config.MediaConfig {
+				cfg := s3Config
+				cfg.S3PrivateBucket = "receipts"
+				return cfg
+			}(),
+		},
+		{
+			name: "s3 private bucket is the public one",
+			cfg: func() config.MediaConfig {
+				cfg := s3Config
+				cfg.S3PrivateBucket = "media"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := newReceiptStorage(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newReceiptStorage succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newReceiptStorage: %v", err)
+			}
+			if (store == nil) != tt.wantNil {
+				t.Fatalf("newReceiptStorage = %v, want nil %v", store, tt.wantNil)
+			}
+			if local, ok := store.(*storage.Local); ok && local.BaseURL != "" {
+				t.Fatalf("receipt storage has a public URL %q", local.BaseURL)
+			}
+		})
+	}
+}
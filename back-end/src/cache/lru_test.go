@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestLRU returns a cache whose clock only moves when the test advances it.
+func newTestLRU(size int, ttl time.Duration) (*LRU[string, int], *time.Time) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](size, ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestLRUExpiresAfterTTL(t *testing.T) {
+	c, now := newTestLRU(10, time.Minute)
+	c.Set("a", 1)
+
+	*now = now.Add(59 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get before the TTL = %d, %v, want 1, true", v, ok)
+	}
+
+	*now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Get at the TTL found the entry")
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("Stats = %+v, want the expired entry removed after 1 hit and 1 miss", stats)
+	}
+
+	// Setting again restarts the TTL.
+	c.Set("a", 2)
+	*now = now.Add(30 * time.Second)
+	c.Set("a", 3)
+	*now = now.Add(45 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 3 {
+		t.Fatalf("Get after an overwrite = %d, %v, want 3, true", v, ok)
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b was used least recently but survived")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Fatalf("%s was evicted", key)
+		}
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Fatalf("Stats = %+v, want 1 eviction and 2 entries", stats)
+	}
+
+	// Overwriting an entry does not count against the size.
+	c.Set("a", 10)
+	if stats := c.Stats(); stats.Evictions != 1 {
+		t.Fatalf("overwrite evicted an entry: %+v", stats)
+	}
+}
+
+func TestLRUSizeBelowOne(t *testing.T) {
+	c, _ := newTestLRU(0, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("a cache created with size 0 holds no entry")
+	}
+	if stats := c.Stats(); stats.Entries != 1 {
+		t.Fatalf("Entries = %d, want 1", stats.Entries)
+	}
+}
+
+func TestLRUDeleteFunc(t *testing.T) {
+	c, _ := newTestLRU(10, time.Minute)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		c.Set(key, i)
+	}
+
+	c.DeleteFunc(func(key string, value int) bool {
+		return key == "a" || value%2 == 1
+	})
+
+	want := map[string]bool{"a": false, "b": false, "c": true, "d": false}
+	for key, present := range want {
+		if _, ok := c.Get(key); ok != present {
+			t.Errorf("Get(%s) found = %v, want %v", key, ok, present)
+		}
+	}
+
+	c.Delete("c")
+	c.Set("e", 5)
+	c.Purge()
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("Entries after Purge = %d", stats.Entries)
+	}
+}
@@ -0,0 +1,34 @@
+package certs
+
+import (
+	"context"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+// Cache keeps autocert data in Postgres so every instance serves the same certificates and can
+// answer HTTP-01 challenges started by another instance.
+type Cache struct {
+	Repo *repository.AcmeCacheRepository
+}
+
+func (c Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok, err := c.Repo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, nil
+}
+
+func (c Cache) Put(ctx context.Context, key string, data []byte) error {
+	return c.Repo.Put(ctx, key, data)
+}
+
+func (c Cache) Delete(ctx context.Context, key string) error {
+	return c.Repo.Delete(ctx, key)
+}
@@ -0,0 +1,124 @@
+// Package certs issues TLS certificates on demand through ACME for the hosts the app serves.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+// ErrHostNotAllowed is returned by the host policy for hosts no customer has verified.
+var ErrHostNotAllowed = errors.New("certs: host not allowed")
+
+// HostResolver reports whether a host belongs to a customer. CustomerService satisfies it.
+type HostResolver interface {
+	ResolveByHost(ctx context.Context, host string) (model.Customer, bool, error)
+}
+
+// Options configures the certificate manager. DirectoryURL defaults to Let's Encrypt; point it
+// at a local Pebble (https://localhost:14000/dir) together with CABundle, Pebble's own CA
+// certificate, to test issuance without a public CA.
+type Options struct {
+	DirectoryURL string
+	Email        string
+	CABundle     string
+	// Hosts are always allowed, e.g. the API's own hostname.
+	Hosts []string
+	// BaseDomains are the app domains whose subdomains belong to customers. They are never issued
+	// on demand, one certificate per customer would soon hit the CA's rate limits; a Wildcard
+	// certificate covers them instead.
+	BaseDomains []string
+}
+
+// NewManager returns an autocert manager that issues a certificate the first time a client asks
+// for an allowed host, and renews it before it expires.
+func NewManager(opts Options, cache autocert.Cache, resolver HostResolver) (*autocert.Manager, error) {
+	directory := strings.TrimSpace(opts.DirectoryURL)
+	if directory == "" {
+		directory = autocert.DefaultACMEDirectory
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if opts.CABundle != "" {
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("read acme ca bundle: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("acme ca bundle %s has no certificates", opts.CABundle)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+		client.Transport = transport
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: HostPolicy(resolver, opts.BaseDomains, opts.Hosts...),
+		Email:      opts.Email,
+		Client:     &acme.Client{DirectoryURL: directory, HTTPClient: client},
+	}, nil
+}
+
+// HostPolicy allows the fixed hosts and any host the resolver maps to a customer, so
+// certificates are never requested for names pointed at us by strangers. The base domains and
+// their subdomains are refused unless listed in hosts, as they are served by the wildcard.
+func HostPolicy(resolver HostResolver, baseDomains []string, hosts ...string) autocert.HostPolicy {
+	fixed := make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		if host = normalizeHost(host); host != "" {
+			fixed[host] = struct{}{}
+		}
+	}
+
+	return func(ctx context.Context, host string) error {
+		host = normalizeHost(host)
+		if _, ok := fixed[host]; ok {
+			return nil
+		}
+		if underBaseDomain(host, baseDomains) {
+			return ErrHostNotAllowed
+		}
+		if resolver == nil {
+			return ErrHostNotAllowed
+		}
+
+		_, ok, err := resolver.ResolveByHost(ctx, host)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrHostNotAllowed
+		}
+		return nil
+	}
+}
+
+func underBaseDomain(host string, baseDomains []string) bool {
+	for _, base := range baseDomains {
+		if base = normalizeHost(base); base == "" {
+			continue
+		}
+		if host == base || strings.HasSuffix(host, "."+base) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,76 @@
+package certs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+// fakeResolver knows the hosts of its map; the error is returned for every lookup when set.
+type fakeResolver struct {
+	hosts map[string]bool
+	err   error
+	asked []string
+}
+
+func (r *fakeResolver) ResolveByHost(_ context.Context, host string) (model.Customer, bool, error) {
+	r.asked = append(r.asked, host)
+	if r.err != nil {
+		return model.Customer{}, false, r.err
+	}
+	if r.hosts[host] {
+		return model.Customer{ID: "c1"}, true, nil
+	}
+	return model.Customer{}, false, nil
+}
+
+func TestHostPolicy(t *testing.T) {
+	lookupFailed := errors.New("database down")
+
+	tests := []struct {
+		name      string
+		host      string
+		resolver  *fakeResolver
+		wantErr   error
+		wantAsked bool
+	}{
+		{name: "fixed host", host: "api.ourapp.id", resolver: &fakeResolver{}},
+		{name: "fixed host in another case with a trailing dot", host: "API.OurApp.id.", resolver: &fakeResolver{}},
+		{name: "verified custom domain", host: "rina-dan-bayu.com", resolver: &fakeResolver{hosts: map[string]bool{"rina-dan-bayu.com": true}}, wantAsked: true},
+		{name: "unknown host", host: "stranger.example", resolver: &fakeResolver{}, wantErr: ErrHostNotAllowed, wantAsked: true},
+		{name: "lookup error", host: "rina-dan-bayu.com", resolver: &fakeResolver{err: lookupFailed}, wantErr: lookupFailed, wantAsked: true},
+		{name: "no resolver", host: "rina-dan-bayu.com", wantErr: ErrHostNotAllowed},
+		// Customer subdomains are served by the wildcard, so they are refused without a lookup.
+		{name: "customer subdomain", host: "rina.ourapp.id", resolver: &fakeResolver{hosts: map[string]bool{"rina.ourapp.id": true}}, wantErr: ErrHostNotAllowed},
+		{name: "bare base domain", host: "ourapp.id", resolver: &fakeResolver{}, wantErr: ErrHostNotAllowed},
+		{name: "nested subdomain", host: "a.b.ourapp.id", resolver: &fakeResolver{}, wantErr: ErrHostNotAllowed},
+		{name: "lookalike of a base domain", host: "notourapp.id", resolver: &fakeResolver{}, wantErr: ErrHostNotAllowed, wantAsked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolver HostResolver
+			if tt.resolver != nil {
+				resolver = tt.resolver
+			}
+			policy := HostPolicy(resolver, []string{"OurApp.id"}, "api.ourapp.id", " ")
+
+			err := policy(context.Background(), tt.host)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("policy(%q) = %v, want %v", tt.host, err, tt.wantErr)
+			}
+			if tt.resolver != nil && (len(tt.resolver.asked) > 0) != tt.wantAsked {
+				t.Fatalf("resolver asked about %v, want a lookup: %v", tt.resolver.asked, tt.wantAsked)
+			}
+		})
+	}
+}
+
+func TestNewManagerCABundle(t *testing.T) {
+	if _, err := NewManager(Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}, nil, nil); err == nil {
+		t.Fatal("NewManager with a missing CA bundle succeeded")
+	}
+}
@@ -0,0 +1,99 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TestPebbleIssuance issues a real certificate from a local Pebble and is skipped unless
+// ACME_DIRECTORY_URL and ACME_CA_BUNDLE point at one, e.g.
+//
+//	PEBBLE_VA_ALWAYS_VALID=1 pebble -config test/config/pebble-config.json
+//	ACME_DIRECTORY_URL=https://localhost:14000/dir ACME_CA_BUNDLE=pebble.minica.pem go test ./src/certs -run Pebble
+//
+// Without PEBBLE_VA_ALWAYS_VALID, Pebble must resolve ACME_TEST_HOST to this machine, where the
+// HTTP-01 challenge is answered on TLS_HTTP_ADDR (Pebble's default :5002).
+func TestPebbleIssuance(t *testing.T) {
+	directory := os.Getenv("ACME_DIRECTORY_URL")
+	bundle := os.Getenv("ACME_CA_BUNDLE")
+	if directory == "" || bundle == "" {
+		t.Skip("ACME_DIRECTORY_URL and ACME_CA_BUNDLE not set; start Pebble to run this test")
+	}
+	host := envOr("ACME_TEST_HOST", "rina-dan-bayu.test")
+	addr := envOr("TLS_HTTP_ADDR", ":5002")
+
+	opts := Options{DirectoryURL: directory, CABundle: bundle, Email: "ops@ourapp.test", BaseDomains: []string{"ourapp.test"}}
+	resolver := &fakeResolver{hosts: map[string]bool{host: true}}
+	cache := autocert.DirCache(t.TempDir())
+	manager, err := NewManager(opts, cache, resolver)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	manager.Client.HTTPClient.Transport = pebbleFinalize{next: manager.Client.HTTPClient.Transport}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen for HTTP-01 on %s: %v", addr, err)
+	}
+	server := &http.Server{Handler: manager.HTTPHandler(nil)}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+	if err != nil {
+		t.Fatalf("GetCertificate(%s): %v", host, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse issued certificate: %v", err)
+	}
+	if !slices.Contains(leaf.DNSNames, host) {
+		t.Fatalf("issued certificate names %v, want %s", leaf.DNSNames, host)
+	}
+
+	// A second instance sharing the cache serves the same certificate without asking the CA.
+	other, err := NewManager(opts, cache, &fakeResolver{hosts: map[string]bool{host: true}})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	cached, err := other.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+	if err != nil {
+		t.Fatalf("GetCertificate from the shared cache: %v", err)
+	}
+	if cachedLeaf, err := x509.ParseCertificate(cached.Certificate[0]); err != nil || cachedLeaf.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+		t.Fatalf("second instance served a different certificate (err %v)", err)
+	}
+
+	if _, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "stranger.test"}); err == nil {
+		t.Fatal("issued a certificate for a host no customer owns")
+	}
+}
+
+// pebbleFinalize adds the order Location that Pebble, unlike Let's Encrypt, leaves off finalize
+// responses. acme.Client polls that URL while the certificate is still processing.
+type pebbleFinalize struct {
+	next http.RoundTripper
+}
+
+func (p pebbleFinalize) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := p.next.RoundTrip(req)
+	if err == nil && res.Header.Get("Location") == "" && strings.Contains(req.URL.Path, "/finalize-order/") {
+		res.Header.Set("Location", strings.Replace(req.URL.String(), "/finalize-order/", "/my-order/", 1))
+	}
+	return res, err
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,114 @@
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// wildcardRecheck is how often the certificate file is checked for a renewed certificate.
+const wildcardRecheck = time.Minute
+
+// Wildcard serves a *.<base domain> certificate read from disk for the customers' subdomains.
+// Let's Encrypt only issues wildcards through the DNS-01 challenge, which autocert cannot answer,
+// so the certificate is obtained out of band, e.g. certbot with the DNS plugin of the domain's
+// provider, and read again whenever the file changes.
+type Wildcard struct {
+	certFile    string
+	keyFile     string
+	baseDomains []string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// LoadWildcard reads the certificate and key pair so a bad path fails at startup.
+func LoadWildcard(certFile, keyFile string, baseDomains []string) (*Wildcard, error) {
+	w := &Wildcard{certFile: certFile, keyFile: keyFile}
+	for _, base := range baseDomains {
+		if base = normalizeHost(base); base != "" {
+			w.baseDomains = append(w.baseDomains, base)
+		}
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Covers reports whether host is a single label under one of the base domains. The base domain
+// itself and nested names are not covered by a wildcard.
+func (w *Wildcard) Covers(host string) bool {
+	host = normalizeHost(host)
+	for _, base := range w.baseDomains {
+		label, found := strings.CutSuffix(host, "."+base)
+		if found && label != "" && !strings.Contains(label, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Certificate returns the current certificate, keeping the previous one when a renewed file
+// cannot be read.
+func (w *Wildcard) Certificate() *tls.Certificate {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if time.Since(w.checked) >= wildcardRecheck {
+		w.checked = time.Now()
+		if info, err := os.Stat(w.certFile); err == nil && !info.ModTime().Equal(w.modTime) {
+			if err := w.loadLocked(); err != nil {
+				log.Printf("reload wildcard certificate: %v", err)
+			}
+		}
+	}
+	return w.cert
+}
+
+func (w *Wildcard) load() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checked = time.Now()
+	return w.loadLocked()
+}
+
+func (w *Wildcard) loadLocked() error {
+	info, err := os.Stat(w.certFile)
+	if err != nil {
+		return fmt.Errorf("wildcard certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(w.certFile, w.keyFile)
+	if err != nil {
+		return fmt.Errorf("wildcard certificate: %w", err)
+	}
+	w.cert = &cert
+	w.modTime = info.ModTime()
+	return nil
+}
+
+// TLSConfig serves the wildcard for the hosts it covers and asks the manager for the rest.
+// A nil wildcard leaves the manager's config as it is.
+func TLSConfig(manager *autocert.Manager, wildcard *Wildcard) *tls.Config {
+	config := manager.TLSConfig()
+	if wildcard == nil {
+		return config
+	}
+
+	issue := config.GetCertificate
+	config.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if wildcard.Covers(hello.ServerName) {
+			return wildcard.Certificate(), nil
+		}
+		return issue(hello)
+	}
+	return config
+}
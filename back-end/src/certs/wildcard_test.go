@@ -0,0 +1,164 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// writeSelfSigned writes a certificate for names and its key to dir and returns their paths.
+func writeSelfSigned(t *testing.T, dir string, serial int64, names ...string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "wildcard.pem")
+	keyFile := filepath.Join(dir, "wildcard.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func serialOf(t *testing.T, cert *tls.Certificate) int64 {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestWildcardCovers(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSigned(t, dir, 1, "*.ourapp.id")
+	wildcard, err := LoadWildcard(certFile, keyFile, []string{"OurApp.id", ""})
+	if err != nil {
+		t.Fatalf("LoadWildcard: %v", err)
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"rina.ourapp.id", true},
+		{"Rina.OurApp.id.", true},
+		{"ourapp.id", false},
+		{"a.b.ourapp.id", false},
+		{"notourapp.id", false},
+		{"rina-dan-bayu.com", false},
+	}
+	for _, tt := range tests {
+		if got := wildcard.Covers(tt.host); got != tt.want {
+			t.Errorf("Covers(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWildcardMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadWildcard(filepath.Join(dir, "missing.pem"), filepath.Join(dir, "missing.key"), nil); err == nil {
+		t.Fatal("LoadWildcard with missing files succeeded")
+	}
+}
+
+func TestWildcardReloadsRenewedFile(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSigned(t, dir, 1, "*.ourapp.id")
+	wildcard, err := LoadWildcard(certFile, keyFile, []string{"ourapp.id"})
+	if err != nil {
+		t.Fatalf("LoadWildcard: %v", err)
+	}
+
+	writeSelfSigned(t, dir, 2, "*.ourapp.id")
+	renewed := time.Now().Add(time.Minute)
+	if err := os.Chtimes(certFile, renewed, renewed); err != nil {
+		t.Fatal(err)
+	}
+	if got := serialOf(t, wildcard.Certificate()); got != 1 {
+		t.Fatalf("serial before the recheck interval = %d, want 1", got)
+	}
+
+	wildcard.checked = time.Now().Add(-wildcardRecheck)
+	if got := serialOf(t, wildcard.Certificate()); got != 2 {
+		t.Fatalf("serial after renewal = %d, want 2", got)
+	}
+
+	// A broken renewal keeps serving the last good certificate.
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	broken := renewed.Add(time.Minute)
+	if err := os.Chtimes(certFile, broken, broken); err != nil {
+		t.Fatal(err)
+	}
+	wildcard.checked = time.Now().Add(-wildcardRecheck)
+	if got := serialOf(t, wildcard.Certificate()); got != 2 {
+		t.Fatalf("serial after a broken renewal = %d, want 2", got)
+	}
+}
+
+func TestTLSConfigServesWildcard(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSigned(t, dir, 7, "*.ourapp.id")
+	wildcard, err := LoadWildcard(certFile, keyFile, []string{"ourapp.id"})
+	if err != nil {
+		t.Fatalf("LoadWildcard: %v", err)
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: HostPolicy(nil, []string{"ourapp.id"}),
+	}
+	config := TLSConfig(manager, wildcard)
+
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "rina.ourapp.id"})
+	if err != nil {
+		t.Fatalf("GetCertificate(rina.ourapp.id): %v", err)
+	}
+	if got := serialOf(t, cert); got != 7 {
+		t.Fatalf("served serial %d, want the wildcard", got)
+	}
+
+	// Other hosts go to the manager, whose policy refuses them before contacting a CA.
+	_, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "stranger.example"})
+	if !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("GetCertificate(stranger.example) err = %v, want ErrHostNotAllowed", err)
+	}
+	if err := manager.HostPolicy(context.Background(), "rina.ourapp.id"); !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("host policy allowed a wildcard host: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+type ManualTransferConfig struct {
+	BankName      string
+	AccountNumber string
+	AccountName   string
+}
+
+func BuildManualTransferConfig() ManualTransferConfig {
+	bankName := GetEnv("MANUAL_TRANSFER_BANK_NAME")
+	if bankName == "" {
+		bankName = "BCA"
+	}
+
+	return ManualTransferConfig{
+		BankName:      bankName,
+		AccountNumber: GetEnv("MANUAL_TRANSFER_ACCOUNT_NUMBER"),
+		AccountName:   GetEnv("MANUAL_TRANSFER_ACCOUNT_NAME"),
+	}
+}
+
+func (c ManualTransferConfig) IsConfigured() bool {
+	return c.AccountNumber != "" && c.AccountName != ""
+}
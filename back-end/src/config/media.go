@@ -0,0 +1,62 @@
+package config
+
+import "strings"
+
+const (
+	MediaDriverLocal = "local"
+	MediaDriverS3    = "s3"
+)
+
+// MediaConfig locates the public media store and the private one for payment receipts, which is
+// never served: PrivateDir on the local driver, S3PrivateBucket on s3.
+type MediaConfig struct {
+	Driver          string
+	LocalDir        string
+	PrivateDir      string
+	PublicURL       string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3PrivateBucket string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3PathStyle     bool
+}
+
+func BuildMediaConfig() MediaConfig {
+	driver := strings.ToLower(GetEnv("MEDIA_DRIVER"))
+	if driver == "" {
+		driver = MediaDriverLocal
+	}
+
+	localDir := GetEnv("MEDIA_LOCAL_DIR")
+	if localDir == "" {
+		localDir = "./uploads"
+	}
+
+	privateDir := GetEnv("MEDIA_PRIVATE_DIR")
+	if privateDir == "" {
+		privateDir = "./private-uploads"
+	}
+
+	publicURL := GetEnv("MEDIA_PUBLIC_URL")
+	if publicURL == "" && driver == MediaDriverLocal {
+		publicURL = "http://localhost:8080/media"
+	}
+
+	pathStyle := strings.ToLower(GetEnv("MEDIA_S3_PATH_STYLE"))
+
+	return MediaConfig{
+		Driver:          driver,
+		LocalDir:        localDir,
+		PrivateDir:      privateDir,
+		PublicURL:       publicURL,
+		S3Endpoint:      GetEnv("MEDIA_S3_ENDPOINT"),
+		S3Region:        GetEnv("MEDIA_S3_REGION"),
+		S3Bucket:        GetEnv("MEDIA_S3_BUCKET"),
+		S3PrivateBucket: GetEnv("MEDIA_S3_PRIVATE_BUCKET"),
+		S3AccessKey:     GetEnv("MEDIA_S3_ACCESS_KEY"),
+		S3SecretKey:     GetEnv("MEDIA_S3_SECRET_KEY"),
+		S3PathStyle:     pathStyle == "true" || pathStyle == "1",
+	}
+}
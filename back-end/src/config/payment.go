@@ -0,0 +1,17 @@
+package config
+
+import "strings"
+
+const (
+	PaymentProviderMidtrans = "midtrans"
+	PaymentProviderXendit   = "xendit"
+)
+
+// PaymentProvider is the gateway new plan checkouts go through, from PAYMENT_PROVIDER.
+func PaymentProvider() string {
+	provider := strings.ToLower(GetEnv("PAYMENT_PROVIDER"))
+	if provider == "" {
+		return PaymentProviderMidtrans
+	}
+	return provider
+}
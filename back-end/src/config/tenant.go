@@ -0,0 +1,15 @@
+package config
+
+import "strings"
+
+// BaseDomains lists the app's own domains from BASE_DOMAINS; each customer is reachable at
+// <domain>.<base domain>.
+func BaseDomains() []string {
+	domains := make([]string, 0)
+	for _, domain := range strings.Split(GetEnv("BASE_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
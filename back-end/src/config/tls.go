@@ -0,0 +1,63 @@
+package config
+
+import "strings"
+
+const (
+	TLSModeOff  = "off"
+	TLSModeACME = "acme"
+)
+
+// TLSConfig controls serving HTTPS directly instead of behind a proxy that terminates TLS.
+type TLSConfig struct {
+	Mode string
+	// Addr serves HTTPS; HTTPAddr answers ACME HTTP-01 challenges and redirects everything else.
+	Addr          string
+	HTTPAddr      string
+	ACMEDirectory string
+	ACMEEmail     string
+	ACMECABundle  string
+	Hosts         []string
+	// WildcardCert and WildcardKey are PEM files of a *.<base domain> certificate for the
+	// customers' subdomains, which are not issued on demand.
+	WildcardCert string
+	WildcardKey  string
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.Mode == TLSModeACME
+}
+
+func BuildTLSConfig() TLSConfig {
+	mode := strings.ToLower(GetEnv("TLS_MODE"))
+	if mode == "" {
+		mode = TLSModeOff
+	}
+
+	addr := GetEnv("TLS_ADDR")
+	if addr == "" {
+		addr = ":443"
+	}
+	httpAddr := GetEnv("TLS_HTTP_ADDR")
+	if httpAddr == "" {
+		httpAddr = ":80"
+	}
+
+	hosts := make([]string, 0)
+	for _, host := range strings.Split(GetEnv("TLS_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return TLSConfig{
+		Mode:          mode,
+		Addr:          addr,
+		HTTPAddr:      httpAddr,
+		ACMEDirectory: GetEnv("ACME_DIRECTORY_URL"),
+		ACMEEmail:     GetEnv("ACME_EMAIL"),
+		ACMECABundle:  GetEnv("ACME_CA_BUNDLE"),
+		Hosts:         hosts,
+		WildcardCert:  GetEnv("TLS_WILDCARD_CERT"),
+		WildcardKey:   GetEnv("TLS_WILDCARD_KEY"),
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+type XenditConfig struct {
+	BaseURL       string
+	SecretKey     string
+	CallbackToken string
+}
+
+func BuildXenditConfig() XenditConfig {
+	return XenditConfig{
+		BaseURL:       GetEnv("XENDIT_BASE_URL"),
+		SecretKey:     GetEnv("XENDIT_SECRET_KEY"),
+		CallbackToken: GetEnv("XENDIT_CALLBACK_TOKEN"),
+	}
+}
+
+// IsConfigured requires the callback token too, since without it no webhook can be trusted.
+func (c XenditConfig) IsConfigured() bool {
+	return c.SecretKey != "" && c.CallbackToken != ""
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -44,6 +45,15 @@ func Decode(raw []byte) (map[string]any, error) {
 	return out, nil
 }
 
+// Equal reports whether two content documents hold the same JSON, ignoring key order and spacing.
+func Equal(a, b []byte) bool {
+	var left, right any
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(left, right)
+}
+
 // IsPaid reports whether a customer status counts as paid.
 func IsPaid(status string) bool {
 	return strings.ToLower(strings.TrimSpace(status)) == "paid"
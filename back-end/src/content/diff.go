@@ -0,0 +1,111 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is one difference between two versions of invitation content. Path is a JSON path such
+// as "$.story.stories[2].title".
+type Change struct {
+	Path string `json:"path"`
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// Diff lists the leaf-level changes needed to turn from into to. Objects are compared key by key
+// and arrays index by index.
+func Diff(from, to []byte) ([]Change, error) {
+	left, err := decodeAny(from)
+	if err != nil {
+		return nil, err
+	}
+	right, err := decodeAny(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	diffValue("$", left, right, &changes)
+	return changes, nil
+}
+
+func decodeAny(raw []byte) (any, error) {
+	if len(raw) == 0 {
+		return map[string]any{}, nil
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid invitation content: %w", err)
+	}
+	return out, nil
+}
+
+func diffValue(path string, from, to any, changes *[]Change) {
+	switch left := from.(type) {
+	case map[string]any:
+		if right, ok := to.(map[string]any); ok {
+			diffObject(path, left, right, changes)
+			return
+		}
+	case []any:
+		if right, ok := to.([]any); ok {
+			diffArray(path, left, right, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Path: path, Op: ChangeChanged, From: from, To: to})
+	}
+}
+
+func diffObject(path string, from, to map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := path + "." + key
+		left, inFrom := from[key]
+		right, inTo := to[key]
+		switch {
+		case !inTo:
+			*changes = append(*changes, Change{Path: child, Op: ChangeRemoved, From: left})
+		case !inFrom:
+			*changes = append(*changes, Change{Path: child, Op: ChangeAdded, To: right})
+		default:
+			diffValue(child, left, right, changes)
+		}
+	}
+}
+
+func diffArray(path string, from, to []any, changes *[]Change) {
+	for i := 0; i < len(from) || i < len(to); i++ {
+		child := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(to):
+			*changes = append(*changes, Change{Path: child, Op: ChangeRemoved, From: from[i]})
+		case i >= len(from):
+			*changes = append(*changes, Change{Path: child, Op: ChangeAdded, To: to[i]})
+		default:
+			diffValue(child, from[i], to[i], changes)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []Change
+	}{
+		{
+			name: "same content in another key order",
+			from: `{"couple": {"groomName": "Bayu", "brideName": "Rina"}, "gallery": {"photos": ["a", "b"]}}`,
+			to:   `{"gallery": {"photos": ["a", "b"]}, "couple": {"brideName": "Rina", "groomName": "Bayu"}}`,
+			want: []Change{},
+		},
+		{
+			name: "changed field",
+			from: `{"couple": {"groomName": "Bayu", "brideName": "Rina"}}`,
+			to:   `{"couple": {"groomName": "Bayu Aji", "brideName": "Rina"}}`,
+			want: []Change{{Path: "$.couple.groomName", Op: ChangeChanged, From: "Bayu", To: "Bayu Aji"}},
+		},
+		{
+			name: "added and removed sections in path order",
+			from: `{"music": {"enabled": true}, "couple": {}}`,
+			to:   `{"couple": {}, "gift": {"banks": []}}`,
+			want: []Change{
+				{Path: "$.gift", Op: ChangeAdded, To: map[string]any{"banks": []any{}}},
+				{Path: "$.music", Op: ChangeRemoved, From: map[string]any{"enabled": true}},
+			},
+		},
+		{
+			name: "list grown and edited",
+			from: `{"story": {"stories": [{"title": "Kuliah"}]}}`,
+			to:   `{"story": {"stories": [{"title": "Kampus"}, {"title": "Lamaran"}]}}`,
+			want: []Change{
+				{Path: "$.story.stories[0].title", Op: ChangeChanged, From: "Kuliah", To: "Kampus"},
+				{Path: "$.story.stories[1]", Op: ChangeAdded, To: map[string]any{"title": "Lamaran"}},
+			},
+		},
+		{
+			name: "list shrunk",
+			from: `{"gallery": {"photos": ["a", "b", "c"]}}`,
+			to:   `{"gallery": {"photos": ["a"]}}`,
+			want: []Change{
+				{Path: "$.gallery.photos[1]", Op: ChangeRemoved, From: "b"},
+				{Path: "$.gallery.photos[2]", Op: ChangeRemoved, From: "c"},
+			},
+		},
+		{
+			name: "value of another type",
+			from: `{"event": {"akadDate": "2026-12-12"}}`,
+			to:   `{"event": null}`,
+			want: []Change{{Path: "$.event", Op: ChangeChanged, From: map[string]any{"akadDate": "2026-12-12"}, To: nil}},
+		},
+		{
+			name: "from an empty draft",
+			from: ``,
+			to:   `{"theme": {"theme": "rustic"}}`,
+			want: []Change{{Path: "$.theme", Op: ChangeAdded, To: map[string]any{"theme": "rustic"}}},
+		},
+		{
+			name: "whole document replaced",
+			from: `{"couple": {}}`,
+			to:   `[]`,
+			want: []Change{{Path: "$", Op: ChangeChanged, From: map[string]any{"couple": map[string]any{}}, To: []any{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff([]byte(tt.from), []byte(tt.to))
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffInvalidContent(t *testing.T) {
+	if _, err := Diff([]byte(`{"couple": `), []byte(`{}`)); err == nil {
+		t.Fatal("Diff accepted invalid content")
+	}
+	if _, err := Diff([]byte(`{}`), []byte(`nope`)); err == nil {
+		t.Fatal("Diff accepted invalid content")
+	}
+}
+
+func TestChangeJSON(t *testing.T) {
+	changes, err := Diff([]byte(`{"couple": {"groomName": "Bayu"}}`), []byte(`{"couple": {"brideName": "Rina"}}`))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `[{"path":"$.couple.brideName","op":"added","to":"Rina"},{"path":"$.couple.groomName","op":"removed","from":"Bayu"}]`
+	if string(raw) != want {
+		t.Fatalf("json = %s, want %s", raw, want)
+	}
+}
@@ -0,0 +1,280 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Content is the invitation content rendered by the front-end. Every section is optional so
+// partially filled drafts can be saved.
+type Content struct {
+	Couple   *Couple   `json:"couple"`
+	Event    *Event    `json:"event"`
+	Location *Location `json:"location"`
+	Gallery  *Gallery  `json:"gallery"`
+	Story    *Story    `json:"story"`
+	Gift     *Gift     `json:"gift"`
+	Theme    *Theme    `json:"theme"`
+	Music    *Music    `json:"music"`
+	// Broadcast holds the editor's WhatsApp message template and guest list; it is not rendered.
+	Broadcast *Broadcast `json:"broadcast"`
+}
+
+type Couple struct {
+	GroomName     string `json:"groomName" validate:"max=100"`
+	GroomFullName string `json:"groomFullName" validate:"max=150"`
+	GroomFather   string `json:"groomFather" validate:"max=150"`
+	GroomMother   string `json:"groomMother" validate:"max=150"`
+	GroomPhoto    string `json:"groomPhoto" validate:"omitempty,imageurl"`
+	BrideName     string `json:"brideName" validate:"max=100"`
+	BrideFullName string `json:"brideFullName" validate:"max=150"`
+	BrideFather   string `json:"brideFather" validate:"max=150"`
+	BrideMother   string `json:"brideMother" validate:"max=150"`
+	BridePhoto    string `json:"bridePhoto" validate:"omitempty,imageurl"`
+}
+
+type Event struct {
+	AkadDate       string `json:"akadDate" validate:"omitempty,isodate"`
+	AkadTime       string `json:"akadTime" validate:"omitempty,clock"`
+	AkadEndTime    string `json:"akadEndTime" validate:"omitempty,clock"`
+	ResepsiDate    string `json:"resepsiDate" validate:"omitempty,isodate"`
+	ResepsiTime    string `json:"resepsiTime" validate:"omitempty,clock"`
+	ResepsiEndTime string `json:"resepsiEndTime" validate:"omitempty,clock"`
+}
+
+type Location struct {
+	AkadVenue      string `json:"akadVenue" validate:"max=200"`
+	AkadAddress    string `json:"akadAddress" validate:"max=500"`
+	AkadMapsURL    string `json:"akadMapsUrl" validate:"omitempty,max=2048,weburl"`
+	ResepsiVenue   string `json:"resepsiVenue" validate:"max=200"`
+	ResepsiAddress string `json:"resepsiAddress" validate:"max=500"`
+	ResepsiMapsURL string `json:"resepsiMapsUrl" validate:"omitempty,max=2048,weburl"`
+}
+
+type Gallery struct {
+	Photos []string `json:"photos" validate:"dive,required,imageurl"`
+}
+
+type Story struct {
+	Stories []StoryEntry `json:"stories" validate:"dive"`
+}
+
+// StoryEntry.Date is free text such as "Januari 2020", so only its length is checked.
+type StoryEntry struct {
+	Title       string `json:"title" validate:"max=150"`
+	Date        string `json:"date" validate:"max=50"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+type Gift struct {
+	Banks []BankAccount `json:"banks" validate:"dive"`
+}
+
+type BankAccount struct {
+	BankName      string `json:"bankName" validate:"max=100"`
+	AccountNumber string `json:"accountNumber" validate:"max=50"`
+	AccountName   string `json:"accountName" validate:"max=150"`
+}
+
+type Theme struct {
+	Theme        string `json:"theme" validate:"omitempty,oneof=elegant rustic modern gold tropical floral"`
+	PrimaryColor string `json:"primaryColor" validate:"omitempty,oneof=brown green black gold teal purple navy maroon pink sage rose"`
+}
+
+type Music struct {
+	Enabled        bool   `json:"enabled"`
+	SelectedMusic  string `json:"selectedMusic" validate:"max=100"`
+	CustomMusicURL string `json:"customMusicUrl" validate:"omitempty,max=2048,weburl"`
+}
+
+type Broadcast struct {
+	Template string           `json:"template" validate:"max=5000"`
+	Guests   []BroadcastGuest `json:"guests" validate:"dive"`
+}
+
+type BroadcastGuest struct {
+	Name  string `json:"name" validate:"max=150"`
+	Phone string `json:"phone" validate:"max=30"`
+}
+
+// Asset is a file URL embedded in the content, with the path of the field that holds it.
+type Asset struct {
+	Field string
+	URL   string
+}
+
+// Assets lists the photo and music URLs the content points at.
+func (c Content) Assets() []Asset {
+	assets := make([]Asset, 0)
+	add := func(field, value string) {
+		if value != "" {
+			assets = append(assets, Asset{Field: field, URL: value})
+		}
+	}
+
+	if c.Couple != nil {
+		add("couple.groomPhoto", c.Couple.GroomPhoto)
+		add("couple.bridePhoto", c.Couple.BridePhoto)
+	}
+	if c.Gallery != nil {
+		for i, photo := range c.Gallery.Photos {
+			add(fmt.Sprintf("gallery.photos[%d]", i), photo)
+		}
+	}
+	if c.Music != nil {
+		add("music.customMusicUrl", c.Music.CustomMusicURL)
+	}
+	return assets
+}
+
+// DecodeAssets lists the file URLs of raw content without validating it, so content saved
+// before a schema change still yields its assets.
+func DecodeAssets(raw []byte) []Asset {
+	var c Content
+	_ = json.Unmarshal(raw, &c)
+	return c.Assets()
+}
+
+// FieldError describes one invalid field. Field is a dotted path inside the content, e.g.
+// "story.stories[0].title".
+type FieldError struct {
+	Field string
+	Rule  string
+}
+
+// ValidationErrors is returned by Validate when the content does not match the schema.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for _, item := range e {
+		if item.Field == "" {
+			fields = append(fields, item.Rule)
+			continue
+		}
+		fields = append(fields, item.Field)
+	}
+	return "invalid invitation content: " + strings.Join(fields, ", ")
+}
+
+var (
+	sections = sectionNames()
+	schema   = newSchemaValidator()
+)
+
+// Validate decodes raw content into the typed model and checks it field by field. Unknown
+// top-level sections are rejected; unknown keys inside a section are ignored so older drafts
+// keep loading.
+func Validate(raw []byte) (Content, error) {
+	var out Content
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return out, nil
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil {
+		return out, ValidationErrors{{Field: "", Rule: "json"}}
+	}
+
+	var errs ValidationErrors
+	for _, key := range sortedKeys(top) {
+		if _, ok := sections[key]; !ok {
+			errs = append(errs, FieldError{Field: key, Rule: "unknown"})
+		}
+	}
+	if len(errs) > 0 {
+		return out, errs
+	}
+
+	if err := json.Unmarshal(raw, &out); err != nil {
+		field := ""
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			field = typeErr.Field
+		}
+		return out, ValidationErrors{{Field: field, Rule: "type"}}
+	}
+
+	if err := schema.Struct(out); err != nil {
+		verrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return out, err
+		}
+		for _, verr := range verrs {
+			errs = append(errs, FieldError{
+				Field: strings.TrimPrefix(verr.Namespace(), "Content."),
+				Rule:  verr.Tag(),
+			})
+		}
+		return out, errs
+	}
+
+	return out, nil
+}
+
+func newSchemaValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	mustRegister(v, "isodate", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("2006-01-02", fl.Field().String())
+		return err == nil
+	})
+	mustRegister(v, "clock", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("15:04", fl.Field().String())
+		return err == nil
+	})
+	mustRegister(v, "weburl", func(fl validator.FieldLevel) bool {
+		return isWebURL(fl.Field().String())
+	})
+	// Couple photos are uploaded as data URLs by the editor; gallery photos are links.
+	mustRegister(v, "imageurl", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return isWebURL(value) || strings.HasPrefix(value, "data:image/")
+	})
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("content: register %s: %v", tag, err))
+	}
+}
+
+func isWebURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+func sectionNames() map[string]struct{} {
+	out := map[string]struct{}{}
+	t := reflect.TypeOf(Content{})
+	for i := 0; i < t.NumField(); i++ {
+		out[strings.Split(t.Field(i).Tag.Get("json"), ",")[0]] = struct{}{}
+	}
+	return out
+}
+
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const validContent = `{
+	"couple": {"groomName": "Bayu", "brideName": "Rina", "bridePhoto": "data:image/jpeg;base64,/9j/4AAQ", "groomPhoto": "https://cdn.example.com/groom.jpg"},
+	"event": {"akadDate": "2026-12-12", "akadTime": "08:00", "akadEndTime": "10:00", "resepsiDate": "2026-12-12", "resepsiTime": "11:00"},
+	"location": {"akadVenue": "Masjid Al-Ikhlas", "akadMapsUrl": "https://maps.app.goo.gl/abc"},
+	"gallery": {"photos": ["https://cdn.example.com/1.jpg"]},
+	"story": {"stories": [{"title": "Pertama bertemu", "date": "Januari 2020", "description": "Di kampus"}]},
+	"gift": {"banks": [{"bankName": "BCA", "accountNumber": "1234567890", "accountName": "Rina"}]},
+	"theme": {"theme": "elegant", "primaryColor": "sage"},
+	"music": {"enabled": true, "selectedMusic": "canon-in-d"},
+	"broadcast": {"template": "Kepada {nama}", "guests": [{"name": "Pak Hendra", "phone": "0812"}]}
+}`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want ValidationErrors
+	}{
+		{name: "every section", raw: validContent},
+		{name: "empty document", raw: "  "},
+		{name: "empty object", raw: "{}"},
+		// Drafts are saved section by section, so no section is required.
+		{name: "missing sections", raw: `{"couple": {"groomName": "Bayu"}}`},
+		{name: "null section", raw: `{"gift": null}`},
+		{name: "unknown key inside a section", raw: `{"couple": {"groomName": "Bayu", "nickname": "Bay"}}`},
+		{name: "malformed json", raw: `{"couple": `, want: ValidationErrors{{Field: "", Rule: "json"}}},
+		{name: "not an object", raw: `["couple"]`, want: ValidationErrors{{Field: "", Rule: "json"}}},
+		{
+			name: "unknown sections",
+			raw:  `{"couple": {}, "rsvp": {}, "countdown": true}`,
+			want: ValidationErrors{{Field: "countdown", Rule: "unknown"}, {Field: "rsvp", Rule: "unknown"}},
+		},
+		{name: "section of the wrong type", raw: `{"couple": "Bayu & Rina"}`, want: ValidationErrors{{Field: "couple", Rule: "type"}}},
+		{name: "field of the wrong type", raw: `{"music": {"enabled": "yes"}}`, want: ValidationErrors{{Field: "music.enabled", Rule: "type"}}},
+		{name: "list of the wrong type", raw: `{"gallery": {"photos": "https://cdn.example.com/1.jpg"}}`, want: ValidationErrors{{Field: "gallery.photos", Rule: "type"}}},
+		{name: "bad date", raw: `{"event": {"akadDate": "12/12/2026"}}`, want: ValidationErrors{{Field: "event.akadDate", Rule: "isodate"}}},
+		{name: "bad time", raw: `{"event": {"resepsiTime": "25:00"}}`, want: ValidationErrors{{Field: "event.resepsiTime", Rule: "clock"}}},
+		{name: "script url", raw: `{"location": {"akadMapsUrl": "javascript:alert(1)"}}`, want: ValidationErrors{{Field: "location.akadMapsUrl", Rule: "weburl"}}},
+		{name: "url without host", raw: `{"music": {"customMusicUrl": "https:///song.mp3"}}`, want: ValidationErrors{{Field: "music.customMusicUrl", Rule: "weburl"}}},
+		{name: "empty gallery photo", raw: `{"gallery": {"photos": ["https://cdn.example.com/1.jpg", ""]}}`, want: ValidationErrors{{Field: "gallery.photos[1]", Rule: "required"}}},
+		{name: "gallery photo that is not a link", raw: `{"gallery": {"photos": ["foto.jpg"]}}`, want: ValidationErrors{{Field: "gallery.photos[0]", Rule: "imageurl"}}},
+		{name: "too long", raw: `{"couple": {"groomName": "` + strings.Repeat("a", 101) + `"}}`, want: ValidationErrors{{Field: "couple.groomName", Rule: "max"}}},
+		{name: "unknown theme", raw: `{"theme": {"theme": "neon"}}`, want: ValidationErrors{{Field: "theme.theme", Rule: "oneof"}}},
+		{
+			name: "several fields",
+			raw:  `{"story": {"stories": [{"title": "ok"}, {"title": "` + strings.Repeat("a", 151) + `"}]}, "event": {"akadTime": "8am"}}`,
+			want: ValidationErrors{{Field: "event.akadTime", Rule: "clock"}, {Field: "story.stories[1].title", Rule: "max"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Validate([]byte(tt.raw))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var got ValidationErrors
+			if !errors.As(err, &got) {
+				t.Fatalf("Validate err = %v (%T), want ValidationErrors", err, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDecodes(t *testing.T) {
+	payload, err := Validate([]byte(validContent))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if payload.Couple.BrideName != "Rina" || len(payload.Story.Stories) != 1 || !payload.Music.Enabled {
+		t.Fatalf("Validate = %+v", payload)
+	}
+
+	want := []Asset{
+		{Field: "couple.groomPhoto", URL: "https://cdn.example.com/groom.jpg"},
+		{Field: "couple.bridePhoto", URL: "data:image/jpeg;base64,/9j/4AAQ"},
+		{Field: "gallery.photos[0]", URL: "https://cdn.example.com/1.jpg"},
+	}
+	if got := payload.Assets(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Assets = %+v, want %+v", got, want)
+	}
+}
+
+// Handlers find schema errors with errors.As, also when a service wrapped them.
+func TestValidationErrorsWrapped(t *testing.T) {
+	_, err := Validate([]byte(`{"rsvp": {}, "event": {"akadDate": "soon"}}`))
+	wrapped := fmt.Errorf("update invitation: %w", err)
+
+	var errs ValidationErrors
+	if !errors.As(wrapped, &errs) {
+		t.Fatalf("errors.As(%v) found no ValidationErrors", wrapped)
+	}
+	if want := "invalid invitation content: rsvp"; errs.Error() != want {
+		t.Fatalf("Error() = %q, want %q", errs.Error(), want)
+	}
+
+	if got := (ValidationErrors{{Rule: "json"}}).Error(); got != "invalid invitation content: json" {
+		t.Fatalf("Error() = %q", got)
+	}
+}
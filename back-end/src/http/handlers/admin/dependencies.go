@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/auth"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	adminService "github.com/proxima-labs/wedding-invitation-back-end/src/service/admin"
 )
 
@@ -14,16 +15,20 @@ var (
 	invitationService *adminService.InvitationService
 	customerService   *adminService.CustomerService
 	paymentService    *adminService.PaymentService
+	rsvpService       *adminService.RsvpService
+	tenantCache       *repository.TenantCache
 	jwtConfig         auth.Config
 )
 
 type Services struct {
-	Auth       *adminService.AuthService
-	User       *adminService.UserService
-	Invitation *adminService.InvitationService
-	Customer   *adminService.CustomerService
-	Payment    *adminService.PaymentService
-	JwtConfig  auth.Config
+	Auth        *adminService.AuthService
+	User        *adminService.UserService
+	Invitation  *adminService.InvitationService
+	Customer    *adminService.CustomerService
+	Payment     *adminService.PaymentService
+	Rsvp        *adminService.RsvpService
+	TenantCache *repository.TenantCache
+	JwtConfig   auth.Config
 }
 
 func ConfigureServices(s Services) {
@@ -32,6 +37,8 @@ func ConfigureServices(s Services) {
 	invitationService = s.Invitation
 	customerService = s.Customer
 	paymentService = s.Payment
+	rsvpService = s.Rsvp
+	tenantCache = s.TenantCache
 	jwtConfig = s.JwtConfig
 }
 
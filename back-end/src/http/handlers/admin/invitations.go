@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	adminMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/admin"
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	adminRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/admin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	adminService "github.com/proxima-labs/wedding-invitation-back-end/src/service/admin"
 )
@@ -22,6 +24,9 @@ type invitationResponse struct {
 	EventDate   *time.Time      `json:"eventDate"`
 	ThemeKey    string          `json:"themeKey"`
 	IsPublished bool            `json:"isPublished"`
+	PublishedAt *time.Time      `json:"publishedAt"`
+	PublishAt   *time.Time      `json:"publishAt"`
+	ArchiveAt   *time.Time      `json:"archiveAt"`
 	Content     json.RawMessage `json:"content"`
 	CreatedAt   time.Time       `json:"createdAt"`
 	UpdatedAt   time.Time       `json:"updatedAt"`
@@ -38,6 +43,8 @@ type invitationListItem struct {
 	EventDate      *time.Time `json:"eventDate"`
 	ThemeKey       string     `json:"themeKey"`
 	IsPublished    bool       `json:"isPublished"`
+	PublishAt      *time.Time `json:"publishAt"`
+	ArchiveAt      *time.Time `json:"archiveAt"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	UpdatedAt      time.Time  `json:"updatedAt"`
 }
@@ -80,20 +87,7 @@ func ListInvitationsHandler(c *gin.Context) {
 
 	responseItems := make([]invitationListItem, 0, len(items))
 	for _, item := range items {
-		responseItems = append(responseItems, invitationListItem{
-			ID:             item.Invitation.ID,
-			CustomerID:     item.Invitation.CustomerID,
-			CustomerName:   item.CustomerName,
-			CustomerDomain: item.CustomerDomain,
-			Slug:           item.Invitation.Slug,
-			Title:          item.Invitation.Title,
-			SearchName:     item.Invitation.SearchName,
-			EventDate:      item.Invitation.EventDate,
-			ThemeKey:       item.Invitation.ThemeKey,
-			IsPublished:    item.Invitation.IsPublished,
-			CreatedAt:      item.Invitation.CreatedAt,
-			UpdatedAt:      item.Invitation.UpdatedAt,
-		})
+		responseItems = append(responseItems, newInvitationListItem(item))
 	}
 
 	c.JSON(http.StatusOK, invitationListResponse{
@@ -103,6 +97,63 @@ func ListInvitationsHandler(c *gin.Context) {
 	})
 }
 
+// ListScheduledInvitationsHandler shows invitations with a pending scheduled publish or archive.
+func ListScheduledInvitationsHandler(c *gin.Context) {
+	if !ensureService(c, invitationService) {
+		return
+	}
+
+	req, err := adminRequest.NewListScheduledInvitationsRequest(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, adminRequest.ErrInvalidLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		case errors.Is(err, adminRequest.ErrInvalidOffset):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		}
+		return
+	}
+
+	items, total, err := invitationService.ListScheduled(c.Request.Context(), req.Limit, req.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list scheduled invitations"})
+		return
+	}
+
+	responseItems := make([]invitationListItem, 0, len(items))
+	for _, item := range items {
+		responseItems = append(responseItems, newInvitationListItem(item))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":  responseItems,
+		"total":  total,
+		"limit":  req.Limit,
+		"offset": req.Offset,
+	})
+}
+
+func newInvitationListItem(item repository.InvitationWithCustomer) invitationListItem {
+	return invitationListItem{
+		ID:             item.Invitation.ID,
+		CustomerID:     item.Invitation.CustomerID,
+		CustomerName:   item.CustomerName,
+		CustomerDomain: item.CustomerDomain,
+		Slug:           item.Invitation.Slug,
+		Title:          item.Invitation.Title,
+		SearchName:     item.Invitation.SearchName,
+		EventDate:      item.Invitation.EventDate,
+		ThemeKey:       item.Invitation.ThemeKey,
+		IsPublished:    item.Invitation.IsPublished,
+		PublishAt:      item.Invitation.PublishAt,
+		ArchiveAt:      item.Invitation.ArchiveAt,
+		CreatedAt:      item.Invitation.CreatedAt,
+		UpdatedAt:      item.Invitation.UpdatedAt,
+	}
+}
+
 func CreateInvitationHandler(c *gin.Context) {
 	if !ensureService(c, invitationService) {
 		return
@@ -118,6 +169,7 @@ func CreateInvitationHandler(c *gin.Context) {
 		return
 	}
 
+	req.Input.Author = revisionAuthor(c)
 	id, err := invitationService.Create(c.Request.Context(), req.Input)
 	if err != nil {
 		switch {
@@ -170,6 +222,9 @@ func GetInvitationHandler(c *gin.Context) {
 		EventDate:   inv.EventDate,
 		ThemeKey:    inv.ThemeKey,
 		IsPublished: inv.IsPublished,
+		PublishedAt: inv.PublishedAt,
+		PublishAt:   inv.PublishAt,
+		ArchiveAt:   inv.ArchiveAt,
 		Content:     json.RawMessage(inv.Content),
 		CreatedAt:   inv.CreatedAt,
 		UpdatedAt:   inv.UpdatedAt,
@@ -210,6 +265,7 @@ func UpdateInvitationHandler(c *gin.Context) {
 		ThemeKey:    req.Input.ThemeKey,
 		IsPublished: req.Input.IsPublished,
 		Content:     req.Input.Content,
+		Author:      revisionAuthor(c),
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invitation"})
@@ -241,3 +297,12 @@ func DeleteInvitationHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// revisionAuthor attributes content revisions written from the dashboard to the signed-in admin.
+func revisionAuthor(c *gin.Context) repository.RevisionAuthor {
+	author := repository.RevisionAuthor{Type: model.RevisionAuthorAdmin}
+	if claims, ok := adminMiddleware.Get(c); ok {
+		author.ID = claims.UserID
+	}
+	return author
+}
@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantCacheMetricsHandler reports hits and misses of this instance's tenant cache. All counters
+// stay zero when the cache is disabled.
+func TenantCacheMetricsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": tenantCache != nil,
+		"stats":   tenantCache.Stats(),
+	})
+}
@@ -5,7 +5,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	adminMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/admin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	adminRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/admin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	adminService "github.com/proxima-labs/wedding-invitation-back-end/src/service/admin"
 )
 
 func ListPaymentsHandler(c *gin.Context) {
@@ -36,3 +40,165 @@ func ListPaymentsHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func ApprovePaymentHandler(c *gin.Context) {
+	reviewPayment(c, true)
+}
+
+func RejectPaymentHandler(c *gin.Context) {
+	reviewPayment(c, false)
+}
+
+func reviewPayment(c *gin.Context, approve bool) {
+	if !ensureService(c, paymentService) {
+		return
+	}
+
+	claims, ok := adminMiddleware.Get(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, payload, err := adminRequest.NewReviewPaymentRequest(c)
+	if err != nil {
+		if errors.Is(err, adminRequest.ErrMissingID) || errors.Is(err, adminRequest.ErrInvalidID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	var payment model.Payment
+	if approve {
+		payment, err = paymentService.Approve(c.Request.Context(), req.ID, claims.UserID, req.Note)
+	} else {
+		payment, err = paymentService.Reject(c.Request.Context(), req.ID, claims.UserID, req.Note)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, adminService.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, adminService.ErrPaymentNotAwaitingReview):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, adminService.ErrReviewNoteRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to review payment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          payment.ID,
+		"customer_id": payment.CustomerID,
+		"status":      payment.Status,
+		"provider":    payment.Provider,
+		"review_note": payment.ReviewNote,
+		"reviewed_at": payment.ReviewedAt,
+		"paid_at":     payment.PaidAt,
+	})
+}
+
+// RefundPaymentHandler refunds a paid payment through its gateway and revokes the plan it bought.
+func RefundPaymentHandler(c *gin.Context) {
+	if !ensureService(c, paymentService) {
+		return
+	}
+
+	claims, ok := adminMiddleware.Get(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, payload, err := adminRequest.NewRefundPaymentRequest(c)
+	if err != nil {
+		if errors.Is(err, adminRequest.ErrMissingID) || errors.Is(err, adminRequest.ErrInvalidID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	payment, err := paymentService.Refund(c.Request.Context(), req.ID, claims.UserID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, adminService.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, adminService.ErrPaymentNotRefundable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, adminService.ErrRefundFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		case errors.Is(err, adminService.ErrPaymentGatewayNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refund payment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          payment.ID,
+		"customer_id": payment.CustomerID,
+		"status":      payment.Status,
+		"provider":    payment.Provider,
+		"raw_status":  payment.RawStatus,
+		"paid_at":     payment.PaidAt,
+	})
+}
+
+// PaymentProofHandler serves the receipt of a manual transfer to the reviewing admin.
+func PaymentProofHandler(c *gin.Context) {
+	if !ensureService(c, paymentService) {
+		return
+	}
+
+	req, err := adminRequest.NewPaymentIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, contentType, err := paymentService.Proof(c.Request.Context(), req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, adminService.ErrPaymentNotFound), errors.Is(err, adminService.ErrProofNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load receipt"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// PaymentEventsHandler lists the status notifications of a payment for auditing.
+func PaymentEventsHandler(c *gin.Context) {
+	if !ensureService(c, paymentService) {
+		return
+	}
+
+	req, err := adminRequest.NewPaymentIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := paymentService.Events(c.Request.Context(), req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, adminService.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list payment events"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": events})
+}
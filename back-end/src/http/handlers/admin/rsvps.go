@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	handlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers"
+	adminRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/admin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	adminService "github.com/proxima-labs/wedding-invitation-back-end/src/service/admin"
+)
+
+func ListRsvpsHandler(c *gin.Context) {
+	if !ensureService(c, rsvpService) {
+		return
+	}
+
+	req, err := adminRequest.NewListRsvpsRequest(c)
+	if err != nil {
+		writeListRsvpsRequestError(c, err)
+		return
+	}
+
+	result, err := rsvpService.List(c.Request.Context(), req.Filters)
+	if err != nil {
+		writeRsvpError(c, err, "failed to list rsvps")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func RsvpSummaryHandler(c *gin.Context) {
+	if !ensureService(c, rsvpService) {
+		return
+	}
+
+	req, err := adminRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	result, err := rsvpService.Summary(c.Request.Context(), req.ID)
+	if err != nil {
+		writeRsvpError(c, err, "failed to load rsvp summary")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ExportRsvpsHandler(c *gin.Context) {
+	if !ensureService(c, rsvpService) {
+		return
+	}
+
+	req, err := adminRequest.NewListRsvpsRequest(c)
+	if err != nil {
+		writeListRsvpsRequestError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("rsvps-%s-%s", req.Filters.InvitationID, time.Now().Format("20060102"))
+	started, err := handlers.StreamSpreadsheet(c, req.Format, filename, handlers.RsvpExportHeader, func(write func([]string) error) error {
+		return rsvpService.Export(c.Request.Context(), req.Filters, func(item model.RSVP) error {
+			return write(handlers.RsvpExportRow(item))
+		})
+	})
+	if err != nil && !started {
+		writeRsvpError(c, err, "failed to export rsvps")
+	}
+}
+
+func writeRsvpError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, adminService.ErrInvitationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+func writeListRsvpsRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, adminRequest.ErrMissingID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+	case errors.Is(err, adminRequest.ErrInvalidAttendance):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attendance"})
+	case errors.Is(err, adminRequest.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+	case errors.Is(err, adminRequest.ErrInvalidOffset):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+	case errors.Is(err, adminRequest.ErrInvalidExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	}
+}
@@ -71,27 +71,25 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	customerID, invitationID, slug, domain, email, err := authService.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := authService.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		switch err {
 		case customerService.ErrInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		case customerService.ErrInvitationNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
 		}
 		return
 	}
 
-	token, err := auth.NewAccessToken(jwtConfig, customerID, email)
+	token, err := auth.NewAccessToken(jwtConfig, result.CustomerID, result.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
 		return
 	}
 
 	refreshToken, err := authService.IssueRefreshToken(c.Request.Context(), customerService.IssueRefreshTokenInput{
-		CustomerID: customerID,
+		CustomerID: result.CustomerID,
 		UserAgent:  c.Request.UserAgent(),
 		IP:         c.ClientIP(),
 	})
@@ -100,13 +98,27 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
+	invitations := make([]gin.H, 0, len(result.Invitations))
+	for _, inv := range result.Invitations {
+		invitations = append(invitations, invitationSummaryResponse(inv))
+	}
+
+	// invitation_id and slug point at the customer's first invitation for clients that open a
+	// single invitation.
+	invitationID, invitationSlug := "", ""
+	if n := len(result.Invitations); n > 0 {
+		first := result.Invitations[n-1]
+		invitationID, invitationSlug = first.ID, first.Slug
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"token":         token,
 		"refresh_token": refreshToken,
-		"customer_id":   customerID,
+		"customer_id":   result.CustomerID,
 		"invitation_id": invitationID,
-		"slug":          slug,
-		"domain":        domain,
+		"slug":          invitationSlug,
+		"domain":        result.Domain,
+		"invitations":   invitations,
 	})
 }
 
@@ -0,0 +1,155 @@
+package customer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func ListCustomDomainsHandler(c *gin.Context) {
+	if domainService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	items, err := domainService.List(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list domains"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		response = append(response, customDomainResponse(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": response})
+}
+
+func RegisterCustomDomainHandler(c *gin.Context) {
+	if domainService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, payload, err := customerRequest.NewCustomDomainRequest(c)
+	if err != nil {
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	domain, err := domainService.Register(c.Request.Context(), customerID, req.Hostname)
+	if err != nil {
+		writeCustomDomainError(c, err, "failed to register domain")
+		return
+	}
+
+	c.JSON(http.StatusCreated, customDomainResponse(domain))
+}
+
+func VerifyCustomDomainHandler(c *gin.Context) {
+	if domainService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewCustomDomainIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing domain id"})
+		return
+	}
+
+	domain, err := domainService.Verify(c.Request.Context(), customerID, req.ID)
+	if err != nil {
+		writeCustomDomainError(c, err, "failed to verify domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, customDomainResponse(domain))
+}
+
+func DeleteCustomDomainHandler(c *gin.Context) {
+	if domainService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewCustomDomainIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing domain id"})
+		return
+	}
+
+	if err := domainService.Delete(c.Request.Context(), customerID, req.ID); err != nil {
+		writeCustomDomainError(c, err, "failed to delete domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func writeCustomDomainError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrCustomDomainNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "domain not found"})
+	case errors.Is(err, customerService.ErrInvalidHostname):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hostname must be a domain name such as www.example.com"})
+	case errors.Is(err, customerService.ErrCustomDomainNotAllowed),
+		errors.Is(err, customerService.ErrCustomDomainLimit):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
+	case errors.Is(err, customerService.ErrCustomDomainExists),
+		errors.Is(err, customerService.ErrCustomDomainTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, customerService.ErrDomainVerificationFailed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": "verification_failed"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func customDomainResponse(item model.CustomDomain) gin.H {
+	recordName, recordValue := customerService.VerificationRecord(item)
+	return gin.H{
+		"id":       item.ID,
+		"hostname": item.Hostname,
+		"status":   item.Status,
+		"verification": gin.H{
+			"type":  "TXT",
+			"name":  recordName,
+			"value": recordValue,
+		},
+		"verified_at":     item.VerifiedAt,
+		"last_checked_at": item.LastCheckedAt,
+		"last_error":      item.LastError,
+		"created_at":      item.CreatedAt,
+	}
+}
@@ -15,6 +15,11 @@ var (
 	paymentService    *customerService.PaymentService
 	planService       *customerService.PlanService
 	planEnforcer      *customerService.PlanEnforcer
+	guestService      *customerService.GuestService
+	rsvpService       *customerService.RsvpService
+	wishService       *customerService.WishService
+	mediaService      *customerService.MediaService
+	domainService     *customerService.CustomDomainService
 	jwtConfig         auth.Config
 )
 
@@ -26,6 +31,11 @@ type Services struct {
 	Payment    *customerService.PaymentService
 	Plan       *customerService.PlanService
 	Enforcer   *customerService.PlanEnforcer
+	Guest      *customerService.GuestService
+	Rsvp       *customerService.RsvpService
+	Wish       *customerService.WishService
+	Media      *customerService.MediaService
+	Domain     *customerService.CustomDomainService
 	JwtConfig  auth.Config
 }
 
@@ -35,6 +45,11 @@ func ConfigureServices(s Services) {
 	paymentService = s.Payment
 	planService = s.Plan
 	planEnforcer = s.Enforcer
+	guestService = s.Guest
+	rsvpService = s.Rsvp
+	wishService = s.Wish
+	mediaService = s.Media
+	domainService = s.Domain
 	jwtConfig = s.JwtConfig
 }
 
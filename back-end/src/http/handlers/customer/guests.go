@@ -0,0 +1,286 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	handlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+var guestExportHeader = []string{"name", "phone", "group", "seat", "max_party_size", "token", "rsvp_status", "guests_count", "responded_at"}
+
+func ListGuestsHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	items, err := guestService.List(c.Request.Context(), customerID, idReq.ID)
+	if err != nil {
+		writeGuestError(c, err, "failed to list guests")
+		return
+	}
+
+	responseItems := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		responseItems = append(responseItems, guestResponse(item))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": responseItems})
+}
+
+func CreateGuestHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	req, payload, err := customerRequest.NewGuestRequest(c)
+	if err != nil {
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	guest, err := guestService.Create(c.Request.Context(), customerID, idReq.ID, req.Input)
+	if err != nil {
+		writeGuestError(c, err, "failed to create guest")
+		return
+	}
+
+	c.JSON(http.StatusCreated, guestResponse(guest))
+}
+
+func UpdateGuestHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewGuestIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req, payload, err := customerRequest.NewGuestRequest(c)
+	if err != nil {
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	guest, err := guestService.Update(c.Request.Context(), customerID, idReq.InvitationID, idReq.GuestID, req.Input)
+	if err != nil {
+		writeGuestError(c, err, "failed to update guest")
+		return
+	}
+
+	c.JSON(http.StatusOK, guestResponse(guest))
+}
+
+func DeleteGuestHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewGuestIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := guestService.Delete(c.Request.Context(), customerID, idReq.InvitationID, idReq.GuestID); err != nil {
+		writeGuestError(c, err, "failed to delete guest")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func ImportGuestsHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	req, rowErrors, err := customerRequest.NewImportGuestsRequest(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerRequest.ErrGuestImportRowsFailed):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "validation_failed",
+				"rows":    rowErrors,
+				"message": "some rows are invalid; nothing was imported",
+			})
+		case errors.Is(err, customerRequest.ErrMissingImportFile),
+			errors.Is(err, customerRequest.ErrInvalidImportFile),
+			errors.Is(err, customerRequest.ErrEmptyImportFile),
+			errors.Is(err, customerRequest.ErrMissingNameColumn),
+			errors.Is(err, customerRequest.ErrTooManyImportRows):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		}
+		return
+	}
+
+	result, err := guestService.Import(c.Request.Context(), customerID, idReq.ID, req.Rows, req.Columns)
+	if err != nil {
+		writeGuestError(c, err, "failed to import guests")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"created": result.Created,
+		"updated": result.Updated,
+	})
+}
+
+func ExportGuestsHandler(c *gin.Context) {
+	if guestService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewExportGuestsRequest(c)
+	if err != nil {
+		if errors.Is(err, customerRequest.ErrInvalidExportFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	filename := fmt.Sprintf("guests-%s", time.Now().Format("20060102"))
+	started, err := handlers.StreamSpreadsheet(c, req.Format, filename, guestExportHeader, func(write func([]string) error) error {
+		return guestService.Export(c.Request.Context(), customerID, req.InvitationID, func(item repository.GuestWithRsvp) error {
+			return write(guestExportRow(item))
+		})
+	})
+	if err != nil && !started {
+		writeGuestError(c, err, "failed to export guests")
+	}
+}
+
+func guestExportRow(item repository.GuestWithRsvp) []string {
+	status := "pending"
+	if item.Attendance != nil && *item.Attendance != "" {
+		status = *item.Attendance
+	}
+	guestsCount := ""
+	if item.GuestsCount != nil {
+		guestsCount = strconv.Itoa(*item.GuestsCount)
+	}
+	respondedAt := ""
+	if item.RespondedAt != nil {
+		respondedAt = item.RespondedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		spreadsheet.EscapeFormula(item.Name),
+		spreadsheet.EscapeFormula(item.Phone),
+		spreadsheet.EscapeFormula(item.GroupName),
+		spreadsheet.EscapeFormula(item.Seat),
+		strconv.Itoa(item.MaxPartySize),
+		// Tokens are base64url and may start with "-".
+		spreadsheet.EscapeFormula(item.Token),
+		status,
+		guestsCount,
+		respondedAt,
+	}
+}
+
+func writeGuestError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrGuestServiceNotConfigured):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "guest service unavailable"})
+	case errors.Is(err, customerService.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+	case errors.Is(err, customerService.ErrGuestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "guest not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func guestResponse(guest model.Guest) gin.H {
+	return gin.H{
+		"id":             guest.ID,
+		"invitation_id":  guest.InvitationID,
+		"name":           guest.Name,
+		"phone":          guest.Phone,
+		"group_name":     guest.GroupName,
+		"seat":           guest.Seat,
+		"max_party_size": guest.MaxPartySize,
+		"token":          guest.Token,
+		"created_at":     guest.CreatedAt,
+		"updated_at":     guest.UpdatedAt,
+	}
+}
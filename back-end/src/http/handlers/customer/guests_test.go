@@ -0,0 +1,34 @@
+package customer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+func TestGuestExportRowEscapesFormulas(t *testing.T) {
+	attendance := "attending"
+	guestsCount := 2
+	respondedAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	item := repository.GuestWithRsvp{
+		Guest: model.Guest{
+			Name:         `=HYPERLINK("https://evil.example","Pak Hendra")`,
+			Phone:        "+62812",
+			GroupName:    "@Keluarga",
+			Seat:         "-A3",
+			MaxPartySize: 2,
+			Token:        "-x7Qm",
+		},
+		Attendance:  &attendance,
+		GuestsCount: &guestsCount,
+		RespondedAt: &respondedAt,
+	}
+
+	want := []string{`'=HYPERLINK("https://evil.example","Pak Hendra")`, "'+62812", "'@Keluarga", "'-A3", "2", "'-x7Qm", "attending", "2", "2026-10-01T02:30:00Z"}
+	if got := guestExportRow(item); !reflect.DeepEqual(got, want) {
+		t.Fatalf("guestExportRow = %q, want %q", got, want)
+	}
+}
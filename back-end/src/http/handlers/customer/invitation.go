@@ -2,6 +2,7 @@ package customer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -10,11 +11,86 @@ import (
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
 	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
 )
 
+func ListInvitationsHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	items, err := invitationService.ListForCustomer(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list invitations"})
+		return
+	}
+
+	responseItems := make([]gin.H, 0, len(items))
+	for _, inv := range items {
+		responseItems = append(responseItems, invitationSummaryResponse(inv))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": responseItems})
+}
+
+func CreateInvitationHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, payload, err := customerRequest.NewCreateInvitationRequest(c, customerID)
+	if err != nil {
+		if errors.Is(err, customerRequest.ErrInvalidEventDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event_date"})
+			return
+		}
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	if len(req.Input.Content) > 0 {
+		limits, err := planEnforcer.GetCustomerLimits(c.Request.Context(), customerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check plan"})
+			return
+		}
+		if err := planEnforcer.ValidateContent(c.Request.Context(), customerID, req.Input.Content, limits); err != nil {
+			writeContentError(c, err, "failed to check content")
+			return
+		}
+	}
+
+	inv, err := invitationService.Create(c.Request.Context(), req.Input)
+	if err != nil {
+		if errors.Is(err, customerService.ErrInvitationLimitReached) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitationSummaryResponse(inv))
+}
+
 func GetInvitationHandler(c *gin.Context) {
 	if invitationService == nil {
 		writeServiceUnavailable(c)
@@ -42,17 +118,23 @@ func GetInvitationHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":           inv.ID,
-		"customer_id":  inv.CustomerID,
-		"slug":         inv.Slug,
-		"title":        inv.Title,
-		"search_name":  inv.SearchName,
-		"event_date":   inv.EventDate,
-		"theme_key":    inv.ThemeKey,
-		"is_published": inv.IsPublished,
-		"content":      json.RawMessage(inv.Content),
-		"created_at":   inv.CreatedAt,
-		"updated_at":   inv.UpdatedAt,
+		"id":                      inv.ID,
+		"customer_id":             inv.CustomerID,
+		"slug":                    inv.Slug,
+		"title":                   inv.Title,
+		"search_name":             inv.SearchName,
+		"event_date":              inv.EventDate,
+		"theme_key":               inv.ThemeKey,
+		"is_published":            inv.IsPublished,
+		"content":                 json.RawMessage(inv.Content),
+		"published_content":       publishedContent(inv),
+		"published_at":            inv.PublishedAt,
+		"has_unpublished_changes": !content.Equal(inv.Content, inv.PublishedContent),
+		"publish_at":              inv.PublishAt,
+		"archive_at":              inv.ArchiveAt,
+		"require_wish_approval":   inv.RequireWishApproval,
+		"created_at":              inv.CreatedAt,
+		"updated_at":              inv.UpdatedAt,
 	})
 }
 
@@ -103,8 +185,8 @@ func UpdateInvitationHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check plan"})
 		return
 	}
-	if err := customerService.ValidateContent(req.Content, limits); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
+	if err := planEnforcer.ValidateContent(c.Request.Context(), customerID, req.Content, limits); err != nil {
+		writeContentError(c, err, "failed to check content")
 		return
 	}
 
@@ -128,11 +210,6 @@ func UpdateInvitationHandler(c *gin.Context) {
 		searchName = title
 	}
 
-	isPublished := inv.IsPublished
-	if req.IsPublished != nil {
-		isPublished = *req.IsPublished
-	}
-
 	derivedSlug := deriveSlugFromContent(req.Content, customerID)
 	if derivedSlug == "" {
 		derivedSlug = req.Slug
@@ -142,14 +219,14 @@ func UpdateInvitationHandler(c *gin.Context) {
 	}
 
 	if err := invitationService.Update(c.Request.Context(), idReq.ID, repository.InvitationUpdateInput{
-		CustomerID:  customerID,
-		Slug:        derivedSlug,
-		Title:       title,
-		SearchName:  searchName,
-		EventDate:   eventDate,
-		ThemeKey:    themeKey,
-		IsPublished: isPublished,
-		Content:     req.Content,
+		CustomerID:          customerID,
+		Slug:                derivedSlug,
+		Title:               title,
+		SearchName:          searchName,
+		EventDate:           eventDate,
+		ThemeKey:            themeKey,
+		Content:             req.Content,
+		RequireWishApproval: req.RequireWishApproval,
 	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update invitation"})
 		return
@@ -158,6 +235,122 @@ func UpdateInvitationHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// PublishInvitationHandler copies the draft to the published snapshot guests see.
+func PublishInvitationHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+	setPublication(c, invitationService.Publish)
+}
+
+func UnpublishInvitationHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+	setPublication(c, invitationService.Unpublish)
+}
+
+func setPublication(c *gin.Context, apply func(ctx context.Context, customerID, invitationID string) (model.Invitation, error)) {
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	inv, err := apply(c.Request.Context(), customerID, idReq.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerService.ErrInvitationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		default:
+			writeContentError(c, err, "failed to update publication")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationSummaryResponse(inv))
+}
+
+// PreviewInvitationHandler returns the draft content shaped like the public invitation response.
+func PreviewInvitationHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	draft, err := invitationService.GetDraftContent(c.Request.Context(), customerID, idReq.ID)
+	if err != nil {
+		if errors.Is(err, customerService.ErrInvitationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+func invitationSummaryResponse(inv model.Invitation) gin.H {
+	return gin.H{
+		"id":                      inv.ID,
+		"slug":                    inv.Slug,
+		"title":                   inv.Title,
+		"event_date":              inv.EventDate,
+		"theme_key":               inv.ThemeKey,
+		"is_published":            inv.IsPublished,
+		"published_at":            inv.PublishedAt,
+		"has_unpublished_changes": !content.Equal(inv.Content, inv.PublishedContent),
+		"publish_at":              inv.PublishAt,
+		"archive_at":              inv.ArchiveAt,
+		"created_at":              inv.CreatedAt,
+		"updated_at":              inv.UpdatedAt,
+	}
+}
+
+// writeContentError maps a failed content check: content that does not match the schema is 422,
+// media of another account and plan limits are 403, and anything else is a server error.
+func writeContentError(c *gin.Context, err error, fallback string) {
+	var schemaErrs content.ValidationErrors
+	switch {
+	case errors.As(err, &schemaErrs):
+		httpRequest.WriteValidationErrorStatus(c, http.StatusUnprocessableEntity, nil, schemaErrs)
+	case errors.Is(err, customerService.ErrForeignMedia):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "foreign_media"})
+	case errors.Is(err, customerService.ErrPlanLimitExceeded):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func publishedContent(inv model.Invitation) any {
+	if len(inv.PublishedContent) == 0 {
+		return nil
+	}
+	return json.RawMessage(inv.PublishedContent)
+}
+
 func deriveSlugFromContent(raw json.RawMessage, customerID string) string {
 	if len(bytes.TrimSpace(raw)) == 0 {
 		return ""
@@ -192,4 +385,3 @@ func deriveSlugFromContent(raw json.RawMessage, customerID string) string {
 
 	return base + "-" + slug.ShortID(customerID)
 }
-
@@ -0,0 +1,54 @@
+package customer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func TestWriteContentError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, schemaErr := content.Validate([]byte(`{"event": {"akadTime": "8am"}}`))
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantKey    string
+		wantValue  string
+	}{
+		{name: "schema", err: fmt.Errorf("update: %w", schemaErr), wantStatus: http.StatusUnprocessableEntity, wantKey: "error", wantValue: "validation_failed"},
+		{name: "plan limit", err: customerService.ValidateContent([]byte(`{"gallery": {"photos": ["https://a.example/1.jpg", "https://a.example/2.jpg"]}}`), customerService.PlanLimits{GalleryPhotos: 1, MaxContentKB: 64}), wantStatus: http.StatusForbidden, wantKey: "code", wantValue: "plan_limit_exceeded"},
+		{name: "content too large", err: fmt.Errorf("%w (max 1 KB)", customerService.ErrContentTooLarge), wantStatus: http.StatusForbidden, wantKey: "code", wantValue: "plan_limit_exceeded"},
+		{name: "foreign media", err: fmt.Errorf("%w: gallery.photos[0]", customerService.ErrForeignMedia), wantStatus: http.StatusForbidden, wantKey: "code", wantValue: "foreign_media"},
+		{name: "other", err: errors.New("connection reset"), wantStatus: http.StatusInternalServerError, wantKey: "error", wantValue: "failed to update invitation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+
+			writeContentError(c, tt.err, "failed to update invitation")
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body: %v", err)
+			}
+			if body[tt.wantKey] != tt.wantValue {
+				t.Fatalf("%s = %v, want %q (body %s)", tt.wantKey, body[tt.wantKey], tt.wantValue, recorder.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package customer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func UploadMediaHandler(c *gin.Context) {
+	if mediaService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewUploadMediaRequest(c)
+	if err != nil {
+		if errors.Is(err, customerRequest.ErrMediaFileTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := mediaService.Upload(c.Request.Context(), customerID, req.Upload)
+	if err != nil {
+		writeMediaError(c, err, "failed to upload media")
+		return
+	}
+
+	c.JSON(http.StatusCreated, mediaResponse(item))
+}
+
+func ListMediaHandler(c *gin.Context) {
+	if mediaService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	items, err := mediaService.List(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list media"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		response = append(response, mediaResponse(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": response})
+}
+
+func DeleteMediaHandler(c *gin.Context) {
+	if mediaService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewMediaIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing media id"})
+		return
+	}
+
+	if err := mediaService.Delete(c.Request.Context(), customerID, req.ID); err != nil {
+		writeMediaError(c, err, "failed to delete media")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func writeMediaError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+	case errors.Is(err, customerService.ErrUnsupportedMedia):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "only JPEG, PNG, GIF and WebP images or MP3, M4A, OGG and WAV audio can be uploaded"})
+	case errors.Is(err, customerService.ErrMediaTooLarge),
+		errors.Is(err, customerService.ErrMediaNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
+	case errors.Is(err, customerService.ErrMediaInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func mediaResponse(item model.Media) gin.H {
+	return gin.H{
+		"id":                item.ID,
+		"kind":              item.Kind,
+		"content_type":      item.ContentType,
+		"size_bytes":        item.SizeBytes,
+		"url":               item.URL,
+		"original_name":     item.OriginalName,
+		"width":             item.Width,
+		"height":            item.Height,
+		"processing_status": item.ProcessingStatus,
+		"variants":          customerService.DecodeMediaVariants(item),
+		"created_at":        item.CreatedAt,
+	}
+}
@@ -25,14 +25,50 @@ func GetMyPlanHandler(c *gin.Context) {
 		return
 	}
 
+	usage, err := planEnforcer.Usage(c.Request.Context(), customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve plan usage"})
+		return
+	}
+
+	invitations := make([]gin.H, 0, len(usage))
+	for _, item := range usage {
+		invitations = append(invitations, gin.H{
+			"invitation_id": item.InvitationID,
+			"slug":          item.Slug,
+			"title":         item.Title,
+			"rsvps":         quotaResponse(item.Rsvps, limits.MaxRsvps),
+			"wishes":        quotaResponse(item.Wishes, limits.MaxWishes),
+			"content_kb":    quotaResponse(int64(item.ContentKB), limits.MaxContentKB),
+			"story_entries": quotaResponse(int64(item.StoryEntries), limits.MaxStoryEntries),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"plan_code": planCode,
 		"limits": gin.H{
-			"gallery_photos": limits.GalleryPhotos,
-			"love_story":     limits.LoveStory,
-			"music":          limits.Music,
-			"gifts":          limits.Gifts,
-			"templates":      limits.Templates,
+			"gallery_photos":     limits.GalleryPhotos,
+			"love_story":         limits.LoveStory,
+			"music":              limits.Music,
+			"gifts":              limits.Gifts,
+			"custom_domain":      limits.CustomDomain,
+			"max_custom_domains": limits.MaxCustomDomains,
+			"templates":          limits.Templates,
+			"max_invitations":    limits.MaxInvitations,
+			"revision_history":   limits.RevisionHistory,
+			"upload_max_mb":      limits.UploadMaxMB,
+			"max_rsvps":          limits.MaxRsvps,
+			"max_wishes":         limits.MaxWishes,
+			"max_content_kb":     limits.MaxContentKB,
+			"max_story_entries":  limits.MaxStoryEntries,
+		},
+		"usage": gin.H{
+			"invitations":    quotaResponse(int64(len(usage)), limits.MaxInvitations),
+			"per_invitation": invitations,
 		},
 	})
 }
+
+func quotaResponse(used int64, limit int) gin.H {
+	return gin.H{"used": used, "limit": limit}
+}
@@ -1,6 +1,7 @@
 package customer
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -29,9 +30,13 @@ func CreatePaymentHandler(c *gin.Context) {
 	}
 
 	result, err := paymentService.Create(c.Request.Context(), req.Input)
+	if errors.Is(err, customerService.ErrPaymentGatewayNotConfigured) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "payment service unavailable"})
+		return
+	}
 	if err != nil {
 		switch err {
-		case customerService.ErrPaymentServiceNotConfigured, customerService.ErrMidtransNotConfigured:
+		case customerService.ErrPaymentServiceNotConfigured:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "payment service unavailable"})
 		case customerService.ErrCustomerNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "customer not found"})
@@ -43,16 +48,23 @@ func CreatePaymentHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"payment_id":          result.PaymentID,
-		"status":              result.Status,
-		"amount":              result.Amount,
-		"currency":            result.Currency,
-		"midtrans_order_id":   result.MidtransOrderID,
-		"midtrans_client_key": result.MidtransClientKey,
-		"midtrans_token":      result.MidtransToken,
-		"midtrans_redirect":   result.MidtransRedirect,
-	})
+	response := gin.H{
+		"payment_id":   result.PaymentID,
+		"status":       result.Status,
+		"amount":       result.Amount,
+		"currency":     result.Currency,
+		"provider":     result.Provider,
+		"order_id":     result.OrderID,
+		"redirect_url": result.RedirectURL,
+	}
+	// The Snap checkout in the front-end reads the midtrans_* fields.
+	if result.Provider == customerService.PaymentProviderMidtrans {
+		response["midtrans_order_id"] = result.OrderID
+		response["midtrans_client_key"] = result.ClientKey
+		response["midtrans_token"] = result.Token
+		response["midtrans_redirect"] = result.RedirectURL
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 func PaymentProgressHandler(c *gin.Context) {
@@ -74,26 +86,109 @@ func PaymentProgressHandler(c *gin.Context) {
 	}
 
 	result, err := paymentService.Progress(c.Request.Context(), req.Input)
+	if errors.Is(err, customerService.ErrPaymentGatewayNotConfigured) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "payment service unavailable"})
+		return
+	}
 	if err != nil {
 		switch err {
-		case customerService.ErrPaymentServiceNotConfigured, customerService.ErrMidtransNotConfigured:
+		case customerService.ErrPaymentServiceNotConfigured:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "payment service unavailable"})
 		case customerService.ErrPaymentNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
-		case customerService.ErrMidtransOrderNotFound:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "midtrans order id not found"})
+		case customerService.ErrPaymentOrderNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment order id not found"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payment progress"})
 		}
 		return
 	}
 
+	response := gin.H{
+		"payment_id":      result.PaymentID,
+		"status":          result.Status,
+		"provider":        result.Provider,
+		"provider_status": result.ProviderStatus,
+		"paid_at":         result.PaidAt,
+		"order_id":        result.OrderID,
+		"redirect_url":    result.RedirectURL,
+		"review_note":     result.ReviewNote,
+	}
+	if result.Provider == customerService.PaymentProviderMidtrans {
+		response["midtrans_status"] = result.ProviderStatus
+		response["midtrans_order_id"] = result.OrderID
+		response["midtrans_redirect"] = result.RedirectURL
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func ManualTransferInstructionsHandler(c *gin.Context) {
+	if paymentService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	account, err := paymentService.ManualTransferInstructions()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "manual transfer not available"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"payment_id":        result.PaymentID,
-		"status":            result.Status,
-		"midtrans_status":   result.MidtransStatus,
-		"paid_at":           result.PaidAt,
-		"midtrans_order_id": result.MidtransOrderID,
-		"midtrans_redirect": result.RedirectURL,
+		"bank_name":      account.BankName,
+		"account_number": account.AccountNumber,
+		"account_name":   account.AccountName,
+	})
+}
+
+func CreateManualTransferHandler(c *gin.Context) {
+	if paymentService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewCreateManualTransferRequest(c, customerID)
+	if err != nil {
+		if errors.Is(err, customerRequest.ErrReceiptTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := paymentService.CreateManualTransfer(c.Request.Context(), req.Input)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerService.ErrManualTransferNotConfigured):
+			c.JSON(http.StatusNotFound, gin.H{"error": "manual transfer not available"})
+		case errors.Is(err, customerService.ErrPaymentServiceNotConfigured):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "payment service unavailable"})
+		case errors.Is(err, customerService.ErrUnsupportedReceipt):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, customerService.ErrReceiptTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, customerService.ErrCustomerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "customer not found"})
+		case errors.Is(err, customerService.ErrPlanNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "plan not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create payment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"payment_id": payment.ID,
+		"status":     payment.Status,
+		"amount":     payment.Amount,
+		"currency":   payment.Currency,
+		"provider":   payment.Provider,
 	})
 }
@@ -0,0 +1,170 @@
+package customer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func ListRevisionsHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	items, err := invitationService.ListRevisions(c.Request.Context(), customerID, idReq.ID)
+	if err != nil {
+		writeRevisionError(c, err, "failed to list revisions")
+		return
+	}
+
+	responseItems := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		responseItems = append(responseItems, revisionResponse(item))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": responseItems})
+}
+
+func GetRevisionHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewRevisionRequest(c)
+	if err != nil {
+		writeRevisionRequestError(c, err)
+		return
+	}
+
+	revision, err := invitationService.GetRevision(c.Request.Context(), customerID, req.InvitationID, req.Version)
+	if err != nil {
+		writeRevisionError(c, err, "failed to load revision")
+		return
+	}
+
+	response := revisionResponse(revision)
+	response["content"] = json.RawMessage(revision.Content)
+	c.JSON(http.StatusOK, response)
+}
+
+func DiffRevisionsHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewDiffRevisionsRequest(c)
+	if err != nil {
+		writeRevisionRequestError(c, err)
+		return
+	}
+
+	diff, err := invitationService.DiffRevisions(c.Request.Context(), customerID, req.InvitationID, req.From, req.To)
+	if err != nil {
+		writeRevisionError(c, err, "failed to diff revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    diff.From,
+		"to":      diff.To,
+		"changes": diff.Changes,
+	})
+}
+
+func RestoreRevisionHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewRevisionRequest(c)
+	if err != nil {
+		writeRevisionRequestError(c, err)
+		return
+	}
+
+	revision, err := invitationService.RestoreRevision(c.Request.Context(), customerID, req.InvitationID, req.Version)
+	if err != nil {
+		writeRevisionError(c, err, "failed to restore revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "ok",
+		"restored_from": revision.Version,
+		"content":       json.RawMessage(revision.Content),
+	})
+}
+
+func writeRevisionRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, customerRequest.ErrMissingID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+	case errors.Is(err, customerRequest.ErrInvalidRevision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+	case errors.Is(err, customerRequest.ErrMissingDiffVersions):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	}
+}
+
+func writeRevisionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+	case errors.Is(err, customerService.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+	default:
+		writeContentError(c, err, fallback)
+	}
+}
+
+func revisionResponse(item model.InvitationRevision) gin.H {
+	return gin.H{
+		"version":       item.Version,
+		"author_type":   item.AuthorType,
+		"author_id":     item.AuthorID,
+		"restored_from": item.RestoredFrom,
+		"created_at":    item.CreatedAt,
+	}
+}
@@ -0,0 +1,150 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	handlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func ListRsvpsHandler(c *gin.Context) {
+	if rsvpService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewListRsvpsRequest(c)
+	if err != nil {
+		writeListRsvpsRequestError(c, err)
+		return
+	}
+
+	items, total, err := rsvpService.List(c.Request.Context(), customerID, req.Filters)
+	if err != nil {
+		writeRsvpError(c, err, "failed to list rsvps")
+		return
+	}
+
+	responseItems := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		responseItems = append(responseItems, gin.H{
+			"id":           item.ID,
+			"guest_id":     item.GuestID,
+			"guest_name":   item.GuestName,
+			"attendance":   item.Attendance,
+			"guests_count": item.GuestsCount,
+			"message":      item.Message,
+			"created_at":   item.CreatedAt,
+			"updated_at":   item.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":  responseItems,
+		"total":  total,
+		"limit":  req.Filters.Limit,
+		"offset": req.Filters.Offset,
+	})
+}
+
+func RsvpSummaryHandler(c *gin.Context) {
+	if rsvpService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewInvitationIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		return
+	}
+
+	summary, err := rsvpService.Summary(c.Request.Context(), customerID, idReq.ID)
+	if err != nil {
+		writeRsvpError(c, err, "failed to load rsvp summary")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_responses":     summary.TotalResponses,
+		"attending_count":     summary.AttendingCount,
+		"not_attending_count": summary.NotAttendingCount,
+		"total_guests":        summary.TotalGuests,
+	})
+}
+
+func ExportRsvpsHandler(c *gin.Context) {
+	if rsvpService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewListRsvpsRequest(c)
+	if err != nil {
+		writeListRsvpsRequestError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("rsvps-%s", time.Now().Format("20060102"))
+	started, err := handlers.StreamSpreadsheet(c, req.Format, filename, handlers.RsvpExportHeader, func(write func([]string) error) error {
+		return rsvpService.Export(c.Request.Context(), customerID, req.Filters, func(item model.RSVP) error {
+			return write(handlers.RsvpExportRow(item))
+		})
+	})
+	if err != nil && !started {
+		writeRsvpError(c, err, "failed to export rsvps")
+	}
+}
+
+func writeListRsvpsRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, customerRequest.ErrMissingID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+	case errors.Is(err, customerRequest.ErrInvalidAttendance):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attendance"})
+	case errors.Is(err, customerRequest.ErrInvalidLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+	case errors.Is(err, customerRequest.ErrInvalidOffset):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+	case errors.Is(err, customerRequest.ErrInvalidExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	}
+}
+
+func writeRsvpError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrRsvpServiceNotConfigured):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "rsvp service unavailable"})
+	case errors.Is(err, customerService.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
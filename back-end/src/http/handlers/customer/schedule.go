@@ -0,0 +1,54 @@
+package customer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+// ScheduleInvitationHandler sets when the invitation is published and archived automatically.
+func ScheduleInvitationHandler(c *gin.Context) {
+	if invitationService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, payload, err := customerRequest.NewScheduleRequest(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerRequest.ErrMissingID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		case errors.Is(err, customerRequest.ErrInvalidScheduleTime), errors.Is(err, customerRequest.ErrConflictingArchiveAt):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			httpRequest.WriteValidationError(c, payload, err)
+		}
+		return
+	}
+
+	inv, err := invitationService.SetSchedule(c.Request.Context(), customerID, req.InvitationID, req.Input)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerService.ErrInvitationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		case errors.Is(err, customerService.ErrInvalidSchedule):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			writeContentError(c, err, "failed to update schedule")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationSummaryResponse(inv))
+}
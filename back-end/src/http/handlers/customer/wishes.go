@@ -0,0 +1,150 @@
+package customer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+func ListWishesHandler(c *gin.Context) {
+	if wishService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req, err := customerRequest.NewListWishesRequest(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerRequest.ErrMissingID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		case errors.Is(err, customerRequest.ErrInvalidWishStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		case errors.Is(err, customerRequest.ErrInvalidLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		case errors.Is(err, customerRequest.ErrInvalidOffset):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		}
+		return
+	}
+
+	items, total, err := wishService.List(c.Request.Context(), customerID, req.Filters)
+	if err != nil {
+		writeWishError(c, err, "failed to list wishes")
+		return
+	}
+
+	responseItems := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		responseItems = append(responseItems, wishResponse(item))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":  responseItems,
+		"total":  total,
+		"limit":  req.Filters.Limit,
+		"offset": req.Filters.Offset,
+	})
+}
+
+func ModerateWishHandler(c *gin.Context) {
+	if wishService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewWishIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req, payload, err := customerRequest.NewModerateWishRequest(c)
+	if err != nil {
+		if errors.Is(err, customerRequest.ErrEmptyWishModeration) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		httpRequest.WriteValidationError(c, payload, err)
+		return
+	}
+
+	wish, err := wishService.Moderate(c.Request.Context(), customerID, idReq.InvitationID, idReq.WishID, req.Input)
+	if err != nil {
+		writeWishError(c, err, "failed to update wish")
+		return
+	}
+
+	c.JSON(http.StatusOK, wishResponse(wish))
+}
+
+func DeleteWishHandler(c *gin.Context) {
+	if wishService == nil {
+		writeServiceUnavailable(c)
+		return
+	}
+
+	customerID, ok := customerMiddleware.GetCustomerID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idReq, err := customerRequest.NewWishIDRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := wishService.Delete(c.Request.Context(), customerID, idReq.InvitationID, idReq.WishID); err != nil {
+		writeWishError(c, err, "failed to delete wish")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func writeWishError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, customerService.ErrWishServiceNotConfigured):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "wish service unavailable"})
+	case errors.Is(err, customerService.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+	case errors.Is(err, customerService.ErrWishNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "wish not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func wishResponse(item model.Wish) gin.H {
+	return gin.H{
+		"id":          item.ID,
+		"rsvp_id":     item.RsvpID,
+		"guest_name":  item.GuestName,
+		"message":     item.Message,
+		"status":      item.Status,
+		"pinned":      item.Pinned,
+		"flag_reason": item.FlagReason,
+		"created_at":  item.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+// StreamSpreadsheet writes rows produced by each as a CSV or XLSX download. The response is only
+// started once the first row (or the end of an empty result) is reached, so when it returns
+// started=false the caller can still reply with a JSON error.
+func StreamSpreadsheet(c *gin.Context, format, filename string, header []string, each func(write func([]string) error) error) (started bool, err error) {
+	var writer spreadsheet.Writer
+	start := func() error {
+		if writer != nil {
+			return nil
+		}
+		c.Header("Content-Type", spreadsheet.ContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+		c.Status(http.StatusOK)
+
+		w, err := spreadsheet.NewWriter(format, c.Writer)
+		if err != nil {
+			return err
+		}
+		writer = w
+		return writer.Write(header)
+	}
+
+	err = each(func(row []string) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return writer.Write(row)
+	})
+	if err == nil {
+		err = start()
+	}
+	if writer == nil {
+		return false, err
+	}
+
+	if err != nil {
+		log.Printf("stream %s: %v", filename, err)
+	}
+	if closeErr := writer.Close(); closeErr != nil {
+		log.Printf("stream %s: %v", filename, closeErr)
+	}
+	return true, err
+}
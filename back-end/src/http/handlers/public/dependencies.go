@@ -15,6 +15,7 @@ var (
 	paymentSvc          *customerService.PaymentService
 	publicInvitationSvc *customerService.PublicInvitationService
 	planSvc             *publicService.PlanService
+	mediaSvc            *customerService.MediaService
 )
 
 type Services struct {
@@ -23,6 +24,7 @@ type Services struct {
 	Payment          *customerService.PaymentService
 	PublicInvitation *customerService.PublicInvitationService
 	Plan             *publicService.PlanService
+	Media            *customerService.MediaService
 }
 
 func ConfigureServices(s Services) {
@@ -31,6 +33,7 @@ func ConfigureServices(s Services) {
 	paymentSvc = s.Payment
 	publicInvitationSvc = s.PublicInvitation
 	planSvc = s.Plan
+	mediaSvc = s.Media
 }
 
 func writeServiceUnavailable(c *gin.Context) {
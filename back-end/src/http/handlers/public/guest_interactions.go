@@ -2,13 +2,25 @@ package public
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	publicMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/public"
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	publicRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/public"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
 	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
+)
+
+const (
+	rsvpDeviceMaxAge       = 365 * 24 * 60 * 60
+	wishStreamPingInterval = 25 * time.Second
 )
 
 func CreateRsvpHandler(c *gin.Context) {
@@ -30,24 +42,65 @@ func CreateRsvpHandler(c *gin.Context) {
 		return
 	}
 
+	if req.Input.DeviceID == "" {
+		deviceID, err := slug.GenerateToken(16)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to submit rsvp"})
+			return
+		}
+		req.Input.DeviceID = deviceID
+	}
+	setRsvpDeviceCookie(c, req.Input.DeviceID)
+
 	result, err := publicInvitationSvc.CreateRsvp(c.Request.Context(), req.Input)
 	if err != nil {
 		switch {
 		case errors.Is(err, customerService.ErrPublicInvitationNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		case errors.Is(err, customerService.ErrInvalidGuestToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid guest token"})
+		case errors.Is(err, customerService.ErrMessageRejected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "message rejected", "code": "message_rejected"})
+		case errors.Is(err, customerService.ErrRsvpQuotaReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to submit rsvp"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"id":           result.ID,
-		"guest_name":   result.GuestName,
-		"attendance":   result.Attendance,
-		"guests_count": result.GuestsCount,
-		"message":      result.Message,
-		"created_at":   result.CreatedAt,
+	status := http.StatusCreated
+	if result.Updated {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{
+		"id":               result.ID,
+		"guest_name":       result.GuestName,
+		"attendance":       result.Attendance,
+		"guests_count":     result.GuestsCount,
+		"message":          result.Message,
+		"updated":          result.Updated,
+		"message_rejected": result.MessageRejected,
+		"device_id":        req.Input.DeviceID,
+		"created_at":       result.CreatedAt,
+		"updated_at":       result.UpdatedAt,
+	})
+}
+
+func setRsvpDeviceCookie(c *gin.Context, deviceID string) {
+	sameSite := http.SameSiteLaxMode
+	if strings.EqualFold(os.Getenv("RSVP_COOKIE_SAMESITE"), "none") {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     publicRequest.RsvpDeviceCookie,
+		Value:    deviceID,
+		Path:     "/",
+		MaxAge:   rsvpDeviceMaxAge,
+		HttpOnly: true,
+		Secure:   strings.EqualFold(os.Getenv("RSVP_COOKIE_SECURE"), "true"),
+		SameSite: sameSite,
 	})
 }
 
@@ -75,6 +128,10 @@ func CreateWishHandler(c *gin.Context) {
 		switch {
 		case errors.Is(err, customerService.ErrPublicInvitationNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		case errors.Is(err, customerService.ErrMessageRejected):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "message rejected", "code": "message_rejected"})
+		case errors.Is(err, customerService.ErrWishQuotaReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "plan_limit_exceeded"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to submit wish"})
 		}
@@ -85,6 +142,7 @@ func CreateWishHandler(c *gin.Context) {
 		"id":         result.ID,
 		"guest_name": result.GuestName,
 		"message":    result.Message,
+		"status":     result.Status,
 		"created_at": result.CreatedAt,
 	})
 }
@@ -103,9 +161,11 @@ func ListWishesHandler(c *gin.Context) {
 	}
 
 	req := publicRequest.NewListWishesRequest(c, tenant.ID, slugReq.Slug)
-	items, err := publicInvitationSvc.ListWishes(c.Request.Context(), req.Input)
+	result, err := publicInvitationSvc.ListWishes(c.Request.Context(), req.Input)
 	if err != nil {
 		switch {
+		case errors.Is(err, query.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
 		case errors.Is(err, customerService.ErrPublicInvitationNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
 		default:
@@ -114,15 +174,84 @@ func ListWishesHandler(c *gin.Context) {
 		return
 	}
 
-	responseItems := make([]gin.H, 0, len(items))
-	for _, item := range items {
+	responseItems := make([]gin.H, 0, len(result.Items))
+	for _, item := range result.Items {
 		responseItems = append(responseItems, gin.H{
 			"id":         item.ID,
 			"guest_name": item.GuestName,
 			"message":    item.Message,
+			"pinned":     item.Pinned,
 			"created_at": item.CreatedAt,
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": responseItems})
+	var nextCursor any
+	if result.NextCursor != "" {
+		nextCursor = result.NextCursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":       responseItems,
+		"next_cursor": nextCursor,
+		"total":       result.Total,
+	})
+}
+
+// StreamWishesHandler pushes wishes of an invitation as Server-Sent Events as they are posted or
+// moderated, for live displays at the venue.
+func StreamWishesHandler(c *gin.Context) {
+	tenant, ok := publicMiddleware.GetTenant(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant missing"})
+		return
+	}
+
+	slugReq, err := publicRequest.NewInvitationSlugRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing invitation slug"})
+		return
+	}
+
+	req := publicRequest.NewStreamWishesRequest(tenant.ID, slugReq.Slug)
+	events, unsubscribe, err := publicInvitationSvc.SubscribeWishes(c.Request.Context(), req.Input)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerService.ErrPublicInvitationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		case errors.Is(err, customerService.ErrWishStreamUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "wish stream unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open wish stream"})
+		}
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	c.Writer.Flush()
+
+	ping := time.NewTicker(wishStreamPingInterval)
+	defer ping.Stop()
+
+	done := c.Request.Context().Done()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-done:
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Name, event.Data)
+			return true
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			return true
+		}
+	})
 }
@@ -2,11 +2,15 @@ package public
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	publicMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/public"
 	publicRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/public"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 )
 
 func GetInvitationHandler(c *gin.Context) {
@@ -36,5 +40,69 @@ func GetInvitationHandler(c *gin.Context) {
 		return
 	}
 
+	if mediaSvc != nil {
+		images, err := mediaSvc.ResponsiveImages(c.Request.Context(), tenant.ID, content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load images"})
+			return
+		}
+		content["images"] = responsiveImagesResponse(images)
+	}
+
+	if req.GuestToken != "" && publicInvitationSvc != nil {
+		guest, err := publicInvitationSvc.GetGuest(c.Request.Context(), customerService.GetGuestInput{
+			CustomerID: tenant.ID,
+			Slug:       req.Slug,
+			Token:      req.GuestToken,
+		})
+		switch {
+		case err == nil:
+			content["guest"] = gin.H{
+				"name":           guest.Name,
+				"group_name":     guest.GroupName,
+				"seat":           guest.Seat,
+				"max_party_size": guest.MaxPartySize,
+				"token":          guest.Token,
+			}
+		case errors.Is(err, customerService.ErrInvalidGuestToken):
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load guest"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, content)
 }
+
+// responsiveImagesResponse maps every uploaded photo URL in the content to its resized variants,
+// ready for an <img srcset>.
+func responsiveImagesResponse(images map[string]model.Media) gin.H {
+	out := gin.H{}
+	for url, item := range images {
+		variants := customerService.DecodeMediaVariants(item)
+		if len(variants) == 0 {
+			continue
+		}
+
+		srcset := make([]string, 0, len(variants))
+		items := make([]gin.H, 0, len(variants))
+		for _, variant := range variants {
+			srcset = append(srcset, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+			items = append(items, gin.H{
+				"name":   variant.Name,
+				"url":    variant.URL,
+				"width":  variant.Width,
+				"height": variant.Height,
+				"type":   variant.ContentType,
+			})
+		}
+		out[url] = gin.H{
+			"src":      variants[len(variants)-1].URL,
+			"srcset":   strings.Join(srcset, ", "),
+			"width":    item.Width,
+			"height":   item.Height,
+			"variants": items,
+		}
+	}
+	return out
+}
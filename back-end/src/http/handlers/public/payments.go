@@ -5,32 +5,43 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	publicRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/public"
 	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 )
 
 func MidtransWebhookHandler(c *gin.Context) {
+	handlePaymentWebhook(c, customerService.PaymentProviderMidtrans)
+}
+
+func XenditWebhookHandler(c *gin.Context) {
+	handlePaymentWebhook(c, customerService.PaymentProviderXendit)
+}
+
+func handlePaymentWebhook(c *gin.Context, provider string) {
 	if paymentSvc == nil {
 		writeServiceUnavailable(c)
 		return
 	}
 
-	req, payload, err := publicRequest.NewMidtransWebhookRequest(c)
+	req, err := publicRequest.NewPaymentWebhookRequest(c, provider)
 	if err != nil {
-		httpRequest.WriteValidationError(c, payload, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	result, err := paymentSvc.HandleMidtransWebhook(c.Request.Context(), req.Input)
+	result, err := paymentSvc.HandleWebhook(c.Request.Context(), req.Input)
 	if err != nil {
 		switch {
-		case errors.Is(err, customerService.ErrInvalidMidtransSignature):
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid midtrans signature"})
-		case errors.Is(err, customerService.ErrPaymentNotFound), errors.Is(err, customerService.ErrMidtransOrderNotFound):
+		case errors.Is(err, customerService.ErrPaymentGatewayNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": provider + " not configured"})
+		case errors.Is(err, customerService.ErrInvalidWebhookSignature):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid " + provider + " signature"})
+		case errors.Is(err, customerService.ErrInvalidWebhookPayload):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, customerService.ErrPaymentNotFound), errors.Is(err, customerService.ErrPaymentOrderNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process midtrans webhook"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process " + provider + " webhook"})
 		}
 		return
 	}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+var RsvpExportHeader = []string{"guest_name", "attendance", "guests_count", "message", "created_at", "updated_at"}
+
+// RsvpExportRow formats one RSVP for the customer and admin exports. Guests type the name and
+// message on the public form, so both are escaped against formula injection.
+func RsvpExportRow(item model.RSVP) []string {
+	return []string{
+		spreadsheet.EscapeFormula(item.GuestName),
+		item.Attendance,
+		strconv.Itoa(item.GuestsCount),
+		spreadsheet.EscapeFormula(item.Message),
+		item.CreatedAt.UTC().Format(time.RFC3339),
+		item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
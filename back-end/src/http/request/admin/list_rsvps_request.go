@@ -0,0 +1,21 @@
+package adminrequest
+
+import (
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+)
+
+var (
+	ErrInvalidAttendance   = httpRequest.ErrInvalidAttendance
+	ErrInvalidExportFormat = httpRequest.ErrInvalidExportFormat
+)
+
+type ListRsvpsRequest = httpRequest.ListRsvpsRequest
+
+func NewListRsvpsRequest(c *gin.Context) (ListRsvpsRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return ListRsvpsRequest{}, err
+	}
+	return httpRequest.NewListRsvpsRequest(c, idReq.ID, defaultListLimit, maxListLimit)
+}
@@ -0,0 +1,81 @@
+package adminrequest
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+)
+
+type paymentReviewPayload struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+type PaymentIDRequest struct {
+	ID string
+}
+
+type ReviewPaymentRequest struct {
+	ID   string
+	Note string
+}
+
+func NewPaymentIDRequest(c *gin.Context) (PaymentIDRequest, error) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return PaymentIDRequest{}, ErrMissingID
+	}
+	if !httpRequest.IsUUID(id) {
+		return PaymentIDRequest{}, ErrInvalidID
+	}
+	return PaymentIDRequest{ID: id}, nil
+}
+
+// NewReviewPaymentRequest reads the optional {"note": "..."} body of an approval or rejection.
+func NewReviewPaymentRequest(c *gin.Context) (ReviewPaymentRequest, any, error) {
+	idReq, err := NewPaymentIDRequest(c)
+	if err != nil {
+		return ReviewPaymentRequest{}, nil, err
+	}
+
+	var payload paymentReviewPayload
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			return ReviewPaymentRequest{}, payload, err
+		}
+		if err := httpRequest.ValidateStruct(payload); err != nil {
+			return ReviewPaymentRequest{}, payload, err
+		}
+	}
+
+	return ReviewPaymentRequest{ID: idReq.ID, Note: strings.TrimSpace(payload.Note)}, payload, nil
+}
+
+type paymentRefundPayload struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type RefundPaymentRequest struct {
+	ID     string
+	Reason string
+}
+
+// NewRefundPaymentRequest reads the {"reason": "..."} body of a refund; the reason is kept with
+// the payment's events.
+func NewRefundPaymentRequest(c *gin.Context) (RefundPaymentRequest, any, error) {
+	idReq, err := NewPaymentIDRequest(c)
+	if err != nil {
+		return RefundPaymentRequest{}, nil, err
+	}
+
+	var payload paymentRefundPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return RefundPaymentRequest{}, payload, err
+	}
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return RefundPaymentRequest{}, payload, err
+	}
+
+	return RefundPaymentRequest{ID: idReq.ID, Reason: payload.Reason}, payload, nil
+}
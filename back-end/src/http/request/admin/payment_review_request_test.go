@@ -0,0 +1,42 @@
+package adminrequest
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewPaymentIDRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{name: "uuid", id: "5b0c1f8e-3d4a-4c7b-9e2f-1a2b3c4d5e6f"},
+		{name: "upper case uuid", id: "5B0C1F8E-3D4A-4C7B-9E2F-1A2B3C4D5E6F"},
+		{name: "missing", id: " ", wantErr: ErrMissingID},
+		{name: "not a uuid", id: "123", wantErr: ErrInvalidID},
+		{name: "misplaced hyphen", id: "5b0c1f8e3-d4a-4c7b-9e2f-1a2b3c4d5e6f", wantErr: ErrInvalidID},
+		{name: "non-hex digit", id: "5b0c1f8e-3d4a-4c7b-9e2f-1a2b3c4d5e6g", wantErr: ErrInvalidID},
+		{name: "sql", id: "' OR 1=1 --", wantErr: ErrInvalidID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+			req, err := NewPaymentIDRequest(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPaymentIDRequest(%q) err = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if err == nil && req.ID != tt.id {
+				t.Fatalf("ID = %q, want %q", req.ID, tt.id)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"errors"
 	"net/http"
 	"reflect"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 )
 
 type FieldError struct {
@@ -26,12 +28,37 @@ func ValidateStruct(payload any) error {
 }
 
 func WriteValidationError(c *gin.Context, payload any, err error) {
-	verrs, ok := err.(validator.ValidationErrors)
+	WriteValidationErrorStatus(c, http.StatusBadRequest, payload, err)
+}
+
+// WriteValidationErrorStatus is WriteValidationError with another status for field errors, e.g.
+// 422 for stored content that a later check finds invalid.
+func WriteValidationErrorStatus(c *gin.Context, status int, payload any, err error) {
+	fieldErrors, ok := ValidationFieldErrors(payload, err)
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
 
+	c.JSON(status, gin.H{
+		"error":   "validation_failed",
+		"fields":  fieldErrors,
+		"message": "invalid input",
+	})
+}
+
+// ValidationFieldErrors converts validator errors into field errors keyed by JSON name.
+func ValidationFieldErrors(payload any, err error) ([]FieldError, bool) {
+	var contentErrs content.ValidationErrors
+	if errors.As(err, &contentErrs) {
+		return contentFieldErrors(contentErrs), true
+	}
+
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, false
+	}
+
 	fieldErrors := make([]FieldError, 0, len(verrs))
 	for _, verr := range verrs {
 		field := jsonFieldName(payload, verr.StructField())
@@ -46,12 +73,24 @@ func WriteValidationError(c *gin.Context, payload any, err error) {
 			Message: message,
 		})
 	}
+	return fieldErrors, true
+}
 
-	c.JSON(http.StatusBadRequest, gin.H{
-		"error":   "validation_failed",
-		"fields":  fieldErrors,
-		"message": "invalid input",
-	})
+// contentFieldErrors reports invitation content errors under the "content" payload field.
+func contentFieldErrors(errs content.ValidationErrors) []FieldError {
+	fieldErrors := make([]FieldError, 0, len(errs))
+	for _, item := range errs {
+		field := "content"
+		if item.Field != "" {
+			field += "." + item.Field
+		}
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   field,
+			Rule:    item.Rule,
+			Message: validationMessage(field, item.Rule),
+		})
+	}
+	return fieldErrors
 }
 
 func jsonFieldName(payload any, field string) string {
@@ -88,7 +127,43 @@ func validationMessage(field string, rule string) string {
 		return field + " must be a valid email"
 	case "min":
 		return field + " is too short"
+	case "max":
+		return field + " is too long"
+	case "isodate":
+		return field + " must be a date in YYYY-MM-DD format"
+	case "clock":
+		return field + " must be a time in HH:MM format"
+	case "weburl", "imageurl":
+		return field + " must be a valid URL"
+	case "oneof":
+		return field + " is not an allowed value"
+	case "unknown":
+		return field + " is not a known section"
+	case "json":
+		return field + " must be a JSON object"
+	case "type":
+		return field + " has the wrong type"
 	default:
 		return field + " is invalid"
 	}
 }
+
+// IsUUID reports whether value is a UUID in the hyphenated form Postgres uses for ids, so a
+// malformed path id is rejected before it reaches a uuid column and fails there.
+func IsUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i, r := range value {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if r != '-' {
+				return false
+			}
+		case '0' <= r && r <= '9', 'a' <= r && r <= 'f', 'A' <= r && r <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
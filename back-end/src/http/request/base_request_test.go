@@ -0,0 +1,46 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+)
+
+func TestValidationFieldErrorsForContent(t *testing.T) {
+	_, err := content.Validate([]byte(`{"event": {"akadDate": "12/12/2026"}, "gallery": {"photos": [""]}}`))
+
+	got, ok := ValidationFieldErrors(nil, fmt.Errorf("create invitation: %w", err))
+	if !ok {
+		t.Fatalf("ValidationFieldErrors did not recognize %v", err)
+	}
+	want := []FieldError{
+		{Field: "content.event.akadDate", Rule: "isodate", Message: "content.event.akadDate must be a date in YYYY-MM-DD format"},
+		{Field: "content.gallery.photos[0]", Rule: "required", Message: "content.gallery.photos[0] is required"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ValidationFieldErrors = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		raw  string
+		want FieldError
+	}{
+		{raw: `{"couple": `, want: FieldError{Field: "content", Rule: "json", Message: "content must be a JSON object"}},
+		{raw: `{"rsvp": {}}`, want: FieldError{Field: "content.rsvp", Rule: "unknown", Message: "content.rsvp is not a known section"}},
+		{raw: `{"couple": []}`, want: FieldError{Field: "content.couple", Rule: "type", Message: "content.couple has the wrong type"}},
+	}
+	for _, tt := range tests {
+		_, err := content.Validate([]byte(tt.raw))
+		got, ok := ValidationFieldErrors(nil, err)
+		if !ok || len(got) != 1 || got[0] != tt.want {
+			t.Errorf("ValidationFieldErrors(%s) = %+v, %v, want %+v", tt.raw, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := ValidationFieldErrors(nil, errors.New("connection reset")); ok {
+		t.Fatal("ValidationFieldErrors accepted an unrelated error")
+	}
+}
@@ -0,0 +1,44 @@
+package customerrequest
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+)
+
+var ErrMissingDomainID = errors.New("missing domain id")
+
+type customDomainPayload struct {
+	Hostname string `json:"hostname" binding:"required,max=253"`
+}
+
+type CustomDomainRequest struct {
+	Hostname string
+}
+
+type CustomDomainIDRequest struct {
+	ID string
+}
+
+func NewCustomDomainRequest(c *gin.Context) (CustomDomainRequest, any, error) {
+	var payload customDomainPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return CustomDomainRequest{}, payload, err
+	}
+
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return CustomDomainRequest{}, payload, err
+	}
+
+	return CustomDomainRequest{Hostname: strings.TrimSpace(payload.Hostname)}, payload, nil
+}
+
+func NewCustomDomainIDRequest(c *gin.Context) (CustomDomainIDRequest, error) {
+	id := strings.TrimSpace(c.Param("domainId"))
+	if id == "" {
+		return CustomDomainIDRequest{}, ErrMissingDomainID
+	}
+	return CustomDomainIDRequest{ID: id}, nil
+}
@@ -0,0 +1,223 @@
+package customerrequest
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+const maxGuestImportRows = 2000
+
+var (
+	ErrMissingImportFile     = errors.New("missing import file")
+	ErrInvalidImportFile     = errors.New("invalid import file")
+	ErrEmptyImportFile       = errors.New("import file has no guest rows")
+	ErrMissingNameColumn     = errors.New("import file has no name column")
+	ErrTooManyImportRows     = errors.New("too many rows in import file")
+	ErrInvalidExportFormat   = httpRequest.ErrInvalidExportFormat
+	ErrGuestImportRowsFailed = errors.New("guest import rows failed validation")
+)
+
+// guestImportColumns maps normalized header names to the fields of guestImportRow.
+var guestImportColumns = map[string]string{
+	"name":           "name",
+	"nama":           "name",
+	"guest_name":     "name",
+	"nama_tamu":      "name",
+	"phone":          "phone",
+	"telepon":        "phone",
+	"no_hp":          "phone",
+	"nomor_hp":       "phone",
+	"whatsapp":       "phone",
+	"wa":             "phone",
+	"group":          "group",
+	"group_name":     "group",
+	"grup":           "group",
+	"kelompok":       "group",
+	"seat":           "seat",
+	"kursi":          "seat",
+	"meja":           "seat",
+	"table":          "seat",
+	"max_party_size": "max_party_size",
+	"party_size":     "max_party_size",
+	"max_guests":     "max_party_size",
+	"jumlah_tamu":    "max_party_size",
+}
+
+type guestImportRow struct {
+	Name         string `json:"name" binding:"required,max=200"`
+	Phone        string `json:"phone" binding:"max=32"`
+	GroupName    string `json:"group" binding:"max=100"`
+	Seat         string `json:"seat" binding:"max=50"`
+	MaxPartySize int    `json:"max_party_size" binding:"gte=0,lte=50"`
+}
+
+// ImportRowError reports validation failures for one spreadsheet row (1-based, header included).
+type ImportRowError struct {
+	Row    int                      `json:"row"`
+	Fields []httpRequest.FieldError `json:"fields"`
+}
+
+type ImportGuestsRequest struct {
+	Rows    []customerService.GuestInput
+	Columns customerService.GuestImportColumns
+}
+
+func NewImportGuestsRequest(c *gin.Context) (ImportGuestsRequest, []ImportRowError, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return ImportGuestsRequest{}, nil, ErrMissingImportFile
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ImportGuestsRequest{}, nil, ErrInvalidImportFile
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ImportGuestsRequest{}, nil, ErrInvalidImportFile
+	}
+
+	records, err := spreadsheet.ReadRows(spreadsheet.DetectFormat(fileHeader.Filename, data), data)
+	if err != nil {
+		return ImportGuestsRequest{}, nil, ErrInvalidImportFile
+	}
+	if len(records) < 2 {
+		return ImportGuestsRequest{}, nil, ErrEmptyImportFile
+	}
+	if len(records)-1 > maxGuestImportRows {
+		return ImportGuestsRequest{}, nil, ErrTooManyImportRows
+	}
+
+	columns := make(map[string]int)
+	for idx, header := range records[0] {
+		if field, ok := guestImportColumns[normalizeImportHeader(header)]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = idx
+			}
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return ImportGuestsRequest{}, nil, ErrMissingNameColumn
+	}
+
+	rows := make([]customerService.GuestInput, 0, len(records)-1)
+	rowErrors := make([]ImportRowError, 0)
+	for i, record := range records[1:] {
+		cell := func(field string) string {
+			idx, ok := columns[field]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := guestImportRow{
+			Name:      cell("name"),
+			Phone:     cell("phone"),
+			GroupName: cell("group"),
+			Seat:      cell("seat"),
+		}
+
+		fieldErrors := make([]httpRequest.FieldError, 0)
+		if raw := cell("max_party_size"); raw != "" {
+			size, err := strconv.Atoi(raw)
+			if err != nil {
+				fieldErrors = append(fieldErrors, httpRequest.FieldError{
+					Field:   "max_party_size",
+					Rule:    "number",
+					Message: "max_party_size must be a whole number",
+				})
+			}
+			row.MaxPartySize = size
+		}
+
+		if err := httpRequest.ValidateStruct(row); err != nil {
+			verrs, ok := httpRequest.ValidationFieldErrors(row, err)
+			if !ok {
+				return ImportGuestsRequest{}, nil, err
+			}
+			fieldErrors = append(fieldErrors, verrs...)
+		}
+
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, ImportRowError{Row: i + 2, Fields: fieldErrors})
+			continue
+		}
+
+		rows = append(rows, customerService.GuestInput{
+			Name:         row.Name,
+			Phone:        row.Phone,
+			GroupName:    row.GroupName,
+			Seat:         row.Seat,
+			MaxPartySize: row.MaxPartySize,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		return ImportGuestsRequest{}, rowErrors, ErrGuestImportRowsFailed
+	}
+	if len(rows) == 0 {
+		return ImportGuestsRequest{}, nil, ErrEmptyImportFile
+	}
+
+	present := func(field string) bool {
+		_, ok := columns[field]
+		return ok
+	}
+	return ImportGuestsRequest{
+		Rows: rows,
+		Columns: customerService.GuestImportColumns{
+			Phone:        present("phone"),
+			GroupName:    present("group"),
+			Seat:         present("seat"),
+			MaxPartySize: present("max_party_size"),
+		},
+	}, nil, nil
+}
+
+type ExportGuestsRequest struct {
+	InvitationID string
+	Format       string
+}
+
+func NewExportGuestsRequest(c *gin.Context) (ExportGuestsRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return ExportGuestsRequest{}, err
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", spreadsheet.FormatCSV)))
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return ExportGuestsRequest{}, ErrInvalidExportFormat
+	}
+
+	return ExportGuestsRequest{InvitationID: idReq.ID, Format: format}, nil
+}
+
+func normalizeImportHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+	header = strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(header)
+	return header
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
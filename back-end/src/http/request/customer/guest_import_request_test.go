@@ -0,0 +1,131 @@
+package customerrequest
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+func importContext(t *testing.T, filename string, data []byte) *gin.Context {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/guests/import", &body)
+	c.Request.Header.Set("Content-Type", form.FormDataContentType())
+	return c
+}
+
+func xlsxFile(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := spreadsheet.NewWriter(spreadsheet.FormatXLSX, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewImportGuestsRequestColumns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		filename    string
+		data        []byte
+		wantRows    []customerService.GuestInput
+		wantColumns customerService.GuestImportColumns
+	}{
+		{
+			name:        "name and phone only",
+			filename:    "tamu.xlsx",
+			data:        xlsxFile(t, [][]string{{"Nama", "No HP"}, {"Pak Hendra", "0812"}}),
+			wantRows:    []customerService.GuestInput{{Name: "Pak Hendra", Phone: "0812"}},
+			wantColumns: customerService.GuestImportColumns{Phone: true},
+		},
+		{
+			name:     "exported file",
+			filename: "guests-20261018.xlsx",
+			data: xlsxFile(t, [][]string{
+				{"name", "phone", "group", "seat", "max_party_size", "token", "rsvp_status", "guests_count", "responded_at"},
+				{spreadsheet.EscapeFormula("=Pak Hendra"), spreadsheet.EscapeFormula("+62812"), "Keluarga", "A3", "2", "-abc", "pending", "", ""},
+			}),
+			wantRows:    []customerService.GuestInput{{Name: "=Pak Hendra", Phone: "+62812", GroupName: "Keluarga", Seat: "A3", MaxPartySize: 2}},
+			wantColumns: customerService.GuestImportColumns{Phone: true, GroupName: true, Seat: true, MaxPartySize: true},
+		},
+		{
+			name:        "semicolon csv with a blank row",
+			filename:    "tamu.csv",
+			data:        []byte("nama;meja\nPak Hendra;A3\n;\n"),
+			wantRows:    []customerService.GuestInput{{Name: "Pak Hendra", Seat: "A3"}},
+			wantColumns: customerService.GuestImportColumns{Seat: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, rowErrors, err := NewImportGuestsRequest(importContext(t, tt.filename, tt.data))
+			if err != nil {
+				t.Fatalf("NewImportGuestsRequest: %v (%+v)", err, rowErrors)
+			}
+			if !reflect.DeepEqual(req.Rows, tt.wantRows) {
+				t.Fatalf("Rows = %+v, want %+v", req.Rows, tt.wantRows)
+			}
+			if req.Columns != tt.wantColumns {
+				t.Fatalf("Columns = %+v, want %+v", req.Columns, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestNewImportGuestsRequestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		wantErr  error
+	}{
+		{name: "malformed xlsx", filename: "tamu.xlsx", data: []byte("PK\x03\x04not a zip"), wantErr: ErrInvalidImportFile},
+		{name: "header only", filename: "tamu.csv", data: []byte("name,phone\n"), wantErr: ErrEmptyImportFile},
+		{name: "no name column", filename: "tamu.csv", data: []byte("phone\n0812\n"), wantErr: ErrMissingNameColumn},
+		{name: "bad party size", filename: "tamu.csv", data: []byte("name,party_size\nPak Hendra,dua\n"), wantErr: ErrGuestImportRowsFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewImportGuestsRequest(importContext(t, tt.filename, tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewImportGuestsRequest err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package customerrequest
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+var ErrMissingGuestID = errors.New("missing guest id")
+
+type GuestIDRequest struct {
+	InvitationID string
+	GuestID      string
+}
+
+func NewGuestIDRequest(c *gin.Context) (GuestIDRequest, error) {
+	invitationID := strings.TrimSpace(c.Param("id"))
+	if invitationID == "" {
+		return GuestIDRequest{}, ErrMissingID
+	}
+	guestID := strings.TrimSpace(c.Param("guestId"))
+	if guestID == "" {
+		return GuestIDRequest{}, ErrMissingGuestID
+	}
+	return GuestIDRequest{InvitationID: invitationID, GuestID: guestID}, nil
+}
+
+type guestPayload struct {
+	Name         string `json:"name" binding:"required,max=200"`
+	Phone        string `json:"phone" binding:"max=32"`
+	GroupName    string `json:"group_name" binding:"max=100"`
+	Seat         string `json:"seat" binding:"max=50"`
+	MaxPartySize int    `json:"max_party_size" binding:"gte=0,lte=50"`
+}
+
+type GuestRequest struct {
+	Input customerService.GuestInput
+}
+
+func NewGuestRequest(c *gin.Context) (GuestRequest, any, error) {
+	var payload guestPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return GuestRequest{}, payload, err
+	}
+
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return GuestRequest{}, payload, err
+	}
+
+	return GuestRequest{
+		Input: customerService.GuestInput{
+			Name:         strings.TrimSpace(payload.Name),
+			Phone:        strings.TrimSpace(payload.Phone),
+			GroupName:    strings.TrimSpace(payload.GroupName),
+			Seat:         strings.TrimSpace(payload.Seat),
+			MaxPartySize: payload.MaxPartySize,
+		},
+	}, payload, nil
+}
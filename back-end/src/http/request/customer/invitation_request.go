@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 )
 
 var (
@@ -29,22 +31,24 @@ func NewInvitationIDRequest(c *gin.Context) (InvitationIDRequest, error) {
 }
 
 type invitationUpdatePayload struct {
-	Slug        string          `json:"slug"`
-	Title       string          `json:"title"`
-	EventDate   string          `json:"event_date"`
-	ThemeKey    string          `json:"theme_key"`
-	IsPublished *bool           `json:"is_published"`
-	Content     json.RawMessage `json:"content" binding:"required"`
+	Slug      string          `json:"slug"`
+	Title     string          `json:"title"`
+	EventDate string          `json:"event_date"`
+	ThemeKey  string          `json:"theme_key"`
+	Content   json.RawMessage `json:"content" binding:"required"`
+	// RequireWishApproval holds new wishes in the moderation queue when true.
+	RequireWishApproval *bool `json:"require_wish_approval"`
 }
 
 type UpdateInvitationRequest struct {
-	Slug              string
-	Title             string
-	ThemeKey          string
-	IsPublished       *bool
-	Content           json.RawMessage
-	EventDate         *time.Time
-	HasEventDateInput bool
+	Slug     string
+	Title    string
+	ThemeKey string
+	Content  json.RawMessage
+	// RequireWishApproval is nil when the setting is left unchanged.
+	RequireWishApproval *bool
+	EventDate           *time.Time
+	HasEventDateInput   bool
 }
 
 func NewUpdateInvitationRequest(c *gin.Context) (UpdateInvitationRequest, any, error) {
@@ -67,18 +71,68 @@ func NewUpdateInvitationRequest(c *gin.Context) (UpdateInvitationRequest, any, e
 		eventDate = &parsed
 	}
 
-	content := payload.Content
-	if len(bytes.TrimSpace(content)) == 0 {
-		content = []byte("{}")
+	rawContent := payload.Content
+	if len(bytes.TrimSpace(rawContent)) == 0 {
+		rawContent = []byte("{}")
+	}
+	if _, err := content.Validate(rawContent); err != nil {
+		return UpdateInvitationRequest{}, payload, err
 	}
 
 	return UpdateInvitationRequest{
-		Slug:              strings.TrimSpace(payload.Slug),
-		Title:             strings.TrimSpace(payload.Title),
-		ThemeKey:          strings.TrimSpace(payload.ThemeKey),
-		IsPublished:       payload.IsPublished,
-		Content:           content,
-		EventDate:         eventDate,
-		HasEventDateInput: hasEventDate,
+		Slug:                strings.TrimSpace(payload.Slug),
+		Title:               strings.TrimSpace(payload.Title),
+		ThemeKey:            strings.TrimSpace(payload.ThemeKey),
+		Content:             rawContent,
+		RequireWishApproval: payload.RequireWishApproval,
+		EventDate:           eventDate,
+		HasEventDateInput:   hasEventDate,
+	}, payload, nil
+}
+
+type invitationCreatePayload struct {
+	Slug      string          `json:"slug" binding:"max=100"`
+	Title     string          `json:"title" binding:"required,max=200"`
+	EventDate string          `json:"event_date"`
+	ThemeKey  string          `json:"theme_key"`
+	Content   json.RawMessage `json:"content"`
+}
+
+type CreateInvitationRequest struct {
+	Input customerService.CreateInvitationInput
+}
+
+func NewCreateInvitationRequest(c *gin.Context, customerID string) (CreateInvitationRequest, any, error) {
+	var payload invitationCreatePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return CreateInvitationRequest{}, payload, err
+	}
+
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return CreateInvitationRequest{}, payload, err
+	}
+
+	if _, err := content.Validate(payload.Content); err != nil {
+		return CreateInvitationRequest{}, payload, err
+	}
+
+	var eventDate *time.Time
+	if value := strings.TrimSpace(payload.EventDate); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return CreateInvitationRequest{}, payload, ErrInvalidEventDate
+		}
+		eventDate = &parsed
+	}
+
+	return CreateInvitationRequest{
+		Input: customerService.CreateInvitationInput{
+			CustomerID: customerID,
+			Slug:       strings.TrimSpace(payload.Slug),
+			Title:      strings.TrimSpace(payload.Title),
+			EventDate:  eventDate,
+			ThemeKey:   strings.TrimSpace(payload.ThemeKey),
+			Content:    payload.Content,
+		},
 	}, payload, nil
 }
@@ -0,0 +1,73 @@
+package customerrequest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+// MaxMediaUploadBytes bounds the upload body before the plan's own upload_max_mb is checked. It
+// must stay above the largest plan limit.
+const MaxMediaUploadBytes = 25 << 20
+
+var (
+	ErrMissingMediaFile  = errors.New("missing media file")
+	ErrInvalidMediaFile  = errors.New("invalid media file")
+	ErrMediaFileTooLarge = errors.New("media file too large")
+	ErrMissingMediaID    = errors.New("missing media id")
+)
+
+type UploadMediaRequest struct {
+	Upload customerService.MediaUpload
+}
+
+type MediaIDRequest struct {
+	ID string
+}
+
+func NewUploadMediaRequest(c *gin.Context) (UploadMediaRequest, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return UploadMediaRequest{}, ErrMediaFileTooLarge
+		}
+		return UploadMediaRequest{}, ErrMissingMediaFile
+	}
+	if fileHeader.Size > MaxMediaUploadBytes {
+		return UploadMediaRequest{}, ErrMediaFileTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return UploadMediaRequest{}, ErrInvalidMediaFile
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxMediaUploadBytes+1))
+	if err != nil {
+		return UploadMediaRequest{}, ErrInvalidMediaFile
+	}
+	if len(data) > MaxMediaUploadBytes {
+		return UploadMediaRequest{}, ErrMediaFileTooLarge
+	}
+
+	return UploadMediaRequest{
+		Upload: customerService.MediaUpload{
+			Filename: fileHeader.Filename,
+			Data:     data,
+		},
+	}, nil
+}
+
+func NewMediaIDRequest(c *gin.Context) (MediaIDRequest, error) {
+	id := strings.TrimSpace(c.Param("mediaId"))
+	if id == "" {
+		return MediaIDRequest{}, ErrMissingMediaID
+	}
+	return MediaIDRequest{ID: id}, nil
+}
@@ -1,6 +1,9 @@
 package customerrequest
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -47,3 +50,60 @@ func NewPaymentProgressRequest(c *gin.Context, customerID string) (PaymentProgre
 		},
 	}, nil
 }
+
+var (
+	ErrMissingPlanCode = errors.New("missing plan_code")
+	ErrMissingReceipt  = errors.New("missing receipt file")
+	ErrInvalidReceipt  = errors.New("invalid receipt file")
+	ErrReceiptTooLarge = errors.New("receipt file too large")
+)
+
+type CreateManualTransferRequest struct {
+	Input customerService.CreateManualTransferInput
+}
+
+// NewCreateManualTransferRequest reads a multipart form with the plan_code and the transfer
+// receipt image in "receipt".
+func NewCreateManualTransferRequest(c *gin.Context, customerID string) (CreateManualTransferRequest, error) {
+	fileHeader, err := c.FormFile("receipt")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return CreateManualTransferRequest{}, ErrReceiptTooLarge
+		}
+		return CreateManualTransferRequest{}, ErrMissingReceipt
+	}
+	if fileHeader.Size > customerService.MaxReceiptBytes {
+		return CreateManualTransferRequest{}, ErrReceiptTooLarge
+	}
+
+	planCode := strings.TrimSpace(c.PostForm("plan_code"))
+	if planCode == "" {
+		return CreateManualTransferRequest{}, ErrMissingPlanCode
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return CreateManualTransferRequest{}, ErrInvalidReceipt
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, customerService.MaxReceiptBytes+1))
+	if err != nil {
+		return CreateManualTransferRequest{}, ErrInvalidReceipt
+	}
+	if len(data) > customerService.MaxReceiptBytes {
+		return CreateManualTransferRequest{}, ErrReceiptTooLarge
+	}
+
+	return CreateManualTransferRequest{
+		Input: customerService.CreateManualTransferInput{
+			CustomerID: customerID,
+			PlanCode:   planCode,
+			Receipt: customerService.MediaUpload{
+				Filename: fileHeader.Filename,
+				Data:     data,
+			},
+		},
+	}, nil
+}
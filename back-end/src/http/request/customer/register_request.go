@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
 	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 )
@@ -45,6 +46,10 @@ func NewRegisterRequest(c *gin.Context) (RegisterRequest, any, error) {
 	payload.Title = strings.TrimSpace(payload.Title)
 	payload.ThemeKey = strings.TrimSpace(payload.ThemeKey)
 
+	if _, err := content.Validate(payload.Content); err != nil {
+		return RegisterRequest{}, payload, err
+	}
+
 	var eventDate *time.Time
 	if payload.EventDate != "" {
 		parsed, err := time.Parse("2006-01-02", payload.EventDate)
@@ -0,0 +1,68 @@
+package customerrequest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrInvalidRevision     = errors.New("invalid revision version")
+	ErrMissingDiffVersions = errors.New("from and to are required")
+)
+
+type RevisionRequest struct {
+	InvitationID string
+	Version      int
+}
+
+func NewRevisionRequest(c *gin.Context) (RevisionRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return RevisionRequest{}, err
+	}
+	version, err := parseRevisionVersion(c.Param("version"))
+	if err != nil {
+		return RevisionRequest{}, err
+	}
+	return RevisionRequest{InvitationID: idReq.ID, Version: version}, nil
+}
+
+type DiffRevisionsRequest struct {
+	InvitationID string
+	From         int
+	To           int
+}
+
+func NewDiffRevisionsRequest(c *gin.Context) (DiffRevisionsRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return DiffRevisionsRequest{}, err
+	}
+
+	fromRaw := strings.TrimSpace(c.Query("from"))
+	toRaw := strings.TrimSpace(c.Query("to"))
+	if fromRaw == "" || toRaw == "" {
+		return DiffRevisionsRequest{}, ErrMissingDiffVersions
+	}
+	from, err := parseRevisionVersion(fromRaw)
+	if err != nil {
+		return DiffRevisionsRequest{}, err
+	}
+	to, err := parseRevisionVersion(toRaw)
+	if err != nil {
+		return DiffRevisionsRequest{}, err
+	}
+
+	return DiffRevisionsRequest{InvitationID: idReq.ID, From: from, To: to}, nil
+}
+
+func parseRevisionVersion(raw string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidRevision
+	}
+	return version, nil
+}
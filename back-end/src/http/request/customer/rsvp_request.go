@@ -0,0 +1,27 @@
+package customerrequest
+
+import (
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+)
+
+const (
+	defaultRsvpListLimit = 50
+	maxRsvpListLimit     = 200
+)
+
+var (
+	ErrInvalidAttendance = httpRequest.ErrInvalidAttendance
+	ErrInvalidLimit      = httpRequest.ErrInvalidLimit
+	ErrInvalidOffset     = httpRequest.ErrInvalidOffset
+)
+
+type ListRsvpsRequest = httpRequest.ListRsvpsRequest
+
+func NewListRsvpsRequest(c *gin.Context) (ListRsvpsRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return ListRsvpsRequest{}, err
+	}
+	return httpRequest.NewListRsvpsRequest(c, idReq.ID, defaultRsvpListLimit, maxRsvpListLimit)
+}
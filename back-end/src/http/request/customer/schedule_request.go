@@ -0,0 +1,77 @@
+package customerrequest
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+var (
+	ErrInvalidScheduleTime  = errors.New("publish_at and archive_at must be RFC 3339 timestamps")
+	ErrConflictingArchiveAt = errors.New("use either archive_at or archive_weeks_after_event")
+)
+
+type schedulePayload struct {
+	PublishAt              string `json:"publish_at"`
+	ArchiveAt              string `json:"archive_at"`
+	ArchiveWeeksAfterEvent *int   `json:"archive_weeks_after_event" binding:"omitempty,min=0,max=52"`
+}
+
+type ScheduleRequest struct {
+	InvitationID string
+	Input        customerService.ScheduleInput
+}
+
+// NewScheduleRequest reads the full schedule; omitted or empty times clear that part of it.
+func NewScheduleRequest(c *gin.Context) (ScheduleRequest, any, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return ScheduleRequest{}, nil, err
+	}
+
+	var payload schedulePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return ScheduleRequest{}, payload, err
+	}
+
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return ScheduleRequest{}, payload, err
+	}
+
+	publishAt, err := parseScheduleTime(payload.PublishAt)
+	if err != nil {
+		return ScheduleRequest{}, payload, err
+	}
+	archiveAt, err := parseScheduleTime(payload.ArchiveAt)
+	if err != nil {
+		return ScheduleRequest{}, payload, err
+	}
+	if archiveAt != nil && payload.ArchiveWeeksAfterEvent != nil {
+		return ScheduleRequest{}, payload, ErrConflictingArchiveAt
+	}
+
+	return ScheduleRequest{
+		InvitationID: idReq.ID,
+		Input: customerService.ScheduleInput{
+			PublishAt:              publishAt,
+			ArchiveAt:              archiveAt,
+			ArchiveWeeksAfterEvent: payload.ArchiveWeeksAfterEvent,
+		},
+	}, payload, nil
+}
+
+func parseScheduleTime(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, ErrInvalidScheduleTime
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,125 @@
+package customerrequest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+const (
+	defaultWishListLimit = 50
+	maxWishListLimit     = 200
+)
+
+var (
+	ErrMissingWishID       = errors.New("missing wish id")
+	ErrInvalidWishStatus   = errors.New("invalid wish status")
+	ErrEmptyWishModeration = errors.New("status or pinned is required")
+)
+
+type WishIDRequest struct {
+	InvitationID string
+	WishID       string
+}
+
+func NewWishIDRequest(c *gin.Context) (WishIDRequest, error) {
+	invitationID := strings.TrimSpace(c.Param("id"))
+	if invitationID == "" {
+		return WishIDRequest{}, ErrMissingID
+	}
+	wishID := strings.TrimSpace(c.Param("wishId"))
+	if wishID == "" {
+		return WishIDRequest{}, ErrMissingWishID
+	}
+	return WishIDRequest{InvitationID: invitationID, WishID: wishID}, nil
+}
+
+type ListWishesRequest struct {
+	Filters repository.WishListFilters
+}
+
+func NewListWishesRequest(c *gin.Context) (ListWishesRequest, error) {
+	idReq, err := NewInvitationIDRequest(c)
+	if err != nil {
+		return ListWishesRequest{}, err
+	}
+
+	filters := repository.WishListFilters{
+		InvitationID: idReq.ID,
+		Status:       strings.TrimSpace(c.Query("status")),
+		Limit:        defaultWishListLimit,
+	}
+	if filters.Status != "" && !isWishStatus(filters.Status) {
+		return ListWishesRequest{}, ErrInvalidWishStatus
+	}
+
+	if value := strings.TrimSpace(c.Query("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return ListWishesRequest{}, ErrInvalidLimit
+		}
+		if limit > 0 {
+			filters.Limit = limit
+		}
+	}
+	if filters.Limit > maxWishListLimit {
+		filters.Limit = maxWishListLimit
+	}
+
+	if value := strings.TrimSpace(c.Query("offset")); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return ListWishesRequest{}, ErrInvalidOffset
+		}
+		if offset > 0 {
+			filters.Offset = offset
+		}
+	}
+
+	return ListWishesRequest{Filters: filters}, nil
+}
+
+type moderateWishPayload struct {
+	Status *string `json:"status" binding:"omitempty,oneof=pending approved hidden"`
+	Pinned *bool   `json:"pinned"`
+}
+
+type ModerateWishRequest struct {
+	Input repository.WishModerationInput
+}
+
+func NewModerateWishRequest(c *gin.Context) (ModerateWishRequest, any, error) {
+	var payload moderateWishPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		return ModerateWishRequest{}, payload, err
+	}
+
+	if err := httpRequest.ValidateStruct(payload); err != nil {
+		return ModerateWishRequest{}, payload, err
+	}
+
+	if payload.Status == nil && payload.Pinned == nil {
+		return ModerateWishRequest{}, payload, ErrEmptyWishModeration
+	}
+
+	return ModerateWishRequest{
+		Input: repository.WishModerationInput{
+			Status: payload.Status,
+			Pinned: payload.Pinned,
+		},
+	}, payload, nil
+}
+
+func isWishStatus(status string) bool {
+	switch status {
+	case model.WishStatusPending, model.WishStatusApproved, model.WishStatusHidden:
+		return true
+	default:
+		return false
+	}
+}
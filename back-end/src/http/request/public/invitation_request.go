@@ -10,7 +10,8 @@ import (
 var ErrMissingSlug = errors.New("missing invitation slug")
 
 type InvitationSlugRequest struct {
-	Slug string
+	Slug       string
+	GuestToken string
 }
 
 func NewInvitationSlugRequest(c *gin.Context) (InvitationSlugRequest, error) {
@@ -18,5 +19,5 @@ func NewInvitationSlugRequest(c *gin.Context) (InvitationSlugRequest, error) {
 	if slug == "" {
 		return InvitationSlugRequest{}, ErrMissingSlug
 	}
-	return InvitationSlugRequest{Slug: slug}, nil
+	return InvitationSlugRequest{Slug: slug, GuestToken: strings.TrimSpace(c.Query("to"))}, nil
 }
@@ -0,0 +1,33 @@
+package publicrequest
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+)
+
+// maxWebhookBodyBytes bounds payment notifications, which are a few kilobytes of JSON.
+const maxWebhookBodyBytes = 64 << 10
+
+type PaymentWebhookRequest struct {
+	Input customerService.PaymentWebhookInput
+}
+
+// NewPaymentWebhookRequest keeps the body as sent, since gateways sign or authenticate the
+// notification as a whole; the provider's gateway decodes it.
+func NewPaymentWebhookRequest(c *gin.Context, provider string) (PaymentWebhookRequest, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		return PaymentWebhookRequest{}, err
+	}
+
+	return PaymentWebhookRequest{
+		Input: customerService.PaymentWebhookInput{
+			Provider: provider,
+			Header:   c.Request.Header.Clone(),
+			Body:     body,
+		},
+	}, nil
+}
@@ -9,12 +9,17 @@ import (
 )
 
 type createRsvpPayload struct {
-	GuestName   string `json:"guest_name" binding:"required"`
+	GuestToken  string `json:"guest_token"`
+	DeviceID    string `json:"device_id" binding:"max=64"`
+	GuestName   string `json:"guest_name" binding:"required_without=GuestToken"`
 	Attendance  string `json:"attendance"`
 	GuestsCount int    `json:"guests_count"`
 	Message     string `json:"message"`
 }
 
+// RsvpDeviceCookie remembers the browser that answered so a resubmission updates the same RSVP.
+const RsvpDeviceCookie = "rsvp_device"
+
 type CreateRsvpRequest struct {
 	Input customerService.CreateRsvpInput
 }
@@ -29,10 +34,22 @@ func NewCreateRsvpRequest(c *gin.Context, customerID string, slug string) (Creat
 		return CreateRsvpRequest{}, payload, err
 	}
 
+	deviceID := strings.TrimSpace(payload.DeviceID)
+	if deviceID == "" {
+		if cookie, err := c.Cookie(RsvpDeviceCookie); err == nil {
+			deviceID = strings.TrimSpace(cookie)
+		}
+	}
+	if len(deviceID) > 64 {
+		deviceID = ""
+	}
+
 	return CreateRsvpRequest{
 		Input: customerService.CreateRsvpInput{
 			CustomerID:  strings.TrimSpace(customerID),
 			Slug:        strings.TrimSpace(slug),
+			GuestToken:  strings.TrimSpace(payload.GuestToken),
+			DeviceID:    deviceID,
 			GuestName:   strings.TrimSpace(payload.GuestName),
 			Attendance:  strings.TrimSpace(payload.Attendance),
 			GuestsCount: payload.GuestsCount,
@@ -66,7 +66,21 @@ func NewListWishesRequest(c *gin.Context, customerID string, slug string) ListWi
 		Input: customerService.ListWishesInput{
 			CustomerID: strings.TrimSpace(customerID),
 			Slug:       strings.TrimSpace(slug),
+			Cursor:     strings.TrimSpace(c.Query("cursor")),
 			Limit:      limit,
 		},
 	}
 }
+
+type StreamWishesRequest struct {
+	Input customerService.StreamWishesInput
+}
+
+func NewStreamWishesRequest(customerID string, slug string) StreamWishesRequest {
+	return StreamWishesRequest{
+		Input: customerService.StreamWishesInput{
+			CustomerID: strings.TrimSpace(customerID),
+			Slug:       strings.TrimSpace(slug),
+		},
+	}
+}
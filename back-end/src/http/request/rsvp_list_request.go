@@ -0,0 +1,69 @@
+package request
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/spreadsheet"
+)
+
+var (
+	ErrInvalidAttendance   = errors.New("invalid attendance")
+	ErrInvalidLimit        = errors.New("invalid limit")
+	ErrInvalidOffset       = errors.New("invalid offset")
+	ErrInvalidExportFormat = errors.New("invalid export format")
+)
+
+type ListRsvpsRequest struct {
+	Filters repository.RsvpListFilters
+	Format  string
+}
+
+// NewListRsvpsRequest reads the filters, paging and export format of an RSVP list shared by the
+// customer and admin routes. A missing or non-positive limit falls back to defaultLimit.
+func NewListRsvpsRequest(c *gin.Context, invitationID string, defaultLimit, maxLimit int) (ListRsvpsRequest, error) {
+	filters := repository.RsvpListFilters{
+		InvitationID: invitationID,
+		Attendance:   strings.TrimSpace(c.Query("attendance")),
+		Query:        strings.TrimSpace(c.Query("q")),
+		Limit:        defaultLimit,
+	}
+	switch filters.Attendance {
+	case "", "attending", "not_attending":
+	default:
+		return ListRsvpsRequest{}, ErrInvalidAttendance
+	}
+
+	if value := strings.TrimSpace(c.Query("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return ListRsvpsRequest{}, ErrInvalidLimit
+		}
+		if limit > 0 {
+			filters.Limit = limit
+		}
+	}
+	if filters.Limit > maxLimit {
+		filters.Limit = maxLimit
+	}
+
+	if value := strings.TrimSpace(c.Query("offset")); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return ListRsvpsRequest{}, ErrInvalidOffset
+		}
+		if offset > 0 {
+			filters.Offset = offset
+		}
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", spreadsheet.FormatCSV)))
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return ListRsvpsRequest{}, ErrInvalidExportFormat
+	}
+
+	return ListRsvpsRequest{Filters: filters, Format: format}, nil
+}
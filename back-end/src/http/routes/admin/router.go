@@ -20,9 +20,19 @@ func RegisterRoutes(group *gin.RouterGroup) {
 	group.GET("/me", adminHandlers.MeHandler)
 	group.GET("/customers", adminHandlers.ListCustomersHandler)
 	group.GET("/payments", adminHandlers.ListPaymentsHandler)
+	group.GET("/payments/:id/proof", adminHandlers.PaymentProofHandler)
+	group.GET("/payments/:id/events", adminHandlers.PaymentEventsHandler)
+	group.POST("/payments/:id/approve", adminHandlers.ApprovePaymentHandler)
+	group.POST("/payments/:id/reject", adminHandlers.RejectPaymentHandler)
+	group.POST("/payments/:id/refund", adminHandlers.RefundPaymentHandler)
+	group.GET("/metrics/tenant-cache", adminHandlers.TenantCacheMetricsHandler)
 	group.GET("/invitations", adminHandlers.ListInvitationsHandler)
 	group.POST("/invitations", adminHandlers.CreateInvitationHandler)
+	group.GET("/invitations/schedules", adminHandlers.ListScheduledInvitationsHandler)
 	group.GET("/invitations/:id", adminHandlers.GetInvitationHandler)
 	group.PATCH("/invitations/:id", adminHandlers.UpdateInvitationHandler)
 	group.DELETE("/invitations/:id", adminHandlers.DeleteInvitationHandler)
+	group.GET("/invitations/:id/rsvps", adminHandlers.ListRsvpsHandler)
+	group.GET("/invitations/:id/rsvps/summary", adminHandlers.RsvpSummaryHandler)
+	group.GET("/invitations/:id/rsvps/export", adminHandlers.ExportRsvpsHandler)
 }
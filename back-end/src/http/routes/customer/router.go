@@ -7,6 +7,7 @@ import (
 	customerHandlers "github.com/proxima-labs/wedding-invitation-back-end/src/http/handlers/customer"
 	middleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware"
 	customerMiddleware "github.com/proxima-labs/wedding-invitation-back-end/src/http/middleware/customer"
+	customerRequest "github.com/proxima-labs/wedding-invitation-back-end/src/http/request/customer"
 )
 
 func RegisterRoutes(group *gin.RouterGroup) {
@@ -17,9 +18,40 @@ func RegisterRoutes(group *gin.RouterGroup) {
 
 	auth := group.Group("/")
 	auth.Use(customerMiddleware.Auth(customerHandlers.JwtConfig()))
+	auth.GET("/invitations", customerHandlers.ListInvitationsHandler)
+	auth.POST("/invitations", customerHandlers.CreateInvitationHandler)
 	auth.GET("/invitations/:id", customerHandlers.GetInvitationHandler)
 	auth.PATCH("/invitations/:id", customerHandlers.UpdateInvitationHandler)
+	auth.GET("/invitations/:id/preview", customerHandlers.PreviewInvitationHandler)
+	auth.POST("/invitations/:id/publish", customerHandlers.PublishInvitationHandler)
+	auth.POST("/invitations/:id/unpublish", customerHandlers.UnpublishInvitationHandler)
+	auth.PUT("/invitations/:id/schedule", customerHandlers.ScheduleInvitationHandler)
+	auth.GET("/invitations/:id/revisions", customerHandlers.ListRevisionsHandler)
+	auth.GET("/invitations/:id/revisions/diff", customerHandlers.DiffRevisionsHandler)
+	auth.GET("/invitations/:id/revisions/:version", customerHandlers.GetRevisionHandler)
+	auth.POST("/invitations/:id/revisions/:version/restore", customerHandlers.RestoreRevisionHandler)
+	auth.GET("/invitations/:id/guests", customerHandlers.ListGuestsHandler)
+	auth.POST("/invitations/:id/guests", customerHandlers.CreateGuestHandler)
+	auth.POST("/invitations/:id/guests/import", customerHandlers.ImportGuestsHandler)
+	auth.GET("/invitations/:id/guests/export", customerHandlers.ExportGuestsHandler)
+	auth.PATCH("/invitations/:id/guests/:guestId", customerHandlers.UpdateGuestHandler)
+	auth.DELETE("/invitations/:id/guests/:guestId", customerHandlers.DeleteGuestHandler)
+	auth.GET("/invitations/:id/rsvps", customerHandlers.ListRsvpsHandler)
+	auth.GET("/invitations/:id/rsvps/summary", customerHandlers.RsvpSummaryHandler)
+	auth.GET("/invitations/:id/rsvps/export", customerHandlers.ExportRsvpsHandler)
+	auth.GET("/invitations/:id/wishes", customerHandlers.ListWishesHandler)
+	auth.PATCH("/invitations/:id/wishes/:wishId", customerHandlers.ModerateWishHandler)
+	auth.DELETE("/invitations/:id/wishes/:wishId", customerHandlers.DeleteWishHandler)
+	auth.GET("/media", customerHandlers.ListMediaHandler)
+	auth.POST("/media", middleware.MaxBodySize(customerRequest.MaxMediaUploadBytes+1<<20), customerHandlers.UploadMediaHandler)
+	auth.DELETE("/media/:mediaId", customerHandlers.DeleteMediaHandler)
+	auth.GET("/domains", customerHandlers.ListCustomDomainsHandler)
+	auth.POST("/domains", customerHandlers.RegisterCustomDomainHandler)
+	auth.POST("/domains/:domainId/verify", customerHandlers.VerifyCustomDomainHandler)
+	auth.DELETE("/domains/:domainId", customerHandlers.DeleteCustomDomainHandler)
 	auth.POST("/payments", customerHandlers.CreatePaymentHandler)
 	auth.GET("/payments/progress", customerHandlers.PaymentProgressHandler)
+	auth.GET("/payments/manual-transfer", customerHandlers.ManualTransferInstructionsHandler)
+	auth.POST("/payments/manual-transfer", customerHandlers.CreateManualTransferHandler)
 	auth.GET("/my-plan", customerHandlers.GetMyPlanHandler)
 }
@@ -9,6 +9,7 @@ import (
 func RegisterRoutes(group *gin.RouterGroup) {
 	group.GET("/plans", publicHandlers.ListPlansHandler)
 	group.POST("/payments/midtrans/webhook", publicHandlers.MidtransWebhookHandler)
+	group.POST("/payments/xendit/webhook", publicHandlers.XenditWebhookHandler)
 
 	publicWithTenant := group.Group("")
 	publicWithTenant.Use(publicHandlers.TenantMiddleware())
@@ -17,6 +18,7 @@ func RegisterRoutes(group *gin.RouterGroup) {
 	publicWithTenant.POST("/invitations/:slug/rsvps", publicHandlers.CreateRsvpHandler)
 	publicWithTenant.GET("/invitations/:slug/wishes", publicHandlers.ListWishesHandler)
 	publicWithTenant.POST("/invitations/:slug/wishes", publicHandlers.CreateWishHandler)
+	publicWithTenant.GET("/invitations/:slug/wishes/stream", publicHandlers.StreamWishesHandler)
 
 	demoWithTenant := group.Group("/:owner")
 	demoWithTenant.Use(publicHandlers.TenantMiddleware())
@@ -25,4 +27,5 @@ func RegisterRoutes(group *gin.RouterGroup) {
 	demoWithTenant.POST("/invitations/:slug/rsvps", publicHandlers.CreateRsvpHandler)
 	demoWithTenant.GET("/invitations/:slug/wishes", publicHandlers.ListWishesHandler)
 	demoWithTenant.POST("/invitations/:slug/wishes", publicHandlers.CreateWishHandler)
+	demoWithTenant.GET("/invitations/:slug/wishes/stream", publicHandlers.StreamWishesHandler)
 }
@@ -29,7 +29,7 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 	router.Use(middleware.HTTPSRedirect())
-	router.Use(middleware.MaxBodySize(5 << 20)) // 5 MB
+	router.Use(middleware.MaxBodySize(5<<20, "/api/v1/customer/media")) // 5 MB; uploads set their own limit
 	router.Use(middleware.RateLimit(100, time.Minute))
 	router.Use(middleware.CORS())
 
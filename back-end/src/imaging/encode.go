@@ -0,0 +1,35 @@
+//go:build !webp
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+)
+
+const jpegQuality = 82
+
+var (
+	jpegFormat = format{ContentType: "image/jpeg", Ext: ".jpg"}
+	pngFormat  = format{ContentType: "image/png", Ext: ".png"}
+)
+
+// encodeImage writes opaque variants as JPEG and keeps transparency in PNG. Both encoders are
+// pure Go, so the default build needs no C toolchain; see encode_webp.go for WebP variants.
+func encodeImage(img *image.RGBA, opaque bool) ([]byte, format, error) {
+	var buf bytes.Buffer
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, format{}, err
+		}
+		return buf.Bytes(), jpegFormat, nil
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, format{}, err
+	}
+	return buf.Bytes(), pngFormat, nil
+}
@@ -0,0 +1,39 @@
+//go:build !webp
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestEncodeFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		ext         string
+		format      string
+	}{
+		{name: "opaque photo", data: jpegFixture(t), contentType: "image/jpeg", ext: ".jpg", format: "jpeg"},
+		{name: "transparent photo", data: pngFixture(t), contentType: "image/png", ext: ".png", format: "png"},
+		{name: "webp upload", data: webpFixture(t), contentType: "image/jpeg", ext: ".jpg", format: "jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			variant := result.Variants[0]
+			if variant.ContentType != tt.contentType || variant.Ext != tt.ext {
+				t.Fatalf("variant is %s (%s), want %s (%s)", variant.ContentType, variant.Ext, tt.contentType, tt.ext)
+			}
+			if _, format, err := image.DecodeConfig(bytes.NewReader(variant.Data)); err != nil || format != tt.format {
+				t.Fatalf("variant decodes as %q (%v), want %s", format, err, tt.format)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+//go:build webp
+
+package imaging
+
+import (
+	"image"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+)
+
+const webpQuality = 80
+
+var webpFormat = format{ContentType: "image/webp", Ext: ".webp"}
+
+// encodeImage writes lossy WebP variants through libwebp, keeping the alpha channel when the
+// photo has transparency. It needs cgo and is only built with the webp tag.
+func encodeImage(img *image.RGBA, opaque bool) ([]byte, format, error) {
+	if opaque {
+		data, err := webp.EncodeRGB(img, webpQuality)
+		return data, webpFormat, err
+	}
+
+	// libwebp expects straight alpha while image.RGBA is premultiplied, so the pixels are
+	// converted and handed over in an RGBA header.
+	straight := image.NewNRGBA(img.Bounds())
+	draw.Draw(straight, straight.Bounds(), img, img.Bounds().Min, draw.Src)
+	data, err := webp.EncodeRGBA(&image.RGBA{Pix: straight.Pix, Stride: straight.Stride, Rect: straight.Rect}, webpQuality)
+	return data, webpFormat, err
+}
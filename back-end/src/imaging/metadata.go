@@ -0,0 +1,262 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var ErrMalformed = errors.New("malformed image")
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngMagic   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// StripMetadata removes EXIF (including GPS position), XMP and text metadata from a JPEG, PNG
+// or WebP file without re-encoding it. A JPEG keeps a minimal EXIF block with only its
+// orientation so phone photos are still displayed upright. Other formats are returned as is.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return data, nil
+	}
+}
+
+// Orientation returns the EXIF orientation (1-8) of a JPEG, or 1 when it has none.
+func Orientation(data []byte) int {
+	orientation := 1
+	_ = walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			if o := exifOrientation(segment[len(exifHeader):]); o != 0 {
+				orientation = o
+			}
+			return false
+		}
+		return true
+	})
+	return orientation
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	orientation := 0
+	insertAt := len(out)
+
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, ErrMalformed
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		if marker == 0xD9 {
+			out = append(out, data[i:]...)
+			break
+		}
+		if marker >= 0xD0 && marker <= 0xD7 || marker == 0x01 {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrMalformed
+		}
+		segment := data[i+4 : end]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(segment, exifHeader):
+			if o := exifOrientation(segment[len(exifHeader):]); o != 0 {
+				orientation = o
+			}
+		case marker == 0xE1 && bytes.HasPrefix(segment, xmpHeader):
+		case marker == 0xED, marker == 0xFE:
+			// Photoshop IPTC block and free-text comments.
+		case marker == 0xDA:
+			// Start of scan: the compressed image data follows up to EOI.
+			out = append(out, data[i:]...)
+			return withOrientation(out, insertAt, orientation), nil
+		default:
+			out = append(out, data[i:end]...)
+			if marker == 0xE0 && insertAt == 2 {
+				insertAt = len(out)
+			}
+		}
+		i = end
+	}
+
+	return withOrientation(out, insertAt, orientation), nil
+}
+
+// withOrientation inserts an APP1 segment holding only the orientation tag.
+func withOrientation(jpeg []byte, at, orientation int) []byte {
+	if orientation <= 1 || orientation > 8 {
+		return jpeg
+	}
+
+	payload := make([]byte, 0, 32)
+	payload = append(payload, exifHeader...)
+	payload = append(payload, 'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08)
+	payload = append(payload, 0x00, 0x01)             // one IFD entry
+	payload = append(payload, 0x01, 0x12, 0x00, 0x03) // Orientation, SHORT
+	payload = append(payload, 0x00, 0x00, 0x00, 0x01) // count
+	payload = append(payload, 0x00, byte(orientation), 0x00, 0x00)
+	payload = append(payload, 0x00, 0x00, 0x00, 0x00) // no next IFD
+
+	segment := make([]byte, 0, len(payload)+4)
+	segment = append(segment, 0xFF, 0xE1)
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := make([]byte, 0, len(jpeg)+len(segment))
+	out = append(out, jpeg[:at]...)
+	out = append(out, segment...)
+	return append(out, jpeg[at:]...)
+}
+
+func walkJPEG(data []byte, visit func(marker byte, segment []byte) bool) error {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return ErrMalformed
+	}
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return ErrMalformed
+		}
+		if !visit(marker, data[i+4:end]) {
+			return nil
+		}
+		i = end
+	}
+	return nil
+}
+
+// exifOrientation reads tag 0x0112 from IFD0 of a TIFF structure, returning 0 if absent.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := order.Uint32(tiff[4:8])
+	if ifd < 8 || uint64(ifd) > uint64(len(tiff)-2) {
+		return 0
+	}
+	offset := int(ifd)
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngMagic) {
+		return nil, ErrMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngMagic...)
+	i := len(pngMagic)
+	for i < len(data) {
+		if i+12 > len(data) {
+			return nil, ErrMalformed
+		}
+		length := binary.BigEndian.Uint32(data[i : i+4])
+		if uint64(length) > uint64(len(data)-i-12) {
+			return nil, ErrMalformed
+		}
+		end := i + 12 + int(length)
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[i:end]...)
+		}
+		if string(data[i+4:i+8]) == "IEND" {
+			break
+		}
+		i = end
+	}
+	return out, nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformed
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	i := 12
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, ErrMalformed
+		}
+		fourCC := string(data[i : i+4])
+		size := binary.LittleEndian.Uint32(data[i+4 : i+8])
+		if uint64(size) > uint64(len(data)-i-8) {
+			return nil, ErrMalformed
+		}
+		end := i + 8 + int(size)
+		// Odd chunks are padded to an even size; some encoders drop the pad after the last one.
+		if size%2 == 1 && end < len(data) {
+			end++
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,317 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+var (
+	// gpsLatitude is 6°10'30" as three TIFF rationals, the position a phone records.
+	gpsLatitude = []byte{0, 0, 0, 6, 0, 0, 0, 1, 0, 0, 0, 10, 0, 0, 0, 1, 0, 0, 0, 30, 0, 0, 0, 1}
+	cameraMake  = []byte("Pixel 8")
+	xmpPacket   = []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:Description exif:GPSLatitude="6,10.5S"/></x:xmpmeta>`)
+	iccProfile  = []byte("sRGB IEC61966-2.1 display profile")
+)
+
+// exifTIFF builds a big-endian TIFF block whose IFD0 holds a camera make, the orientation and
+// a pointer to a GPS IFD with a latitude.
+func exifTIFF(orientation int) []byte {
+	tiff := []byte{'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08}
+	entry := func(tag, typ uint16, count, value uint32) {
+		tiff = binary.BigEndian.AppendUint16(tiff, tag)
+		tiff = binary.BigEndian.AppendUint16(tiff, typ)
+		tiff = binary.BigEndian.AppendUint32(tiff, count)
+		tiff = binary.BigEndian.AppendUint32(tiff, value)
+	}
+
+	// IFD0 at 8, its make string at 50, the GPS IFD at 58 and its rationals at 88.
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	entry(0x010F, 2, uint32(len(cameraMake)+1), 50)
+	entry(0x0112, 3, 1, uint32(orientation)<<16)
+	entry(0x8825, 4, 1, 58)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0)
+	tiff = append(append(tiff, cameraMake...), 0)
+
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	entry(0x0001, 2, 2, uint32('S')<<24)
+	entry(0x0002, 5, 3, 88)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0)
+	return append(tiff, gpsLatitude...)
+}
+
+func testImage(alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 24, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 24; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 10), uint8(y * 15), 120, alpha})
+		}
+	}
+	return img
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegFixture is a phone photo: EXIF with GPS, XMP, an ICC profile and a comment before the image.
+func jpegFixture(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(255), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	out := append([]byte(nil), encoded[:2]...)
+	out = append(out, jpegSegment(0xE1, append(append([]byte(nil), exifHeader...), exifTIFF(6)...))...)
+	out = append(out, jpegSegment(0xE1, append(append([]byte(nil), xmpHeader...), xmpPacket...))...)
+	out = append(out, jpegSegment(0xE2, append([]byte("ICC_PROFILE\x00\x01\x01"), iccProfile...))...)
+	out = append(out, jpegSegment(0xFE, []byte("taken at home"))...)
+	return append(out, encoded[2:]...)
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngFixture is a screenshot with an ICC profile, EXIF with GPS, XMP, a text comment and a timestamp.
+func pngFixture(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(200)); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	encoded := buf.Bytes()
+	ihdrEnd := len(pngMagic) + 25
+
+	out := append([]byte(nil), encoded[:ihdrEnd]...)
+	out = append(out, pngChunk("iCCP", append([]byte("sRGB\x00\x00"), iccProfile...))...)
+	out = append(out, pngChunk("eXIf", exifTIFF(1))...)
+	out = append(out, pngChunk("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), xmpPacket...))...)
+	out = append(out, pngChunk("tEXt", []byte("Comment\x00taken at home"))...)
+	out = append(out, pngChunk("tIME", []byte{0x07, 0xEA, 10, 18, 9, 30, 0})...)
+	return append(out, encoded[ihdrEnd:]...)
+}
+
+func webpChunk(fourCC string, data []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// webpFixture wraps the lossy photo in testdata in an extended file with ICC, EXIF and XMP chunks.
+func webpFixture(t *testing.T) []byte {
+	t.Helper()
+	plain, err := os.ReadFile("testdata/photo.webp")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	vp8x := []byte{0x20 | 0x08 | 0x04, 0, 0, 0}
+	vp8x = append(vp8x, 23, 0, 0, 15, 0, 0) // canvas width and height minus one
+	body := []byte("WEBP")
+	body = append(body, webpChunk("VP8X", vp8x)...)
+	body = append(body, webpChunk("ICCP", iccProfile)...)
+	body = append(body, plain[12:]...)
+	body = append(body, webpChunk("EXIF", exifTIFF(1))...)
+	body = append(body, webpChunk("XMP ", xmpPacket)...)
+
+	out := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	return append(out, body...)
+}
+
+func TestStripMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		// orientation is the EXIF orientation a stripped JPEG keeps.
+		orientation int
+	}{
+		{name: "jpeg", contentType: "image/jpeg", data: jpegFixture(t), orientation: 6},
+		{name: "png", contentType: "image/png", data: pngFixture(t)},
+		{name: "webp", contentType: "image/webp", data: webpFixture(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.Contains(tt.data, gpsLatitude) {
+				t.Fatal("fixture carries no GPS position")
+			}
+
+			out, err := StripMetadata(tt.contentType, tt.data)
+			if err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+			for what, leaked := range map[string][]byte{
+				"GPS position": gpsLatitude,
+				"camera make":  cameraMake,
+				"XMP packet":   xmpPacket,
+				"comment":      []byte("taken at home"),
+			} {
+				if bytes.Contains(out, leaked) {
+					t.Errorf("stripped %s still contains the %s", tt.name, what)
+				}
+			}
+			// Color profiles are not personal and keep the photo's colors right.
+			if !bytes.Contains(out, iccProfile) {
+				t.Error("ICC profile was dropped")
+			}
+
+			img, _, err := image.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("decode stripped %s: %v", tt.name, err)
+			}
+			if size := img.Bounds().Size(); size != image.Pt(24, 16) {
+				t.Fatalf("stripped %s is %v, want 24x16", tt.name, size)
+			}
+			if tt.orientation != 0 {
+				if got := Orientation(out); got != tt.orientation {
+					t.Fatalf("Orientation = %d, want %d", got, tt.orientation)
+				}
+			}
+		})
+	}
+}
+
+func TestStripWebPHeader(t *testing.T) {
+	out, err := StripMetadata("image/webp", webpFixture(t))
+	if err != nil {
+		t.Fatalf("StripMetadata: %v", err)
+	}
+	if size := binary.LittleEndian.Uint32(out[4:8]); int(size) != len(out)-8 {
+		t.Fatalf("RIFF size = %d, want %d", size, len(out)-8)
+	}
+	if flags := out[20]; flags != 0x20 {
+		t.Fatalf("VP8X flags = %#x, want only the ICC flag", flags)
+	}
+}
+
+func TestStripMetadataOtherFormats(t *testing.T) {
+	gif := []byte("GIF89a")
+	out, err := StripMetadata("image/gif", gif)
+	if err != nil || !bytes.Equal(out, gif) {
+		t.Fatalf("StripMetadata(gif) = %q, %v", out, err)
+	}
+}
+
+// Uploads are untrusted, so every prefix of a valid file is stripped without a panic.
+func TestStripMetadataTruncated(t *testing.T) {
+	fixtures := map[string][]byte{
+		"image/jpeg": jpegFixture(t),
+		"image/png":  pngFixture(t),
+		"image/webp": webpFixture(t),
+	}
+	for contentType, data := range fixtures {
+		for n := 0; n < len(data); n++ {
+			out, err := StripMetadata(contentType, data[:n])
+			if err == nil && bytes.Contains(out, gpsLatitude) {
+				t.Fatalf("%s cut at %d kept the GPS position", contentType, n)
+			}
+			Orientation(data[:n])
+		}
+	}
+}
+
+func TestStripMetadataMalformed(t *testing.T) {
+	webp := webpFixture(t)
+	png := pngFixture(t)
+	jpeg := jpegFixture(t)
+
+	withUint32 := func(data []byte, at int, order binary.ByteOrder, value uint32) []byte {
+		out := append([]byte(nil), data...)
+		order.PutUint32(out[at:], value)
+		return out
+	}
+	withUint16 := func(data []byte, at int, value uint16) []byte {
+		out := append([]byte(nil), data...)
+		binary.BigEndian.PutUint16(out[at:], value)
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		wantErr     bool
+	}{
+		{name: "webp chunk larger than any file", contentType: "image/webp", data: withUint32(webp, 16, binary.LittleEndian, 0xFFFFFFFF), wantErr: true},
+		{name: "webp chunk one byte past the end", contentType: "image/webp", data: withUint32(webp[:30], 16, binary.LittleEndian, 15), wantErr: true},
+		{name: "webp chunk header cut short", contentType: "image/webp", data: webp[:len(webp)-2], wantErr: true},
+		{name: "webp without the last pad byte", contentType: "image/webp", data: append(append([]byte(nil), webp[:12]...), webpChunk("EXIF", []byte{1, 2, 3})[:11]...)},
+		{name: "webp without a riff header", contentType: "image/webp", data: webp[8:], wantErr: true},
+		{name: "png chunk larger than any file", contentType: "image/png", data: withUint32(png, 8, binary.BigEndian, 0xFFFFFFFF), wantErr: true},
+		{name: "png chunk length past the end", contentType: "image/png", data: withUint32(png, 33, binary.BigEndian, uint32(len(png))), wantErr: true},
+		{name: "png without a signature", contentType: "image/png", data: png[8:], wantErr: true},
+		{name: "jpeg segment shorter than its length", contentType: "image/jpeg", data: withUint16(jpeg, 4, 1), wantErr: true},
+		{name: "jpeg segment past the end", contentType: "image/jpeg", data: withUint16(jpeg, 4, 0xFFFF), wantErr: true},
+		{name: "jpeg without start of image", contentType: "image/jpeg", data: jpeg[2:], wantErr: true},
+		{name: "jpeg without a marker", contentType: "image/jpeg", data: []byte{0xFF, 0xD8, 0x00, 0x00, 0x00, 0x00}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := StripMetadata(tt.contentType, tt.data)
+			if tt.wantErr && !errors.Is(err, ErrMalformed) {
+				t.Fatalf("StripMetadata err = %v, want ErrMalformed", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("StripMetadata: %v", err)
+			}
+		})
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	exif := func(tiff []byte) []byte {
+		return append([]byte{0xFF, 0xD8}, jpegSegment(0xE1, append(append([]byte(nil), exifHeader...), tiff...))...)
+	}
+	little := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "big endian", data: exif(exifTIFF(6)), want: 6},
+		{name: "little endian", data: exif(little), want: 8},
+		{name: "no exif", data: []byte{0xFF, 0xD8, 0xFF, 0xD9}, want: 1},
+		{name: "out of range", data: exif(exifTIFF(9)), want: 1},
+		{name: "unknown byte order", data: exif(append([]byte("XX"), exifTIFF(6)[2:]...)), want: 1},
+		{name: "ifd offset past the end", data: exif(withIFDOffset(exifTIFF(6), 0xFFFFFFF0)), want: 1},
+		{name: "ifd offset inside the header", data: exif(withIFDOffset(exifTIFF(6), 4)), want: 1},
+		{name: "more entries than bytes", data: exif(append([]byte(nil), exifTIFF(6)[:8]...)), want: 1},
+		{name: "entry count past the end", data: exif(append(exifTIFF(6)[:8:8], 0xFF, 0xFF, 0x01)), want: 1},
+		{name: "not a jpeg", data: []byte("GIF89a"), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Orientation(tt.data); got != tt.want {
+				t.Fatalf("Orientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func withIFDOffset(tiff []byte, offset uint32) []byte {
+	out := append([]byte(nil), tiff...)
+	binary.BigEndian.PutUint32(out[4:8], offset)
+	return out
+}
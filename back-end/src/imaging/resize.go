@@ -0,0 +1,171 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels rejects decompression bombs before a full decode allocates memory for them.
+const MaxPixels = 60_000_000
+
+var ErrTooManyPixels = errors.New("image dimensions too large")
+
+// Size is a responsive variant, bounded by its width.
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Sizes are the variants generated for every uploaded photo, smallest first.
+var Sizes = []Size{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 800},
+	{Name: "large", Width: 1600},
+}
+
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// format is the encoding of a variant.
+type format struct {
+	ContentType string
+	Ext         string
+}
+
+type Result struct {
+	// Width and Height are the upright dimensions of the original.
+	Width    int
+	Height   int
+	Variants []Variant
+}
+
+// Process decodes a photo and renders its resized variants, upright according to EXIF
+// orientation and without any metadata. Opaque photos become JPEG variants and photos with
+// transparency PNG, or lossy WebP in both cases when built with the webp tag. Sizes wider than the photo are skipped, except the thumbnail which
+// is always produced.
+func Process(data []byte) (Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("decode config: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return Result{}, ErrMalformed
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Result{}, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("decode: %w", err)
+	}
+
+	orientation := Orientation(data)
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if orientation >= 5 {
+		width, height = height, width
+	}
+
+	opaque := isOpaque(src)
+	result := Result{Width: width, Height: height}
+	for i, size := range Sizes {
+		if i > 0 && size.Width >= width {
+			continue
+		}
+		targetWidth := min(size.Width, width)
+		targetHeight := max(1, height*targetWidth/width)
+
+		// Scale in the stored orientation first so rotating works on the small image.
+		scaleWidth, scaleHeight := targetWidth, targetHeight
+		if orientation >= 5 {
+			scaleWidth, scaleHeight = targetHeight, targetWidth
+		}
+		scaled := image.NewRGBA(image.Rect(0, 0, scaleWidth, scaleHeight))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, src.Bounds(), draw.Src, nil)
+		upright := orient(scaled, orientation)
+
+		variant, err := encode(size.Name, upright, opaque)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Variants = append(result.Variants, variant)
+	}
+	return result, nil
+}
+
+func encode(name string, img *image.RGBA, opaque bool) (Variant, error) {
+	data, format, err := encodeImage(img, opaque)
+	if err != nil {
+		return Variant{}, fmt.Errorf("encode %s: %w", name, err)
+	}
+	return Variant{
+		Name:        name,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		ContentType: format.ContentType,
+		Ext:         format.Ext,
+		Data:        data,
+	}, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// orient applies an EXIF orientation so the result is displayed upright.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	outW, outH := w, h
+	if orientation >= 5 {
+		outW, outH = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, outW, outH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			si := img.PixOffset(x, y)
+			di := out.PixOffset(dx, dy)
+			copy(out.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+	return out
+}
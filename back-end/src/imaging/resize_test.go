@@ -0,0 +1,104 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"testing"
+)
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		width  int
+		height int
+	}{
+		// Stored 24x16 and rotated 90° clockwise by its EXIF orientation.
+		{name: "jpeg taken upright", data: jpegFixture(t), width: 16, height: 24},
+		{name: "png with transparency", data: pngFixture(t), width: 24, height: 16},
+		{name: "webp", data: webpFixture(t), width: 24, height: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if result.Width != tt.width || result.Height != tt.height {
+				t.Fatalf("Process = %dx%d, want %dx%d", result.Width, result.Height, tt.width, tt.height)
+			}
+			// Every size is wider than the photo, so only the thumbnail is rendered.
+			if len(result.Variants) != 1 || result.Variants[0].Name != "thumbnail" {
+				t.Fatalf("Variants = %+v, want the thumbnail only", result.Variants)
+			}
+
+			variant := result.Variants[0]
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(variant.Data))
+			if err != nil {
+				t.Fatalf("decode variant: %v", err)
+			}
+			if cfg.Width != tt.width || cfg.Height != tt.height || variant.Width != tt.width || variant.Height != tt.height {
+				t.Fatalf("variant is %dx%d (%dx%d stored), want %dx%d", variant.Width, variant.Height, cfg.Width, cfg.Height, tt.width, tt.height)
+			}
+			if bytes.Contains(variant.Data, gpsLatitude) {
+				t.Fatal("variant kept the GPS position")
+			}
+		})
+	}
+}
+
+func TestProcessRejectsDecompressionBombs(t *testing.T) {
+	data := pngFixture(t)
+	// Claim 10000x10000 pixels in the header of a tiny file.
+	ihdr := data[len(pngMagic)+8 : len(pngMagic)+21]
+	binary.BigEndian.PutUint32(ihdr[0:4], 10000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 10000)
+	binary.BigEndian.PutUint32(data[len(pngMagic)+21:], crc32.ChecksumIEEE(data[len(pngMagic)+4:len(pngMagic)+21]))
+
+	if _, err := Process(data); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("Process err = %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestProcessRejectsGarbage(t *testing.T) {
+	if _, err := Process([]byte("not an image")); err == nil {
+		t.Fatal("Process accepted garbage")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image with a red pixel on the left and a blue one on the right.
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Pix = []byte{255, 0, 0, 255, 0, 0, 255, 255}
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		// red is where the left pixel ends up.
+		red image.Point
+	}{
+		{1, image.Pt(2, 1), image.Pt(0, 0)},
+		{2, image.Pt(2, 1), image.Pt(1, 0)},
+		{3, image.Pt(2, 1), image.Pt(1, 0)},
+		{4, image.Pt(2, 1), image.Pt(0, 0)},
+		{5, image.Pt(1, 2), image.Pt(0, 0)},
+		{6, image.Pt(1, 2), image.Pt(0, 0)},
+		{7, image.Pt(1, 2), image.Pt(0, 1)},
+		{8, image.Pt(1, 2), image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		out := orient(img, tt.orientation)
+		if out.Bounds().Size() != tt.size {
+			t.Errorf("orientation %d: size %v, want %v", tt.orientation, out.Bounds().Size(), tt.size)
+			continue
+		}
+		if r, _, _, _ := out.At(tt.red.X, tt.red.Y).RGBA(); r != 0xFFFF {
+			t.Errorf("orientation %d: red pixel is not at %v", tt.orientation, tt.red)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// AcmeCacheEntry is one item of the TLS certificate cache: an issued certificate, the ACME
+// account key or a pending HTTP-01 challenge token.
+type AcmeCacheEntry struct {
+	Key       string    `gorm:"column:key;primaryKey"`
+	Data      []byte    `gorm:"column:data"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (AcmeCacheEntry) TableName() string {
+	return "acme_cache"
+}
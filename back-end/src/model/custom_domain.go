@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	CustomDomainStatusPending  = "pending"
+	CustomDomainStatusVerified = "verified"
+	CustomDomainStatusFailed   = "failed"
+)
+
+// CustomDomain is a hostname a customer wants their invitations served on. It is only used for
+// routing once a DNS TXT record proved the customer controls it.
+type CustomDomain struct {
+	ID                string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID        string     `gorm:"column:customer_id"`
+	Hostname          string     `gorm:"column:hostname"`
+	VerificationToken string     `gorm:"column:verification_token"`
+	Status            string     `gorm:"column:status"`
+	VerifiedAt        *time.Time `gorm:"column:verified_at"`
+	LastCheckedAt     *time.Time `gorm:"column:last_checked_at"`
+	LastError         string     `gorm:"column:last_error"`
+	CreatedAt         time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (CustomDomain) TableName() string {
+	return "custom_domains"
+}
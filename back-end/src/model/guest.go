@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type Guest struct {
+	ID           string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	InvitationID string    `gorm:"column:invitation_id"`
+	Name         string    `gorm:"column:name"`
+	Phone        string    `gorm:"column:phone"`
+	GroupName    string    `gorm:"column:group_name"`
+	Seat         string    `gorm:"column:seat"`
+	MaxPartySize int       `gorm:"column:max_party_size"`
+	Token        string    `gorm:"column:token"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Guest) TableName() string {
+	return "guests"
+}
@@ -11,9 +11,17 @@ type Invitation struct {
 	EventDate   *time.Time `gorm:"column:event_date"`
 	ThemeKey    string     `gorm:"column:theme_key"`
 	IsPublished bool       `gorm:"column:is_published"`
-	Content     []byte     `gorm:"column:content;type:jsonb"`
-	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	// Content is the draft; PublishedContent is the snapshot served to guests.
+	Content          []byte     `gorm:"column:content;type:jsonb"`
+	PublishedContent []byte     `gorm:"column:published_content;type:jsonb"`
+	PublishedAt      *time.Time `gorm:"column:published_at"`
+	// PublishAt and ArchiveAt schedule the next publish or unpublish; they are cleared once applied.
+	PublishAt *time.Time `gorm:"column:publish_at"`
+	ArchiveAt *time.Time `gorm:"column:archive_at"`
+	// RequireWishApproval keeps new wishes pending until the customer approves them.
+	RequireWishApproval bool      `gorm:"column:require_wish_approval"`
+	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (Invitation) TableName() string {
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	RevisionAuthorCustomer = "customer"
+	RevisionAuthorAdmin    = "admin"
+	RevisionAuthorSystem   = "system"
+)
+
+// InvitationRevision is a saved version of an invitation's content.
+type InvitationRevision struct {
+	ID           string `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	InvitationID string `gorm:"column:invitation_id"`
+	Version      int    `gorm:"column:version"`
+	Content      []byte `gorm:"column:content;type:jsonb"`
+	AuthorType   string `gorm:"column:author_type"`
+	AuthorID     string `gorm:"column:author_id"`
+	// RestoredFrom is the version this revision was rolled back to, if any.
+	RestoredFrom *int      `gorm:"column:restored_from"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (InvitationRevision) TableName() string {
+	return "invitation_revisions"
+}
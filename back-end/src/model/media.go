@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	MediaKindImage = "image"
+	MediaKindAudio = "audio"
+)
+
+const (
+	MediaStatusPending    = "pending"
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
+// Media is a file a customer uploaded for use in their invitation content.
+type Media struct {
+	ID           string `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID   string `gorm:"column:customer_id"`
+	Kind         string `gorm:"column:kind"`
+	ContentType  string `gorm:"column:content_type"`
+	SizeBytes    int64  `gorm:"column:size_bytes"`
+	StorageKey   string `gorm:"column:storage_key"`
+	URL          string `gorm:"column:url"`
+	OriginalName string `gorm:"column:original_name"`
+	// Width, Height and Variants are filled in once a photo has been processed.
+	Width               *int       `gorm:"column:width"`
+	Height              *int       `gorm:"column:height"`
+	Variants            []byte     `gorm:"column:variants;type:jsonb"`
+	ProcessingStatus    string     `gorm:"column:processing_status"`
+	ProcessingError     string     `gorm:"column:processing_error"`
+	ProcessingStartedAt *time.Time `gorm:"column:processing_started_at"`
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+// MediaVariant is one resized rendition of a photo, stored in Media.Variants.
+type MediaVariant struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	StorageKey  string `json:"storage_key"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+func (Media) TableName() string {
+	return "media"
+}
@@ -2,17 +2,41 @@ package model
 
 import "time"
 
+// Manual bank transfers have no gateway: the customer uploads a receipt and the payment waits
+// for an admin to approve or reject it.
+const (
+	PaymentProviderManualTransfer = "manual_transfer"
+	PaymentStatusAwaitingReview   = "awaiting_review"
+)
+
 type Payment struct {
-	ID             string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
-	CustomerID     string     `gorm:"column:customer_id"`
-	PlanID         string     `gorm:"column:plan_id"`
-	Amount         int        `gorm:"column:amount"`
-	Currency       string     `gorm:"column:currency"`
-	ProofOfPayment string     `gorm:"column:proof_of_payment"`
-	Status         string     `gorm:"column:status"`
-	PaidAt         *time.Time `gorm:"column:paid_at"`
-	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	ID                    string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	CustomerID            string     `gorm:"column:customer_id"`
+	PlanID                string     `gorm:"column:plan_id"`
+	Amount                int        `gorm:"column:amount"`
+	Currency              string     `gorm:"column:currency"`
+	ProofOfPayment        string     `gorm:"column:proof_of_payment"`
+	Status                string     `gorm:"column:status"`
+	Provider              string     `gorm:"column:provider"`
+	ProviderOrderID       *string    `gorm:"column:provider_order_id"`
+	ProviderTransactionID string     `gorm:"column:provider_transaction_id"`
+	PaymentType           string     `gorm:"column:payment_type"`
+	RawStatus             string     `gorm:"column:raw_status"`
+	RedirectURL           string     `gorm:"column:redirect_url"`
+	ReviewedBy            *string    `gorm:"column:reviewed_by"`
+	ReviewedAt            *time.Time `gorm:"column:reviewed_at"`
+	ReviewNote            string     `gorm:"column:review_note"`
+	PaidAt                *time.Time `gorm:"column:paid_at"`
+	CreatedAt             time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt             time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// OrderID returns the gateway order id, or "" for payments that never went through a gateway.
+func (p Payment) OrderID() string {
+	if p.ProviderOrderID == nil {
+		return ""
+	}
+	return *p.ProviderOrderID
 }
 
 func (Payment) TableName() string {
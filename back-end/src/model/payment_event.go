@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Where a payment status came from.
+const (
+	PaymentEventSourceWebhook = "webhook"
+	PaymentEventSourcePoll    = "poll"
+	PaymentEventSourceReview  = "review"
+	PaymentEventSourceRefund  = "refund"
+)
+
+// PaymentEvent records a status notification for a payment. Applied is false when the payment
+// state machine refused it, e.g. a late pending after the payment was paid.
+type PaymentEvent struct {
+	ID         string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	PaymentID  string    `gorm:"column:payment_id"`
+	Provider   string    `gorm:"column:provider"`
+	Source     string    `gorm:"column:source"`
+	ActorID    *string   `gorm:"column:actor_id"`
+	EventKey   *string   `gorm:"column:event_key"`
+	RawStatus  string    `gorm:"column:raw_status"`
+	Status     string    `gorm:"column:status"`
+	FromStatus string    `gorm:"column:from_status"`
+	Applied    bool      `gorm:"column:applied"`
+	Payload    string    `gorm:"column:payload"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
@@ -5,11 +5,14 @@ import "time"
 type RSVP struct {
 	ID           string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
 	InvitationID string    `gorm:"column:invitation_id"`
+	GuestID      *string   `gorm:"column:guest_id"`
+	IdentityKey  string    `gorm:"column:identity_key"`
 	GuestName    string    `gorm:"column:guest_name"`
 	Attendance   string    `gorm:"column:attendance"`
 	GuestsCount  int       `gorm:"column:guests_count"`
 	Message      string    `gorm:"column:message"`
 	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (RSVP) TableName() string {
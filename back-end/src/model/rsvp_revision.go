@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// RSVPRevision keeps a previous answer each time a guest resubmits their RSVP.
+type RSVPRevision struct {
+	ID          string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	RsvpID      string    `gorm:"column:rsvp_id"`
+	GuestName   string    `gorm:"column:guest_name"`
+	Attendance  string    `gorm:"column:attendance"`
+	GuestsCount int       `gorm:"column:guests_count"`
+	Message     string    `gorm:"column:message"`
+	AnsweredAt  time.Time `gorm:"column:answered_at"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RSVPRevision) TableName() string {
+	return "rsvp_revisions"
+}
@@ -2,11 +2,21 @@ package model
 
 import "time"
 
+const (
+	WishStatusPending  = "pending"
+	WishStatusApproved = "approved"
+	WishStatusHidden   = "hidden"
+)
+
 type Wish struct {
 	ID           string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
 	InvitationID string    `gorm:"column:invitation_id"`
+	RsvpID       *string   `gorm:"column:rsvp_id"`
 	GuestName    string    `gorm:"column:guest_name"`
 	Message      string    `gorm:"column:message"`
+	Status       string    `gorm:"column:status"`
+	Pinned       bool      `gorm:"column:pinned"`
+	FlagReason   string    `gorm:"column:flag_reason"`
 	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
 }
 
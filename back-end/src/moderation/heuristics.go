@@ -0,0 +1,166 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|id|co|xyz|io|me|ly|site|online|info|biz|link|top|club)\b(?:/\S*)?`)
+
+// linkObfuscation undoes the usual ways of hiding a link from filters, such as "hxxp://" and
+// "example[.]com" or "example (dot) com".
+var linkObfuscation = regexp.MustCompile(`(?i)\s*(?:\[\s*(?:\.|dot)\s*\]|\(\s*(?:\.|dot)\s*\)|\{\s*(?:\.|dot)\s*\})\s*|\bhxxp(s?)://`)
+
+func deobfuscateLinks(text string) string {
+	return linkObfuscation.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasSuffix(match, "://") {
+			return strings.Replace(strings.ToLower(match), "hxxp", "http", 1)
+		}
+		return "."
+	})
+}
+
+// LinkFilter flags text that contains links, including obfuscated ones, and rejects text that is
+// mostly links.
+type LinkFilter struct {
+	// RejectAt is the number of links from which the text is rejected. Zero means 3.
+	RejectAt int
+}
+
+func (f LinkFilter) Check(_ context.Context, input Input) (Result, error) {
+	rejectAt := f.RejectAt
+	if rejectAt <= 0 {
+		rejectAt = 3
+	}
+
+	count := len(linkPattern.FindAllStringIndex(deobfuscateLinks(input.GuestName+" "+input.Message), -1))
+	switch {
+	case count >= rejectAt:
+		return verdict(Reject, "links"), nil
+	case count > 0:
+		return verdict(Flag, "link"), nil
+	default:
+		return verdict(Allow, ""), nil
+	}
+}
+
+// RepeatFilter catches keyboard mashing such as "aaaaaaaaaa" or "!!!!!!!!!!". Emoji are ignored
+// because guests often repeat them on purpose.
+type RepeatFilter struct{}
+
+const (
+	repeatFlagRun   = 8
+	repeatRejectRun = 20
+	lowVarietyMin   = 20
+)
+
+func (RepeatFilter) Check(_ context.Context, input Input) (Result, error) {
+	longest := 0
+	run := 0
+	var last rune
+	letters := 0
+	distinct := make(map[rune]struct{})
+
+	for _, r := range strings.ToLower(input.Message) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsPunct(r) {
+			run = 0
+			last = 0
+			continue
+		}
+		if r == last {
+			run++
+		} else {
+			run = 1
+			last = r
+		}
+		if run > longest {
+			longest = run
+		}
+		if unicode.IsLetter(r) {
+			letters++
+			distinct[r] = struct{}{}
+		}
+	}
+
+	switch {
+	case longest >= repeatRejectRun:
+		return verdict(Reject, "repeated characters"), nil
+	case longest >= repeatFlagRun:
+		return verdict(Flag, "repeated characters"), nil
+	case letters >= lowVarietyMin && len(distinct) <= 3:
+		return verdict(Flag, "repeated characters"), nil
+	default:
+		return verdict(Allow, ""), nil
+	}
+}
+
+// MessageHistory looks up earlier messages of an invitation. Messages are compared by the key
+// MessageKey returns for them.
+type MessageHistory interface {
+	CountRecentMessages(ctx context.Context, invitationID, key string, since time.Time) (int64, error)
+	HasMessageFrom(ctx context.Context, invitationID, guestName, key string) (bool, error)
+}
+
+// MessageKey reduces a message to its lowercase letters and digits, so near-duplicates that only
+// differ in case, spacing, punctuation or emoji share a key.
+func MessageKey(message string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, message)
+}
+
+// DuplicateFilter rejects a guest posting the same wish twice and flags bursts of one message
+// posted under different names. Messages without letters or digits are never duplicates.
+type DuplicateFilter struct {
+	History MessageHistory
+	// Window and BurstAt default to 10 minutes and 3 messages.
+	Window  time.Duration
+	BurstAt int64
+	Now     func() time.Time
+}
+
+func (f DuplicateFilter) Check(ctx context.Context, input Input) (Result, error) {
+	key := MessageKey(input.Message)
+	if f.History == nil || key == "" {
+		return verdict(Allow, ""), nil
+	}
+
+	// A resubmitted RSVP replaces its own wish, so only wishes are checked per guest.
+	if input.Source == SourceWish {
+		exists, err := f.History.HasMessageFrom(ctx, input.InvitationID, strings.TrimSpace(input.GuestName), key)
+		if err != nil {
+			return Result{}, err
+		}
+		if exists {
+			return verdict(Reject, "duplicate message"), nil
+		}
+	}
+
+	window := f.Window
+	if window <= 0 {
+		window = 10 * time.Minute
+	}
+	burstAt := f.BurstAt
+	if burstAt <= 0 {
+		burstAt = 3
+	}
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+
+	count, err := f.History.CountRecentMessages(ctx, input.InvitationID, key, now().Add(-window))
+	if err != nil {
+		return Result{}, err
+	}
+	if count >= burstAt {
+		return verdict(Flag, "repeated message"), nil
+	}
+	return verdict(Allow, ""), nil
+}
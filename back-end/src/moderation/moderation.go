@@ -0,0 +1,86 @@
+// Package moderation screens guest-submitted text such as wishes and RSVP messages.
+package moderation
+
+import (
+	"context"
+	"strings"
+)
+
+// Decision is the outcome of a check. Higher values are more severe.
+type Decision int
+
+const (
+	Allow Decision = iota
+	// Flag keeps the entry but hides it until the couple reviews it.
+	Flag
+	// Reject refuses the entry outright.
+	Reject
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Flag:
+		return "flag"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+const (
+	SourceWish = "wish"
+	SourceRSVP = "rsvp"
+)
+
+// Input is the text to screen along with where it was submitted.
+type Input struct {
+	InvitationID string
+	Source       string
+	GuestName    string
+	Message      string
+}
+
+// Result carries the decision and the reasons of every check that did not allow the input.
+type Result struct {
+	Decision Decision
+	Reasons  []string
+}
+
+// Reason joins the reasons into a single line for storage.
+func (r Result) Reason() string {
+	return strings.Join(r.Reasons, ", ")
+}
+
+// Checker is a single moderation rule.
+type Checker interface {
+	Check(ctx context.Context, input Input) (Result, error)
+}
+
+// Pipeline runs every checker and keeps the most severe decision.
+type Pipeline []Checker
+
+func (p Pipeline) Check(ctx context.Context, input Input) (Result, error) {
+	combined := Result{Decision: Allow}
+	for _, checker := range p {
+		if checker == nil {
+			continue
+		}
+		result, err := checker.Check(ctx, input)
+		if err != nil {
+			return Result{}, err
+		}
+		if result.Decision > combined.Decision {
+			combined.Decision = result.Decision
+		}
+		combined.Reasons = append(combined.Reasons, result.Reasons...)
+	}
+	return combined, nil
+}
+
+func verdict(decision Decision, reason string) Result {
+	if decision == Allow {
+		return Result{Decision: Allow}
+	}
+	return Result{Decision: decision, Reasons: []string{reason}}
+}
@@ -0,0 +1,301 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWordList(t *testing.T) {
+	list, err := NewWordList()
+	if err != nil {
+		t.Fatalf("NewWordList: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		guest   string
+		message string
+		want    Decision
+	}{
+		{name: "clean wish", guest: "Pak Hendra", message: "Selamat menempuh hidup baru, semoga sakinah!", want: Allow},
+		{name: "everyday word left off the list", message: "Lucu banget anjing kalian di foto prewed", want: Allow},
+		{name: "listed word inside another word", message: "Selamat, bangsawan kecil!", want: Allow},
+		{name: "flagged word", message: "dasar bangsat", want: Flag},
+		{name: "upper case and punctuation", message: "BANGSAT!!!", want: Flag},
+		{name: "rejected word", message: "kontol", want: Reject},
+		{name: "leetspeak", message: "k0nt0l", want: Reject},
+		{name: "leetspeak with symbols", message: "b@ng$4t", want: Flag},
+		{name: "repeated letters", message: "bangsaaaaat", want: Flag},
+		{name: "leetspeak does not turn o into u", message: "f00000ck", want: Allow},
+		{name: "repeated letters in a rejected word", message: "fuuuuuck", want: Reject},
+		{name: "phrase split by spacing", message: "main judi   online yuk", want: Reject},
+		{name: "phrase split by punctuation", message: "slot-gacor.", want: Reject},
+		{name: "guest name", guest: "Si Tolol", message: "Selamat ya", want: Flag},
+		{name: "most severe entry wins", message: "bangsat kontol", want: Reject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := list.Check(context.Background(), Input{GuestName: tt.guest, Message: tt.message})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Decision != tt.want {
+				t.Fatalf("Check(%q) = %v, want %v", tt.message, result.Decision, tt.want)
+			}
+			if tt.want != Allow && result.Reason() != "profanity" {
+				t.Fatalf("Reason = %q, want profanity", result.Reason())
+			}
+		})
+	}
+}
+
+func TestWordListExtraFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extra.txt")
+	if err := os.WriteFile(path, []byte("# local additions\n\n!mantan terindah\nbucin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := NewWordList(" ", path)
+	if err != nil {
+		t.Fatalf("NewWordList: %v", err)
+	}
+	for message, want := range map[string]Decision{
+		"untuk mantan terindah": Reject,
+		"dasar bucinnn":         Flag,
+		"# local additions":     Allow,
+		"bangsat":               Flag,
+	} {
+		result, _ := list.Check(context.Background(), Input{Message: message})
+		if result.Decision != want {
+			t.Errorf("Check(%q) = %v, want %v", message, result.Decision, want)
+		}
+	}
+
+	if _, err := NewWordList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("NewWordList accepted a missing file")
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		guest   string
+		message string
+		want    Decision
+	}{
+		{name: "no link", message: "Selamat ya, semoga langgeng. Sampai jumpa di Jl. Merdeka no. 5", want: Allow},
+		{name: "sentence without spaces after dots", message: "Selamat.Semoga bahagia.Amin", want: Allow},
+		{name: "url", message: "cek https://promo.example.com/hadiah", want: Flag},
+		{name: "www", message: "mampir ke www.tokoku.id", want: Flag},
+		{name: "bare domain", message: "diskon di tokoku.site", want: Flag},
+		{name: "upper case", message: "KLIK HTTP://EVIL.XYZ", want: Flag},
+		{name: "link in the name", guest: "bit.ly/promo", message: "Selamat", want: Flag},
+		{name: "hxxp", message: "buka hxxps://evil.example", want: Flag},
+		{name: "bracketed dot", message: "mampir ke tokoku[.]com ya", want: Flag},
+		{name: "parenthesized dot word", message: "mampir ke tokoku (dot) com ya", want: Flag},
+		{name: "braced dot", message: "tokoku{.}xyz", want: Flag},
+		{name: "three links", message: "a.com b.net c.org", want: Reject},
+		{name: "three obfuscated links", message: "a[.]com b(.)net hxxp://c.org", want: Reject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := LinkFilter{}.Check(context.Background(), Input{GuestName: tt.guest, Message: tt.message})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Decision != tt.want {
+				t.Fatalf("Check(%q) = %v, want %v", tt.message, result.Decision, tt.want)
+			}
+		})
+	}
+
+	if result, _ := (LinkFilter{RejectAt: 1}).Check(context.Background(), Input{Message: "a.com"}); result.Decision != Reject {
+		t.Fatalf("RejectAt 1 = %v, want reject", result.Decision)
+	}
+}
+
+func TestRepeatFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Decision
+	}{
+		{name: "normal wish", message: "Selamaaat ya kaliaaan!!", want: Allow},
+		{name: "seven in a row", message: "yeyyyyyyy", want: Allow},
+		{name: "eight in a row", message: "aaaaaaaa", want: Flag},
+		{name: "punctuation", message: "Selamat!!!!!!!!!!", want: Flag},
+		{name: "case does not hide a run", message: "aAaAaAaAaA", want: Flag},
+		{name: "twenty in a row", message: strings.Repeat("z", 20), want: Reject},
+		{name: "spaces break a run but not low variety", message: strings.Repeat("aaaa ", 10), want: Flag},
+		{name: "emoji are ignored", message: strings.Repeat("🎉", 30) + " selamat", want: Allow},
+		{name: "low variety", message: strings.Repeat("wk", 12), want: Flag},
+		{name: "short low variety", message: "wkwkwk", want: Allow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RepeatFilter{}.Check(context.Background(), Input{Message: tt.message})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Decision != tt.want {
+				t.Fatalf("Check(%q) = %v, want %v", tt.message, result.Decision, tt.want)
+			}
+		})
+	}
+}
+
+// fakeHistory keeps earlier wishes in memory and compares them the way the wish repository does.
+type fakeHistory struct {
+	wishes []fakeWish
+	err    error
+}
+
+type fakeWish struct {
+	guest   string
+	message string
+	at      time.Time
+}
+
+func (h *fakeHistory) CountRecentMessages(_ context.Context, _ string, key string, since time.Time) (int64, error) {
+	var count int64
+	for _, wish := range h.wishes {
+		if MessageKey(wish.message) == key && !wish.at.Before(since) {
+			count++
+		}
+	}
+	return count, h.err
+}
+
+func (h *fakeHistory) HasMessageFrom(_ context.Context, _ string, guest, key string) (bool, error) {
+	for _, wish := range h.wishes {
+		if strings.EqualFold(strings.TrimSpace(wish.guest), guest) && MessageKey(wish.message) == key {
+			return true, h.err
+		}
+	}
+	return false, h.err
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	history := &fakeHistory{wishes: []fakeWish{
+		{guest: "Pak Hendra", message: "Selamat menempuh hidup baru!", at: now.Add(-time.Hour)},
+		{guest: "Spammer 1", message: "Promo slot", at: now.Add(-5 * time.Minute)},
+		{guest: "Spammer 2", message: "promo SLOT!!", at: now.Add(-4 * time.Minute)},
+		{guest: "Spammer 3", message: "Promo slot", at: now.Add(-20 * time.Minute)},
+		{guest: "Bu Sari", message: "🎉🎉", at: now.Add(-time.Minute)},
+	}}
+	filter := DuplicateFilter{History: history, BurstAt: 2, Now: func() time.Time { return now }}
+
+	tests := []struct {
+		name   string
+		input  Input
+		want   Decision
+		reason string
+	}{
+		{
+			name:  "new wish",
+			input: Input{Source: SourceWish, GuestName: "Pak Hendra", Message: "Semoga sakinah"},
+			want:  Allow,
+		},
+		{
+			name:   "same wish again",
+			input:  Input{Source: SourceWish, GuestName: "Pak Hendra", Message: "Selamat menempuh hidup baru!"},
+			want:   Reject,
+			reason: "duplicate message",
+		},
+		{
+			name:   "near-duplicate wish",
+			input:  Input{Source: SourceWish, GuestName: " pak hendra ", Message: "selamat menempuh  hidup baru 🎉"},
+			want:   Reject,
+			reason: "duplicate message",
+		},
+		{
+			name:  "same words from another guest",
+			input: Input{Source: SourceWish, GuestName: "Bu Sari", Message: "Selamat menempuh hidup baru!"},
+			want:  Allow,
+		},
+		{
+			name:  "resubmitted rsvp message",
+			input: Input{Source: SourceRSVP, GuestName: "Pak Hendra", Message: "Selamat menempuh hidup baru!"},
+			want:  Allow,
+		},
+		{
+			name:   "burst under different names",
+			input:  Input{Source: SourceWish, GuestName: "Spammer 4", Message: "Promo... slot"},
+			want:   Flag,
+			reason: "repeated message",
+		},
+		{
+			name:  "emoji only",
+			input: Input{Source: SourceWish, GuestName: "Bu Sari", Message: "🎉🎉"},
+			want:  Allow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := filter.Check(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Decision != tt.want || result.Reason() != tt.reason {
+				t.Fatalf("Check = %v (%q), want %v (%q)", result.Decision, result.Reason(), tt.want, tt.reason)
+			}
+		})
+	}
+
+	// Only two of the three copies were posted within the default ten minutes.
+	defaults := DuplicateFilter{History: history, Now: func() time.Time { return now }}
+	if result, _ := defaults.Check(context.Background(), Input{Source: SourceRSVP, Message: "promo slot"}); result.Decision != Allow {
+		t.Fatalf("default window = %v, want allow", result.Decision)
+	}
+
+	failing := DuplicateFilter{History: &fakeHistory{err: errors.New("connection reset")}}
+	if _, err := failing.Check(context.Background(), Input{Source: SourceWish, Message: "Selamat"}); err == nil {
+		t.Fatal("Check hid a history error")
+	}
+}
+
+func TestMessageKey(t *testing.T) {
+	tests := map[string]string{
+		"Selamat ya!":           "selamatya",
+		"  SELAMAT   ya 🎉🎉 ":    "selamatya",
+		"Selamat, ya...":        "selamatya",
+		"Semoga langgeng 2026!": "semogalanggeng2026",
+		"🎉🎉":                    "",
+	}
+	for message, want := range tests {
+		if got := MessageKey(message); got != want {
+			t.Errorf("MessageKey(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	list, err := NewWordList()
+	if err != nil {
+		t.Fatalf("NewWordList: %v", err)
+	}
+	pipeline := Pipeline{list, nil, LinkFilter{}, RepeatFilter{}}
+
+	result, err := pipeline.Check(context.Background(), Input{Message: "bangsat cek tokoku[.]com"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Decision != Flag || result.Reason() != "profanity, link" {
+		t.Fatalf("Check = %v (%q), want flag (profanity, link)", result.Decision, result.Reason())
+	}
+
+	result, _ = pipeline.Check(context.Background(), Input{Message: "ngentot a.com"})
+	if result.Decision != Reject {
+		t.Fatalf("Check = %v, want the most severe decision", result.Decision)
+	}
+}
@@ -0,0 +1,135 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+//go:embed wordlists/*.txt
+var builtinLists embed.FS
+
+// WordList flags or rejects text containing listed words or phrases. Each line of a list holds
+// one entry; lines starting with "#" are comments and entries prefixed with "!" are rejected
+// instead of flagged.
+type WordList struct {
+	entries map[string]Decision
+}
+
+// NewWordList returns the built-in Indonesian and English lists merged with the given files.
+func NewWordList(paths ...string) (*WordList, error) {
+	list := &WordList{entries: make(map[string]Decision)}
+
+	builtin, err := builtinLists.ReadDir("wordlists")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range builtin {
+		f, err := builtinLists.Open("wordlists/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		err = list.Load(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", entry.Name(), err)
+		}
+	}
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = list.Load(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", path, err)
+		}
+	}
+	return list, nil
+}
+
+// Load adds the entries read from r.
+func (w *WordList) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		decision := Flag
+		if strings.HasPrefix(line, "!") {
+			decision = Reject
+			line = strings.TrimPrefix(line, "!")
+		}
+
+		phrase := strings.Join(tokenize(line), " ")
+		if phrase == "" {
+			continue
+		}
+		for _, key := range []string{phrase, squeeze(phrase)} {
+			if decision > w.entries[key] {
+				w.entries[key] = decision
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func (w *WordList) Check(_ context.Context, input Input) (Result, error) {
+	tokens := tokenize(input.GuestName + " " + input.Message)
+	squeezed := make([]string, len(tokens))
+	for i, token := range tokens {
+		squeezed[i] = squeeze(token)
+	}
+
+	decision := Allow
+	for _, words := range [][]string{tokens, squeezed} {
+		// Look for phrases of up to three words.
+		for size := 1; size <= 3; size++ {
+			for i := 0; i+size <= len(words); i++ {
+				if found := w.entries[strings.Join(words[i:i+size], " ")]; found > decision {
+					decision = found
+				}
+			}
+		}
+	}
+	return verdict(decision, "profanity"), nil
+}
+
+var leetReplacer = strings.NewReplacer(
+	"4", "a", "@", "a", "3", "e", "1", "i", "0", "o", "5", "s", "$", "s",
+)
+
+// tokenize lowercases the text, undoes common character substitutions and splits it into words.
+func tokenize(text string) []string {
+	text = leetReplacer.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// squeeze collapses repeated letters so "bangsaaat" matches "bangsat".
+func squeeze(word string) string {
+	var b strings.Builder
+	var last rune
+	for i, r := range word {
+		if i > 0 && r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
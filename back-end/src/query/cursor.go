@@ -0,0 +1,54 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// WishCursor marks the last wish of a page in (pinned, created_at, id) order.
+type WishCursor struct {
+	Pinned    bool      `json:"p"`
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+func (c WishCursor) valid() bool {
+	return !c.CreatedAt.IsZero() && c.ID != ""
+}
+
+// EncodeCursor returns an opaque, URL-safe token for a cursor value.
+func EncodeCursor(cursor any) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor reverses EncodeCursor. Tokens that EncodeCursor could not have produced, such as
+// ones with unknown or missing fields, are rejected with ErrInvalidCursor.
+func DecodeCursor(token string, cursor any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cursor); err != nil {
+		return ErrInvalidCursor
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return ErrInvalidCursor
+	}
+	if v, ok := cursor.(interface{ valid() bool }); ok && !v.valid() {
+		return ErrInvalidCursor
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWishCursorRoundTrip(t *testing.T) {
+	cursors := []WishCursor{
+		{Pinned: true, CreatedAt: time.Date(2026, 10, 1, 9, 30, 0, 123456789, time.UTC), ID: "2e4f6a8c-1b3d-4e5f-9a7b-6c8d0e2f4a6b"},
+		{CreatedAt: time.Date(2026, 10, 1, 16, 30, 0, 1000, time.FixedZone("WIB", 7*3600)), ID: "7a6b5c4d-3e2f-4a1b-8c9d-0e1f2a3b4c5d"},
+	}
+
+	for _, want := range cursors {
+		token, err := EncodeCursor(want)
+		if err != nil {
+			t.Fatalf("EncodeCursor: %v", err)
+		}
+		var got WishCursor
+		if err := DecodeCursor(token, &got); err != nil {
+			t.Fatalf("DecodeCursor(%q): %v", token, err)
+		}
+		// Postgres keeps microseconds; the cursor must not lose any of them.
+		if got.Pinned != want.Pinned || got.ID != want.ID || !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Fatalf("DecodeCursor = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	valid := `{"p":false,"t":"2026-10-01T09:30:00Z","i":"2e4f6a8c-1b3d-4e5f-9a7b-6c8d0e2f4a6b"}`
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a cursor!"},
+		{name: "padded base64", token: encode(valid) + "=="},
+		{name: "standard alphabet", token: "+/+/"},
+		{name: "truncated", token: encode(valid)[:20]},
+		{name: "not json", token: encode("page=2")},
+		{name: "json array", token: encode(`["2026-10-01T09:30:00Z"]`)},
+		{name: "wrong type", token: encode(`{"p":"yes","t":"2026-10-01T09:30:00Z","i":"x"}`)},
+		{name: "bad time", token: encode(`{"p":false,"t":"yesterday","i":"x"}`)},
+		{name: "unknown field", token: encode(`{"p":false,"t":"2026-10-01T09:30:00Z","i":"x","limit":1000}`)},
+		{name: "missing id", token: encode(`{"p":false,"t":"2026-10-01T09:30:00Z"}`)},
+		{name: "missing time", token: encode(`{"p":true,"i":"x"}`)},
+		{name: "empty object", token: encode(`{}`)},
+		{name: "trailing data", token: encode(valid + `{"i":"y"}`)},
+		{name: "trailing brace", token: encode(valid + `}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cursor WishCursor
+			if err := DecodeCursor(tt.token, &cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("DecodeCursor(%q) err = %v, want ErrInvalidCursor", tt.token, err)
+			}
+		})
+	}
+
+	var cursor WishCursor
+	if err := DecodeCursor(encode(valid), &cursor); err != nil {
+		t.Fatalf("DecodeCursor rejected a valid cursor: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package realtime fans out events to subscribers of a topic, such as the live wishes feed of an
+// invitation.
+package realtime
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultSubscriberBuffer = 16
+
+// Event is a single message delivered to subscribers.
+type Event struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// Broker publishes events to every subscriber of a topic.
+type Broker interface {
+	Publish(ctx context.Context, topic string, event Event) error
+	// Subscribe returns a channel of events and a function that ends the subscription.
+	Subscribe(topic string) (<-chan Event, func())
+	// Close ends every subscription so open streams return, e.g. when the server shuts down.
+	Close()
+}
+
+// Hub is an in-process Broker. Slow subscribers miss events instead of blocking publishers.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[chan Event]struct{}
+	buffer int
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[chan Event]struct{}), buffer: defaultSubscriberBuffer}
+}
+
+func (h *Hub) Publish(_ context.Context, topic string, event Event) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.topics[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (h *Hub) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, h.buffer)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	subs, ok := h.topics[topic]
+	if !ok {
+		subs = make(map[chan Event]struct{})
+		h.topics[topic] = subs
+	}
+	subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		// Close may have ended the subscription already.
+		subs := h.topics[topic]
+		if _, ok := subs[ch]; !ok {
+			return
+		}
+		delete(subs, ch)
+		if len(subs) == 0 {
+			delete(h.topics, topic)
+		}
+		close(ch)
+	}
+}
+
+// Close closes the channel of every subscriber. Later subscriptions get a closed channel.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for topic, subs := range h.topics {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(h.topics, topic)
+	}
+}
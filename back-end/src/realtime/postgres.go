@@ -0,0 +1,119 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
+)
+
+// Postgres limits NOTIFY payloads to 8000 bytes.
+const maxNotifyPayload = 7900
+
+// PostgresBroker delivers events locally and relays them to other instances through
+// LISTEN/NOTIFY, so every machine behind the load balancer sees every event.
+type PostgresBroker struct {
+	hub     *Hub
+	db      *gorm.DB
+	dsn     string
+	channel string
+	origin  string
+}
+
+type notification struct {
+	Origin string `json:"o"`
+	Topic  string `json:"t"`
+	ID     string `json:"i,omitempty"`
+	Name   string `json:"n,omitempty"`
+	Data   []byte `json:"d,omitempty"`
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn, channel string) (*PostgresBroker, error) {
+	origin, err := slug.GenerateToken(9)
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresBroker{hub: NewHub(), db: db, dsn: dsn, channel: channel, origin: origin}, nil
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, topic string, event Event) error {
+	if err := b.hub.Publish(ctx, topic, event); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(notification{
+		Origin: b.origin,
+		Topic:  topic,
+		ID:     event.ID,
+		Name:   event.Name,
+		Data:   event.Data,
+	})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		log.Printf("realtime: event %s on %s too large to relay (%d bytes)", event.ID, topic, len(payload))
+		return nil
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+func (b *PostgresBroker) Subscribe(topic string) (<-chan Event, func()) {
+	return b.hub.Subscribe(topic)
+}
+
+func (b *PostgresBroker) Close() {
+	b.hub.Close()
+}
+
+// Listen relays notifications from other instances until ctx is done, reconnecting with backoff
+// when the connection drops.
+func (b *PostgresBroker) Listen(ctx context.Context) {
+	backoff := time.Second
+	for {
+		err := b.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("realtime: listen %s: %v; retrying in %s", b.channel, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *PostgresBroker) listenOnce(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var n notification
+		if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil || n.Origin == b.origin {
+			continue
+		}
+		_ = b.hub.Publish(ctx, n.Topic, Event{ID: n.ID, Name: n.Name, Data: n.Data})
+	}
+}
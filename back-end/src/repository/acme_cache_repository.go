@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AcmeCacheRepository struct {
+	DB *gorm.DB
+}
+
+func (r *AcmeCacheRepository) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var entry model.AcmeCacheEntry
+	err := r.DB.WithContext(ctx).
+		Model(&model.AcmeCacheEntry{}).
+		Where("key = ?", key).
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return entry.Data, true, nil
+}
+
+func (r *AcmeCacheRepository) Put(ctx context.Context, key string, data []byte) error {
+	entry := model.AcmeCacheEntry{Key: key, Data: data, UpdatedAt: time.Now()}
+	return r.DB.WithContext(ctx).
+		Model(&model.AcmeCacheEntry{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+		}).
+		Create(&entry).Error
+}
+
+func (r *AcmeCacheRepository) Delete(ctx context.Context, key string) error {
+	return r.DB.WithContext(ctx).Where("key = ?", key).Delete(&model.AcmeCacheEntry{}).Error
+}
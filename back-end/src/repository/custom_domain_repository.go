@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"gorm.io/gorm"
+)
+
+type CustomDomainRepository struct {
+	DB    *gorm.DB
+	Cache *TenantCache
+}
+
+func (r *CustomDomainRepository) Create(ctx context.Context, customerID, hostname, token string) (model.CustomDomain, error) {
+	return r.createWithDB(ctx, r.DB, customerID, hostname, token)
+}
+
+func (r *CustomDomainRepository) CreateTx(ctx context.Context, tx *gorm.DB, customerID, hostname, token string) (model.CustomDomain, error) {
+	return r.createWithDB(ctx, tx, customerID, hostname, token)
+}
+
+func (r *CustomDomainRepository) createWithDB(ctx context.Context, db *gorm.DB, customerID, hostname, token string) (model.CustomDomain, error) {
+	item := model.CustomDomain{
+		CustomerID:        customerID,
+		Hostname:          hostname,
+		VerificationToken: token,
+		Status:            model.CustomDomainStatusPending,
+	}
+	if err := db.WithContext(ctx).Model(&model.CustomDomain{}).Create(&item).Error; err != nil {
+		return model.CustomDomain{}, err
+	}
+	return item, nil
+}
+
+func (r *CustomDomainRepository) GetByIDAndCustomer(ctx context.Context, id, customerID string) (model.CustomDomain, bool, error) {
+	var item model.CustomDomain
+	err := r.DB.WithContext(ctx).
+		Model(&model.CustomDomain{}).
+		Where("id = ? AND customer_id = ?", id, customerID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.CustomDomain{}, false, nil
+	}
+	if err != nil {
+		return model.CustomDomain{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *CustomDomainRepository) ListByCustomer(ctx context.Context, customerID string) ([]model.CustomDomain, error) {
+	return r.listByCustomerWithDB(r.DB.WithContext(ctx), customerID)
+}
+
+func (r *CustomDomainRepository) ListByCustomerTx(ctx context.Context, tx *gorm.DB, customerID string) ([]model.CustomDomain, error) {
+	return r.listByCustomerWithDB(tx.WithContext(ctx), customerID)
+}
+
+func (r *CustomDomainRepository) listByCustomerWithDB(db *gorm.DB, customerID string) ([]model.CustomDomain, error) {
+	items := make([]model.CustomDomain, 0)
+	err := db.
+		Model(&model.CustomDomain{}).
+		Where("customer_id = ?", customerID).
+		Order("created_at ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// VerifiedByOther reports whether another customer already proved control of the hostname.
+func (r *CustomDomainRepository) VerifiedByOther(ctx context.Context, hostname, customerID string) (bool, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&model.CustomDomain{}).
+		Where("hostname = ? AND status = ? AND customer_id <> ?", hostname, model.CustomDomainStatusVerified, customerID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindVerifiedCustomer returns the customer whose verified domain matches the hostname.
+func (r *CustomDomainRepository) FindVerifiedCustomer(ctx context.Context, hostname string) (model.Customer, bool, error) {
+	key := "host:" + hostname
+	cached, gen, ok := r.Cache.customer(key)
+	if ok {
+		return cached.customer, cached.found, nil
+	}
+
+	var customer model.Customer
+	err := r.DB.WithContext(ctx).
+		Model(&model.Customer{}).
+		Joins("JOIN custom_domains ON custom_domains.customer_id = customers.id").
+		Where("custom_domains.hostname = ? AND custom_domains.status = ?", hostname, model.CustomDomainStatusVerified).
+		First(&customer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Cache.putCustomer(gen, key, model.Customer{}, false)
+		return model.Customer{}, false, nil
+	}
+	if err != nil {
+		return model.Customer{}, false, err
+	}
+	r.Cache.putCustomer(gen, key, customer, true)
+	return customer, true, nil
+}
+
+func (r *CustomDomainRepository) MarkVerified(ctx context.Context, id string, at time.Time) error {
+	err := r.DB.WithContext(ctx).
+		Model(&model.CustomDomain{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":          model.CustomDomainStatusVerified,
+			"verified_at":     at,
+			"last_checked_at": at,
+			"last_error":      "",
+		}).Error
+	if err != nil {
+		return err
+	}
+	r.Cache.forgetHosts()
+	return nil
+}
+
+func (r *CustomDomainRepository) MarkFailed(ctx context.Context, id string, at time.Time, reason string) error {
+	err := r.DB.WithContext(ctx).
+		Model(&model.CustomDomain{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":          model.CustomDomainStatusFailed,
+			"verified_at":     nil,
+			"last_checked_at": at,
+			"last_error":      reason,
+		}).Error
+	if err != nil {
+		return err
+	}
+	r.Cache.forgetHosts()
+	return nil
+}
+
+func (r *CustomDomainRepository) Delete(ctx context.Context, id string) error {
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.CustomDomain{}).Error; err != nil {
+		return err
+	}
+	r.Cache.forgetHosts()
+	return nil
+}
@@ -9,7 +9,8 @@ import (
 )
 
 type CustomerRepository struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Cache *TenantCache
 }
 
 type CustomerCreateInput struct {
@@ -21,9 +22,15 @@ type CustomerCreateInput struct {
 }
 
 func (r *CustomerRepository) Create(ctx context.Context, input CustomerCreateInput) (string, error) {
-	return r.createWithDB(ctx, r.DB, input)
+	id, err := r.createWithDB(ctx, r.DB, input)
+	if err != nil {
+		return "", err
+	}
+	r.Cache.forgetCustomer(id)
+	return id, nil
 }
 
+// CreateTx leaves the tenant cache alone; call ForgetCached once the transaction commits.
 func (r *CustomerRepository) CreateTx(ctx context.Context, tx *gorm.DB, input CustomerCreateInput) (string, error) {
 	return r.createWithDB(ctx, tx, input)
 }
@@ -55,14 +62,22 @@ func (r *CustomerRepository) FindByEmail(ctx context.Context, email string) (mod
 }
 
 func (r *CustomerRepository) FindByDomain(ctx context.Context, domain string) (model.Customer, bool, error) {
+	key := "domain:" + domain
+	cached, gen, ok := r.Cache.customer(key)
+	if ok {
+		return cached.customer, cached.found, nil
+	}
+
 	var customer model.Customer
 	err := r.DB.WithContext(ctx).Model(&model.Customer{}).Where("domain = ?", domain).First(&customer).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Cache.putCustomer(gen, key, model.Customer{}, false)
 		return model.Customer{}, false, nil
 	}
 	if err != nil {
 		return model.Customer{}, false, err
 	}
+	r.Cache.putCustomer(gen, key, customer, true)
 	return customer, true, nil
 }
 
@@ -78,27 +93,63 @@ func (r *CustomerRepository) FindByID(ctx context.Context, id string) (model.Cus
 	return customer, true, nil
 }
 
+// LockTx takes a row lock on the customer for the rest of the transaction, serializing changes
+// that are limited per customer.
+func (r *CustomerRepository) LockTx(ctx context.Context, tx *gorm.DB, id string) error {
+	return tx.WithContext(ctx).Exec("SELECT 1 FROM customers WHERE id = ? FOR UPDATE", id).Error
+}
+
 func (r *CustomerRepository) UpdateDomainIfEmpty(ctx context.Context, id string, domain string) error {
-	return r.DB.WithContext(ctx).
+	err := r.DB.WithContext(ctx).
 		Model(&model.Customer{}).
 		Where("id = ? AND (domain = '' OR domain IS NULL)", id).
 		Update("domain", domain).Error
+	if err != nil {
+		return err
+	}
+	r.Cache.forgetCustomer(id)
+	return nil
 }
 
 func (r *CustomerRepository) UpdateDomain(ctx context.Context, id string, domain string) error {
-	return r.DB.WithContext(ctx).
+	err := r.DB.WithContext(ctx).
 		Model(&model.Customer{}).
 		Where("id = ?", id).
 		Update("domain", domain).Error
+	if err != nil {
+		return err
+	}
+	r.Cache.forgetCustomer(id)
+	return nil
 }
 
 func (r *CustomerRepository) UpdateStatus(ctx context.Context, id string, status string) error {
-	return r.DB.WithContext(ctx).
+	if err := r.updateStatusWithDB(ctx, r.DB, id, status); err != nil {
+		return err
+	}
+	r.Cache.forgetCustomer(id)
+	return nil
+}
+
+// UpdateStatusTx leaves the tenant cache alone; call ForgetCached once the transaction commits.
+func (r *CustomerRepository) UpdateStatusTx(ctx context.Context, tx *gorm.DB, id string, status string) error {
+	return r.updateStatusWithDB(ctx, tx, id, status)
+}
+
+func (r *CustomerRepository) updateStatusWithDB(ctx context.Context, db *gorm.DB, id string, status string) error {
+	return db.WithContext(ctx).
 		Model(&model.Customer{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
+// ForgetCached drops the tenant cache's lookups of the customer after a transaction that
+// changed it has committed. Invalidating inside the transaction would let a concurrent request
+// cache the old row again before the commit.
+func (r *CustomerRepository) ForgetCached(id string) {
+	r.Cache.forgetCustomer(id)
+}
+
 func (r *CustomerRepository) ExistsByDomain(ctx context.Context, domain string) (bool, error) {
 	var count int64
 	err := r.DB.WithContext(ctx).Model(&model.Customer{}).Where("domain = ?", domain).Count(&count).Error
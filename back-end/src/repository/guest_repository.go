@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"gorm.io/gorm"
+)
+
+type GuestRepository struct {
+	DB *gorm.DB
+}
+
+type GuestCreateInput struct {
+	InvitationID string
+	Name         string
+	Phone        string
+	GroupName    string
+	Seat         string
+	MaxPartySize int
+	Token        string
+}
+
+type GuestUpdateInput struct {
+	Name         string
+	Phone        string
+	GroupName    string
+	Seat         string
+	MaxPartySize int
+}
+
+type GuestWithRsvp struct {
+	model.Guest
+	Attendance  *string    `gorm:"column:attendance"`
+	GuestsCount *int       `gorm:"column:guests_count"`
+	RespondedAt *time.Time `gorm:"column:responded_at"`
+}
+
+func (r *GuestRepository) Create(ctx context.Context, input GuestCreateInput) (model.Guest, error) {
+	return r.createWithDB(ctx, r.DB, input)
+}
+
+func (r *GuestRepository) CreateTx(ctx context.Context, tx *gorm.DB, input GuestCreateInput) (model.Guest, error) {
+	return r.createWithDB(ctx, tx, input)
+}
+
+func (r *GuestRepository) createWithDB(ctx context.Context, db *gorm.DB, input GuestCreateInput) (model.Guest, error) {
+	item := model.Guest{
+		InvitationID: input.InvitationID,
+		Name:         input.Name,
+		Phone:        input.Phone,
+		GroupName:    input.GroupName,
+		Seat:         input.Seat,
+		MaxPartySize: input.MaxPartySize,
+		Token:        input.Token,
+	}
+	if err := db.WithContext(ctx).Model(&model.Guest{}).Create(&item).Error; err != nil {
+		return model.Guest{}, err
+	}
+	return item, nil
+}
+
+func (r *GuestRepository) GetByIDAndInvitation(ctx context.Context, id, invitationID string) (model.Guest, bool, error) {
+	var item model.Guest
+	err := r.DB.WithContext(ctx).
+		Model(&model.Guest{}).
+		Where("id = ? AND invitation_id = ?", id, invitationID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Guest{}, false, nil
+	}
+	if err != nil {
+		return model.Guest{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *GuestRepository) FindByInvitationAndToken(ctx context.Context, invitationID, token string) (model.Guest, bool, error) {
+	var item model.Guest
+	err := r.DB.WithContext(ctx).
+		Model(&model.Guest{}).
+		Where("invitation_id = ? AND token = ?", invitationID, token).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Guest{}, false, nil
+	}
+	if err != nil {
+		return model.Guest{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *GuestRepository) ListByInvitationID(ctx context.Context, invitationID string) ([]model.Guest, error) {
+	return r.listWithDB(ctx, r.DB, invitationID)
+}
+
+func (r *GuestRepository) ListByInvitationIDTx(ctx context.Context, tx *gorm.DB, invitationID string) ([]model.Guest, error) {
+	return r.listWithDB(ctx, tx, invitationID)
+}
+
+func (r *GuestRepository) listWithDB(ctx context.Context, db *gorm.DB, invitationID string) ([]model.Guest, error) {
+	items := make([]model.Guest, 0)
+	err := db.WithContext(ctx).
+		Model(&model.Guest{}).
+		Where("invitation_id = ?", invitationID).
+		Order("created_at ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *GuestRepository) Update(ctx context.Context, id string, input GuestUpdateInput) error {
+	return r.updateWithDB(ctx, r.DB, id, input)
+}
+
+func (r *GuestRepository) UpdateTx(ctx context.Context, tx *gorm.DB, id string, input GuestUpdateInput) error {
+	return r.updateWithDB(ctx, tx, id, input)
+}
+
+func (r *GuestRepository) updateWithDB(ctx context.Context, db *gorm.DB, id string, input GuestUpdateInput) error {
+	updates := map[string]any{
+		"name":           input.Name,
+		"phone":          input.Phone,
+		"group_name":     input.GroupName,
+		"seat":           input.Seat,
+		"max_party_size": input.MaxPartySize,
+	}
+
+	return db.WithContext(ctx).
+		Model(&model.Guest{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *GuestRepository) Delete(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.Guest{}).Error
+}
+
+// EachWithRsvp streams the guest list joined with each guest's latest RSVP.
+func (r *GuestRepository) EachWithRsvp(ctx context.Context, invitationID string, fn func(GuestWithRsvp) error) error {
+	rows, err := r.DB.WithContext(ctx).
+		Table("guests").
+		Select("guests.*, latest.attendance, latest.guests_count, latest.updated_at as responded_at").
+		Joins("LEFT JOIN LATERAL (SELECT attendance, guests_count, updated_at FROM rsvps WHERE rsvps.guest_id = guests.id ORDER BY updated_at DESC LIMIT 1) latest ON true").
+		Where("guests.invitation_id = ?", invitationID).
+		Order("guests.created_at ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item GuestWithRsvp
+		if err := r.DB.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
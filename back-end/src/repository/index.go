@@ -11,17 +11,26 @@ type Registry struct {
 	Payment               *PaymentRepository
 	Rsvp                  *RsvpRepository
 	Wish                  *WishRepository
+	Guest                 *GuestRepository
+	Media                 *MediaRepository
+	CustomDomain          *CustomDomainRepository
+	AcmeCache             *AcmeCacheRepository
 }
 
-func NewRegistry(db *gorm.DB) Registry {
+// NewRegistry builds the repositories; tenants may be nil to disable the tenant cache.
+func NewRegistry(db *gorm.DB, tenants *TenantCache) Registry {
 	return Registry{
-		Customer:             &CustomerRepository{DB: db},
+		Customer:             &CustomerRepository{DB: db, Cache: tenants},
 		CustomerRefreshToken: &CustomerRefreshTokenRepository{DB: db},
-		Invitation:           &InvitationRepository{DB: db},
+		Invitation:           &InvitationRepository{DB: db, Cache: tenants},
 		User:                 &UserRepository{DB: db},
 		Plan:                 &PlanRepository{DB: db},
 		Payment:              &PaymentRepository{DB: db},
 		Rsvp:                 &RsvpRepository{DB: db},
 		Wish:                 &WishRepository{DB: db},
+		Guest:                &GuestRepository{DB: db},
+		Media:                &MediaRepository{DB: db},
+		CustomDomain:         &CustomDomainRepository{DB: db, Cache: tenants},
+		AcmeCache:            &AcmeCacheRepository{DB: db},
 	}
 }
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"time"
 
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InvitationWithCustomer struct {
@@ -28,12 +30,37 @@ type invitationWithCustomerRow struct {
 	Content        []byte     `gorm:"column:content"`
 	CreatedAt      time.Time  `gorm:"column:created_at"`
 	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+	PublishAt      *time.Time `gorm:"column:publish_at"`
+	ArchiveAt      *time.Time `gorm:"column:archive_at"`
 	CustomerName   *string    `gorm:"column:customer_name"`
 	CustomerDomain *string    `gorm:"column:customer_domain"`
 }
 
+func (row invitationWithCustomerRow) toInvitationWithCustomer() InvitationWithCustomer {
+	return InvitationWithCustomer{
+		Invitation: model.Invitation{
+			ID:          row.ID,
+			CustomerID:  row.CustomerID,
+			Slug:        row.Slug,
+			Title:       row.Title,
+			SearchName:  row.SearchName,
+			EventDate:   row.EventDate,
+			ThemeKey:    row.ThemeKey,
+			IsPublished: row.IsPublished,
+			Content:     row.Content,
+			PublishAt:   row.PublishAt,
+			ArchiveAt:   row.ArchiveAt,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		},
+		CustomerName:   derefString(row.CustomerName),
+		CustomerDomain: derefString(row.CustomerDomain),
+	}
+}
+
 type InvitationRepository struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Cache *TenantCache
 }
 
 type InvitationCreateInput struct {
@@ -45,12 +72,31 @@ type InvitationCreateInput struct {
 	ThemeKey    string
 	IsPublished bool
 	Content     []byte
+	// RequireWishApproval is left unchanged on update when nil.
+	RequireWishApproval *bool
+	// Author is recorded on the content revision written by this change.
+	Author RevisionAuthor
+	// KeepRevisions prunes older revisions beyond this many; zero keeps them all.
+	KeepRevisions int
+	// RestoredFrom marks the change as a rollback to that revision.
+	RestoredFrom *int
+}
+
+type RevisionAuthor struct {
+	Type string
+	ID   string
 }
 
 type InvitationUpdateInput = InvitationCreateInput
 
 func (r *InvitationRepository) Create(ctx context.Context, input InvitationCreateInput) (string, error) {
-	return r.createWithDB(ctx, r.DB, input)
+	var id string
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		id, err = r.createWithDB(ctx, tx, input)
+		return err
+	})
+	return id, err
 }
 
 func (r *InvitationRepository) CreateTx(ctx context.Context, tx *gorm.DB, input InvitationCreateInput) (string, error) {
@@ -68,9 +114,20 @@ func (r *InvitationRepository) createWithDB(ctx context.Context, db *gorm.DB, in
 		IsPublished: input.IsPublished,
 		Content:     input.Content,
 	}
+	if input.RequireWishApproval != nil {
+		inv.RequireWishApproval = *input.RequireWishApproval
+	}
+	if input.IsPublished {
+		now := time.Now()
+		inv.PublishedContent = input.Content
+		inv.PublishedAt = &now
+	}
 	if err := db.WithContext(ctx).Model(&model.Invitation{}).Create(&inv).Error; err != nil {
 		return "", err
 	}
+	if err := r.createRevisionTx(ctx, db, inv.ID, 1, input.Content, input.Author, nil); err != nil {
+		return "", err
+	}
 	return inv.ID, nil
 }
 
@@ -86,40 +143,295 @@ func (r *InvitationRepository) GetByID(ctx context.Context, id string) (model.In
 	return inv, true, nil
 }
 
+// Update overwrites the invitation's draft and appends a content revision when the content
+// changed. Publication state is left alone; see Publish and Unpublish.
 func (r *InvitationRepository) Update(ctx context.Context, id string, input InvitationUpdateInput) error {
 	updates := map[string]any{
-		"customer_id":  input.CustomerID,
-		"slug":         input.Slug,
-		"title":        input.Title,
-		"search_name":  input.SearchName,
-		"event_date":   input.EventDate,
-		"theme_key":    input.ThemeKey,
-		"is_published": input.IsPublished,
-		"content":      input.Content,
+		"customer_id": input.CustomerID,
+		"slug":        input.Slug,
+		"title":       input.Title,
+		"search_name": input.SearchName,
+		"event_date":  input.EventDate,
+		"theme_key":   input.ThemeKey,
+		"content":     input.Content,
+	}
+	if input.RequireWishApproval != nil {
+		updates["require_wish_approval"] = *input.RequireWishApproval
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.Invitation
+		err := tx.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&model.Invitation{}).
+			Select("id", "content").
+			Where("id = ?", id).
+			First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.WithContext(ctx).
+			Model(&model.Invitation{}).
+			Where("id = ?", id).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return r.appendRevisionTx(ctx, tx, current, input)
+	})
+	if err != nil {
+		return err
+	}
+	r.Cache.forgetInvitations(id)
+	return nil
+}
+
+func (r *InvitationRepository) appendRevisionTx(ctx context.Context, tx *gorm.DB, current model.Invitation, input InvitationUpdateInput) error {
+	latest, ok, err := r.latestRevisionTx(ctx, tx, current.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Invitations created before revisions existed start their history from the stored content.
+		latest = model.InvitationRevision{Version: 1, Content: current.Content}
+		if err := r.createRevisionTx(ctx, tx, current.ID, 1, current.Content, RevisionAuthor{Type: model.RevisionAuthorSystem}, nil); err != nil {
+			return err
+		}
+	}
+	if content.Equal(latest.Content, input.Content) && input.RestoredFrom == nil {
+		return nil
+	}
+
+	if err := r.createRevisionTx(ctx, tx, current.ID, latest.Version+1, input.Content, input.Author, input.RestoredFrom); err != nil {
+		return err
+	}
+	if input.KeepRevisions <= 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).
+		Where("invitation_id = ? AND version <= ?", current.ID, latest.Version+1-input.KeepRevisions).
+		Delete(&model.InvitationRevision{}).Error
+}
+
+func (r *InvitationRepository) createRevisionTx(ctx context.Context, tx *gorm.DB, invitationID string, version int, raw []byte, author RevisionAuthor, restoredFrom *int) error {
+	if len(raw) == 0 {
+		raw = []byte("{}")
+	}
+	authorType := author.Type
+	if authorType == "" {
+		authorType = model.RevisionAuthorSystem
+	}
+	return tx.WithContext(ctx).Create(&model.InvitationRevision{
+		InvitationID: invitationID,
+		Version:      version,
+		Content:      raw,
+		AuthorType:   authorType,
+		AuthorID:     author.ID,
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+func (r *InvitationRepository) latestRevisionTx(ctx context.Context, tx *gorm.DB, invitationID string) (model.InvitationRevision, bool, error) {
+	var revision model.InvitationRevision
+	err := tx.WithContext(ctx).
+		Model(&model.InvitationRevision{}).
+		Where("invitation_id = ?", invitationID).
+		Order("version DESC").
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.InvitationRevision{}, false, nil
+	}
+	if err != nil {
+		return model.InvitationRevision{}, false, err
+	}
+	return revision, true, nil
+}
+
+// ListRevisions returns the revisions of an invitation, newest first, without their content.
+func (r *InvitationRepository) ListRevisions(ctx context.Context, invitationID string) ([]model.InvitationRevision, error) {
+	items := make([]model.InvitationRevision, 0)
+	err := r.DB.WithContext(ctx).
+		Model(&model.InvitationRevision{}).
+		Omit("content").
+		Where("invitation_id = ?", invitationID).
+		Order("version DESC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *InvitationRepository) GetRevision(ctx context.Context, invitationID string, version int) (model.InvitationRevision, bool, error) {
+	var revision model.InvitationRevision
+	err := r.DB.WithContext(ctx).
+		Model(&model.InvitationRevision{}).
+		Where("invitation_id = ? AND version = ?", invitationID, version).
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.InvitationRevision{}, false, nil
+	}
+	if err != nil {
+		return model.InvitationRevision{}, false, err
+	}
+	return revision, true, nil
+}
+
+// Publish copies the current draft to the published snapshot in a single statement, so guests
+// never see a half-applied edit. It reports false when the invitation does not exist.
+func (r *InvitationRepository) Publish(ctx context.Context, id string) (bool, error) {
+	published, err := r.publishWithDB(ctx, r.DB, id)
+	if err != nil {
+		return false, err
 	}
+	r.Cache.forgetInvitations(id)
+	return published, nil
+}
+
+// PublishTx leaves the tenant cache alone; call ForgetCached once the transaction commits.
+func (r *InvitationRepository) PublishTx(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	return r.publishWithDB(ctx, tx, id)
+}
+
+func (r *InvitationRepository) publishWithDB(ctx context.Context, db *gorm.DB, id string) (bool, error) {
+	result := db.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"published_content": gorm.Expr("content"),
+			"published_at":      gorm.Expr("now()"),
+			"is_published":      true,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Unpublish hides the invitation from guests but keeps the last published snapshot.
+func (r *InvitationRepository) Unpublish(ctx context.Context, id string) (bool, error) {
+	unpublished, err := r.unpublishWithDB(ctx, r.DB, id)
+	if err != nil {
+		return false, err
+	}
+	r.Cache.forgetInvitations(id)
+	return unpublished, nil
+}
+
+// UnpublishTx leaves the tenant cache alone; call ForgetCached once the transaction commits.
+func (r *InvitationRepository) UnpublishTx(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	return r.unpublishWithDB(ctx, tx, id)
+}
+
+func (r *InvitationRepository) unpublishWithDB(ctx context.Context, db *gorm.DB, id string) (bool, error) {
+	result := db.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("id = ?", id).
+		Update("is_published", false)
+	return result.RowsAffected > 0, result.Error
+}
 
-	return r.DB.WithContext(ctx).
+// ForgetCached drops the tenant cache's lookups of the invitations after a transaction that
+// changed them has committed.
+func (r *InvitationRepository) ForgetCached(ids ...string) {
+	r.Cache.forgetInvitations(ids...)
+}
+
+// SetSchedule replaces the pending publish and archive times; nil clears a schedule.
+func (r *InvitationRepository) SetSchedule(ctx context.Context, id string, publishAt, archiveAt *time.Time) error {
+	return r.setScheduleWithDB(ctx, r.DB, id, publishAt, archiveAt)
+}
+
+func (r *InvitationRepository) SetScheduleTx(ctx context.Context, tx *gorm.DB, id string, publishAt, archiveAt *time.Time) error {
+	return r.setScheduleWithDB(ctx, tx, id, publishAt, archiveAt)
+}
+
+func (r *InvitationRepository) setScheduleWithDB(ctx context.Context, db *gorm.DB, id string, publishAt, archiveAt *time.Time) error {
+	return db.WithContext(ctx).
 		Model(&model.Invitation{}).
 		Where("id = ?", id).
-		Updates(updates).Error
+		Updates(map[string]any{
+			"publish_at": publishAt,
+			"archive_at": archiveAt,
+		}).Error
+}
+
+// PublishDue publishes every invitation whose publish_at has passed, copying its draft like
+// Publish does, and returns their ids. Running it on several instances at once is safe because
+// each row is claimed by the UPDATE itself.
+func (r *InvitationRepository) PublishDue(ctx context.Context, now time.Time) ([]string, error) {
+	ids := make([]string, 0)
+	err := r.DB.WithContext(ctx).Raw(`
+		UPDATE invitations
+		SET published_content = content, published_at = ?, is_published = true, publish_at = NULL, updated_at = ?
+		WHERE publish_at IS NOT NULL AND publish_at <= ?
+		RETURNING id`, now, now, now).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	r.Cache.forgetInvitations(ids...)
+	return ids, nil
+}
+
+// ArchiveDue unpublishes every invitation whose archive_at has passed and returns their ids.
+func (r *InvitationRepository) ArchiveDue(ctx context.Context, now time.Time) ([]string, error) {
+	ids := make([]string, 0)
+	err := r.DB.WithContext(ctx).Raw(`
+		UPDATE invitations
+		SET is_published = false, archive_at = NULL, updated_at = ?
+		WHERE archive_at IS NOT NULL AND archive_at <= ?
+		RETURNING id`, now, now).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	r.Cache.forgetInvitations(ids...)
+	return ids, nil
+}
+
+// ReferencesText reports whether any of the customer's invitations mentions text in its draft or
+// published content, e.g. the URL of an uploaded file.
+func (r *InvitationRepository) ReferencesText(ctx context.Context, customerID, text string) (bool, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("customer_id = ?", customerID).
+		Where("strpos(content::text, ?) > 0 OR strpos(COALESCE(published_content::text, ''), ?) > 0", text, text).
+		Count(&count).Error
+	return count > 0, err
 }
 
 func (r *InvitationRepository) Delete(ctx context.Context, id string) error {
-	return r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.Invitation{}).Error
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.Invitation{}).Error; err != nil {
+		return err
+	}
+	r.Cache.forgetInvitations(id)
+	return nil
 }
 
+// FindPublishedByCustomerAndSlug serves repeated lookups from the tenant cache. The returned
+// invitation may be shared with other callers and must not be modified.
 func (r *InvitationRepository) FindPublishedByCustomerAndSlug(ctx context.Context, customerID, slug string) (model.Invitation, bool, error) {
+	cached, gen, ok := r.Cache.invitation(customerID, slug)
+	if ok {
+		return cached.invitation, cached.found, nil
+	}
+
 	var inv model.Invitation
 	err := r.DB.WithContext(ctx).
 		Model(&model.Invitation{}).
-		Where("customer_id = ? AND slug = ? AND is_published = ?", customerID, slug, true).
+		Where("customer_id = ? AND slug = ? AND is_published = ? AND published_content IS NOT NULL", customerID, slug, true).
 		First(&inv).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Cache.putInvitation(gen, customerID, slug, model.Invitation{}, false)
 		return model.Invitation{}, false, nil
 	}
 	if err != nil {
 		return model.Invitation{}, false, err
 	}
+	r.Cache.putInvitation(gen, customerID, slug, inv, true)
 	return inv, true, nil
 }
 
@@ -139,7 +451,7 @@ func (r *InvitationRepository) List(ctx context.Context, filters query.Invitatio
 		query = query.Where("event_date <= ?", *filters.DateTo)
 	}
 	if filters.Query != "" {
-		likeQuery := "%" + filters.Query + "%"
+		likeQuery := containsPattern(filters.Query)
 		query = query.Where("(title ILIKE ? OR search_name ILIKE ?)", likeQuery, likeQuery)
 	}
 
@@ -151,10 +463,34 @@ func (r *InvitationRepository) List(ctx context.Context, filters query.Invitatio
 	return items, nil
 }
 
+// ListByCustomerID returns every invitation of the customer, newest first. The plan's
+// max_invitations keeps the list short, so it is not paged.
+func (r *InvitationRepository) ListByCustomerID(ctx context.Context, customerID string) ([]model.Invitation, error) {
+	return r.listByCustomerIDWithDB(r.DB.WithContext(ctx), customerID)
+}
+
+func (r *InvitationRepository) ListByCustomerIDTx(ctx context.Context, tx *gorm.DB, customerID string) ([]model.Invitation, error) {
+	return r.listByCustomerIDWithDB(tx.WithContext(ctx), customerID)
+}
+
+// ListByCustomerIDForUpdateTx is ListByCustomerIDTx with every row locked until the transaction
+// ends, so a scheduled publish cannot change them in between.
+func (r *InvitationRepository) ListByCustomerIDForUpdateTx(ctx context.Context, tx *gorm.DB, customerID string) ([]model.Invitation, error) {
+	return r.listByCustomerIDWithDB(tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), customerID)
+}
+
+func (r *InvitationRepository) listByCustomerIDWithDB(db *gorm.DB, customerID string) ([]model.Invitation, error) {
+	items := make([]model.Invitation, 0)
+	if err := db.Where("customer_id = ?", customerID).Order("created_at DESC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (r *InvitationRepository) ListWithCustomer(ctx context.Context, filters query.InvitationListFilters) ([]InvitationWithCustomer, error) {
 	query := r.DB.WithContext(ctx).
 		Model(&model.Invitation{}).
-		Select("invitations.id, invitations.customer_id, invitations.slug, invitations.title, invitations.search_name, invitations.event_date, invitations.theme_key, invitations.is_published, invitations.content, invitations.created_at, invitations.updated_at, customers.full_name as customer_name, customers.domain as customer_domain").
+		Select("invitations.id, invitations.customer_id, invitations.slug, invitations.title, invitations.search_name, invitations.event_date, invitations.theme_key, invitations.is_published, invitations.content, invitations.publish_at, invitations.archive_at, invitations.created_at, invitations.updated_at, customers.full_name as customer_name, customers.domain as customer_domain").
 		Joins("LEFT JOIN customers ON customers.id = invitations.customer_id")
 
 	if filters.CustomerID != "" {
@@ -170,7 +506,7 @@ func (r *InvitationRepository) ListWithCustomer(ctx context.Context, filters que
 		query = query.Where("invitations.event_date <= ?", *filters.DateTo)
 	}
 	if filters.Query != "" {
-		likeQuery := "%" + filters.Query + "%"
+		likeQuery := containsPattern(filters.Query)
 		query = query.Where("(invitations.title ILIKE ? OR invitations.search_name ILIKE ?)", likeQuery, likeQuery)
 	}
 
@@ -181,28 +517,69 @@ func (r *InvitationRepository) ListWithCustomer(ctx context.Context, filters que
 
 	items := make([]InvitationWithCustomer, 0, len(rows))
 	for _, row := range rows {
-		items = append(items, InvitationWithCustomer{
-			Invitation: model.Invitation{
-				ID:          row.ID,
-				CustomerID:  row.CustomerID,
-				Slug:        row.Slug,
-				Title:       row.Title,
-				SearchName:  row.SearchName,
-				EventDate:   row.EventDate,
-				ThemeKey:    row.ThemeKey,
-				IsPublished: row.IsPublished,
-				Content:     row.Content,
-				CreatedAt:   row.CreatedAt,
-				UpdatedAt:   row.UpdatedAt,
-			},
-			CustomerName:   derefString(row.CustomerName),
-			CustomerDomain: derefString(row.CustomerDomain),
-		})
+		items = append(items, row.toInvitationWithCustomer())
 	}
 
 	return items, nil
 }
 
+// ListScheduled returns invitations with a pending publish or archive, soonest first.
+func (r *InvitationRepository) ListScheduled(ctx context.Context, limit, offset int) ([]InvitationWithCustomer, int64, error) {
+	base := r.DB.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("invitations.publish_at IS NOT NULL OR invitations.archive_at IS NOT NULL")
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([]invitationWithCustomerRow, 0)
+	err := base.
+		Select("invitations.id, invitations.customer_id, invitations.slug, invitations.title, invitations.search_name, invitations.event_date, invitations.theme_key, invitations.is_published, invitations.publish_at, invitations.archive_at, invitations.created_at, invitations.updated_at, customers.full_name as customer_name, customers.domain as customer_domain").
+		Joins("LEFT JOIN customers ON customers.id = invitations.customer_id").
+		Order("LEAST(invitations.publish_at, invitations.archive_at) ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]InvitationWithCustomer, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.toInvitationWithCustomer())
+	}
+	return items, total, nil
+}
+
+// LockTx takes a row lock on the invitation for the rest of the transaction, serializing writes
+// that are limited per invitation such as RSVPs and wishes.
+func (r *InvitationRepository) LockTx(ctx context.Context, tx *gorm.DB, id string) error {
+	return tx.WithContext(ctx).Exec("SELECT 1 FROM invitations WHERE id = ? FOR UPDATE", id).Error
+}
+
+func (r *InvitationRepository) CountByCustomerTx(ctx context.Context, tx *gorm.DB, customerID string) (int64, error) {
+	var count int64
+	err := tx.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("customer_id = ?", customerID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *InvitationRepository) ExistsByCustomerAndSlugTx(ctx context.Context, tx *gorm.DB, customerID, slug string) (bool, error) {
+	var count int64
+	err := tx.WithContext(ctx).
+		Model(&model.Invitation{}).
+		Where("customer_id = ? AND slug = ?", customerID, slug).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *InvitationRepository) ExistsByCustomerAndSlug(ctx context.Context, customerID, slug string) (bool, error) {
 	var count int64
 	err := r.DB.WithContext(ctx).
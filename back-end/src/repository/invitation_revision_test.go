@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+const testInvitationID = "3c2b1a09-8f7e-4d6c-9b5a-4e3d2c1b0a9f"
+
+var revisionColumns = []string{"id", "invitation_id", "version", "content", "author_type", "author_id", "restored_from"}
+
+// expectDraftUpdate expects the locked read of the current draft, its update and the lookup of
+// the latest revision.
+func expectDraftUpdate(mock sqlmock.Sqlmock, latest *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id","content" FROM "invitations" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs(testInvitationID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(testInvitationID, []byte(`{"couple": {"groomName": "Bayu"}}`)))
+	mock.ExpectExec(`UPDATE "invitations" SET .*"content"=`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "invitation_revisions" WHERE invitation_id = \$1 ORDER BY version DESC`).
+		WithArgs(testInvitationID, 1).
+		WillReturnRows(latest)
+}
+
+func expectRevisionInsert(mock sqlmock.Sqlmock, version int, raw string, authorType, authorID string, restoredFrom any) {
+	mock.ExpectQuery(`INSERT INTO "invitation_revisions" \("invitation_id","version","content","author_type","author_id","restored_from","created_at"\)`).
+		WithArgs(testInvitationID, version, []byte(raw), authorType, authorID, restoredFrom, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("revision-new"))
+}
+
+func updateInput(raw string) InvitationUpdateInput {
+	return InvitationUpdateInput{
+		CustomerID: testCustomerID,
+		Slug:       "rina-dan-bayu",
+		Content:    []byte(raw),
+		Author:     RevisionAuthor{Type: model.RevisionAuthorCustomer, ID: testCustomerID},
+	}
+}
+
+func TestUpdateWithUnchangedContentWritesNoRevision(t *testing.T) {
+	db, mock := testdb.New(t)
+	repo := &InvitationRepository{DB: db}
+
+	// Key order and spacing differ from the latest revision; the content does not.
+	expectDraftUpdate(mock, sqlmock.NewRows(revisionColumns).
+		AddRow("revision-3", testInvitationID, 3, []byte(`{"couple":{"brideName":"Rina","groomName":"Bayu"}}`), model.RevisionAuthorCustomer, testCustomerID, nil))
+	mock.ExpectCommit()
+
+	input := updateInput(`{"couple": {"groomName": "Bayu", "brideName": "Rina"}}`)
+	input.KeepRevisions = 5
+	if err := repo.Update(context.Background(), testInvitationID, input); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+func TestUpdateAppendsAndPrunesRevisions(t *testing.T) {
+	db, mock := testdb.New(t)
+	repo := &InvitationRepository{DB: db}
+	raw := `{"couple": {"groomName": "Bayu Aji"}}`
+
+	expectDraftUpdate(mock, sqlmock.NewRows(revisionColumns).
+		AddRow("revision-5", testInvitationID, 5, []byte(`{"couple": {"groomName": "Bayu"}}`), model.RevisionAuthorCustomer, testCustomerID, nil))
+	expectRevisionInsert(mock, 6, raw, model.RevisionAuthorCustomer, testCustomerID, nil)
+	// Keeping three revisions leaves versions 4 to 6.
+	mock.ExpectExec(`DELETE FROM "invitation_revisions" WHERE invitation_id = \$1 AND version <= \$2`).
+		WithArgs(testInvitationID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	input := updateInput(raw)
+	input.KeepRevisions = 3
+	if err := repo.Update(context.Background(), testInvitationID, input); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+func TestUpdateStartsHistoryOfOlderInvitations(t *testing.T) {
+	db, mock := testdb.New(t)
+	repo := &InvitationRepository{DB: db}
+	raw := `{"couple": {"groomName": "Bayu Aji"}}`
+
+	// Without revisions, the stored content becomes version 1 before the edit is recorded.
+	expectDraftUpdate(mock, sqlmock.NewRows(revisionColumns))
+	expectRevisionInsert(mock, 1, `{"couple": {"groomName": "Bayu"}}`, model.RevisionAuthorSystem, "", nil)
+	expectRevisionInsert(mock, 2, raw, model.RevisionAuthorCustomer, testCustomerID, nil)
+	mock.ExpectCommit()
+
+	// Without a retention limit nothing is pruned.
+	if err := repo.Update(context.Background(), testInvitationID, updateInput(raw)); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+func TestUpdateRecordsRestore(t *testing.T) {
+	db, mock := testdb.New(t)
+	repo := &InvitationRepository{DB: db}
+	raw := `{"couple": {"groomName": "Bayu"}}`
+
+	// Restoring version 2 when version 4 already holds the same content is still recorded, as
+	// version 5, so the history shows the rollback.
+	expectDraftUpdate(mock, sqlmock.NewRows(revisionColumns).
+		AddRow("revision-4", testInvitationID, 4, []byte(raw), model.RevisionAuthorCustomer, testCustomerID, nil))
+	expectRevisionInsert(mock, 5, raw, model.RevisionAuthorCustomer, testCustomerID, 2)
+	mock.ExpectExec(`DELETE FROM "invitation_revisions" WHERE invitation_id = \$1 AND version <= \$2`).
+		WithArgs(testInvitationID, 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	restoredFrom := 2
+	input := updateInput(raw)
+	input.RestoredFrom = &restoredFrom
+	input.KeepRevisions = 5
+	if err := repo.Update(context.Background(), testInvitationID, input); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern builds an ILIKE pattern matching text anywhere, with the wildcards it contains
+// taken literally.
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	DB *gorm.DB
+}
+
+type MediaCreateInput struct {
+	CustomerID   string
+	Kind         string
+	ContentType  string
+	SizeBytes    int64
+	StorageKey   string
+	URL          string
+	OriginalName string
+	// ProcessingStatus is pending for photos that still need their variants.
+	ProcessingStatus string
+}
+
+func (r *MediaRepository) Create(ctx context.Context, input MediaCreateInput) (model.Media, error) {
+	item := model.Media{
+		CustomerID:       input.CustomerID,
+		Kind:             input.Kind,
+		ContentType:      input.ContentType,
+		SizeBytes:        input.SizeBytes,
+		StorageKey:       input.StorageKey,
+		URL:              input.URL,
+		OriginalName:     input.OriginalName,
+		Variants:         []byte("[]"),
+		ProcessingStatus: input.ProcessingStatus,
+	}
+	if err := r.DB.WithContext(ctx).Model(&model.Media{}).Create(&item).Error; err != nil {
+		return model.Media{}, err
+	}
+	return item, nil
+}
+
+func (r *MediaRepository) GetByIDAndCustomer(ctx context.Context, id, customerID string) (model.Media, bool, error) {
+	var item model.Media
+	err := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("id = ? AND customer_id = ?", id, customerID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Media{}, false, nil
+	}
+	if err != nil {
+		return model.Media{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *MediaRepository) ListByCustomer(ctx context.Context, customerID string) ([]model.Media, error) {
+	items := make([]model.Media, 0)
+	err := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// OwnedKeys returns which of the given storage keys belong to the customer.
+func (r *MediaRepository) OwnedKeys(ctx context.Context, customerID string, keys []string) (map[string]struct{}, error) {
+	owned := make(map[string]struct{}, len(keys))
+	if len(keys) == 0 {
+		return owned, nil
+	}
+
+	found := make([]string, 0, len(keys))
+	err := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("customer_id = ? AND storage_key IN ?", customerID, keys).
+		Pluck("storage_key", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range found {
+		owned[key] = struct{}{}
+	}
+	return owned, nil
+}
+
+// FindReadyByURLs returns the customer's processed photos among the given URLs.
+func (r *MediaRepository) FindReadyByURLs(ctx context.Context, customerID string, urls []string) ([]model.Media, error) {
+	items := make([]model.Media, 0, len(urls))
+	if len(urls) == 0 {
+		return items, nil
+	}
+	err := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("customer_id = ? AND url IN ? AND processing_status = ?", customerID, urls, model.MediaStatusReady).
+		Find(&items).Error
+	return items, err
+}
+
+// Claim marks a photo as being processed and returns it. A photo already claimed by another
+// worker is only taken over once its claim is older than staleBefore, so a crashed worker does
+// not leave it pending forever.
+func (r *MediaRepository) Claim(ctx context.Context, id string, staleBefore time.Time) (model.Media, bool, error) {
+	items := make([]model.Media, 0, 1)
+	err := r.DB.WithContext(ctx).Raw(`
+		UPDATE media
+		SET processing_status = ?, processing_started_at = now()
+		WHERE id = ? AND kind = ?
+		  AND (processing_status = ? OR (processing_status = ? AND processing_started_at < ?))
+		RETURNING *`,
+		model.MediaStatusProcessing, id, model.MediaKindImage,
+		model.MediaStatusPending, model.MediaStatusProcessing, staleBefore).
+		Scan(&items).Error
+	if err != nil || len(items) == 0 {
+		return model.Media{}, false, err
+	}
+	return items[0], true, nil
+}
+
+// ListUnprocessedIDs returns photos still waiting for a worker, oldest first.
+func (r *MediaRepository) ListUnprocessedIDs(ctx context.Context, staleBefore time.Time, limit int) ([]string, error) {
+	ids := make([]string, 0)
+	err := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("kind = ?", model.MediaKindImage).
+		Where("processing_status = ? OR (processing_status = ? AND processing_started_at < ?)",
+			model.MediaStatusPending, model.MediaStatusProcessing, staleBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// MarkProcessed stores the variants of a photo. It reports false when the photo was deleted while
+// it was being processed.
+func (r *MediaRepository) MarkProcessed(ctx context.Context, id string, width, height int, variants []byte) (bool, error) {
+	result := r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"width":             width,
+			"height":            height,
+			"variants":          variants,
+			"processing_status": model.MediaStatusReady,
+			"processing_error":  "",
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *MediaRepository) MarkFailed(ctx context.Context, id, reason string) error {
+	return r.DB.WithContext(ctx).
+		Model(&model.Media{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"processing_status": model.MediaStatusFailed,
+			"processing_error":  reason,
+		}).Error
+}
+
+// Delete removes a media row and returns it as it was at deletion, so the caller sees variants a
+// worker stored after the row was last read.
+func (r *MediaRepository) Delete(ctx context.Context, id string) (model.Media, bool, error) {
+	items := make([]model.Media, 0, 1)
+	err := r.DB.WithContext(ctx).Raw(`DELETE FROM media WHERE id = ? RETURNING *`, id).Scan(&items).Error
+	if err != nil || len(items) == 0 {
+		return model.Media{}, false, err
+	}
+	return items[0], true, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -15,13 +16,35 @@ type PaymentRepository struct {
 }
 
 type PaymentCreateInput struct {
-	CustomerID     string
-	PlanID         string
-	Amount         int
-	Currency       string
-	ProofOfPayment string
-	Status         string
-	PaidAt         *time.Time
+	CustomerID            string
+	PlanID                string
+	Amount                int
+	Currency              string
+	ProofOfPayment        string
+	Status                string
+	Provider              string
+	ProviderOrderID       string
+	ProviderTransactionID string
+	RedirectURL           string
+	PaidAt                *time.Time
+}
+
+// ProviderStatusUpdate is a status reported by the payment gateway. Empty TransactionID and
+// PaymentType keep the stored values, since not every notification carries them.
+type ProviderStatusUpdate struct {
+	Status        string
+	PaidAt        *time.Time
+	RawStatus     string
+	TransactionID string
+	PaymentType   string
+}
+
+// PaymentReviewInput records who reviewed a manual transfer; the decision itself is a status
+// change.
+type PaymentReviewInput struct {
+	ReviewerID string
+	Note       string
+	At         time.Time
 }
 
 type AdminPaymentFilters struct {
@@ -42,28 +65,38 @@ type AdminPaymentRow struct {
 	Amount        int        `gorm:"column:amount"`
 	Currency      string     `gorm:"column:currency"`
 	Status        string     `gorm:"column:status"`
+	Provider      string     `gorm:"column:provider"`
+	ReviewNote    string     `gorm:"column:review_note"`
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at"`
 	PaidAt        *time.Time `gorm:"column:paid_at"`
 	CreatedAt     time.Time  `gorm:"column:created_at"`
 	UpdatedAt     time.Time  `gorm:"column:updated_at"`
 }
 
 type AdminPaymentSummary struct {
-	TotalRevenue  int64
-	PaidCount     int64
-	PendingCount  int64
-	FailedCount   int64
-	RefundedCount int64
+	TotalRevenue        int64
+	PaidCount           int64
+	PendingCount        int64
+	AwaitingReviewCount int64
+	FailedCount         int64
+	RefundedCount       int64
 }
 
 func (r *PaymentRepository) Create(ctx context.Context, input PaymentCreateInput) (string, error) {
 	payment := model.Payment{
-		CustomerID:     input.CustomerID,
-		PlanID:         input.PlanID,
-		Amount:         input.Amount,
-		Currency:       input.Currency,
-		ProofOfPayment: input.ProofOfPayment,
-		Status:         input.Status,
-		PaidAt:         input.PaidAt,
+		CustomerID:            input.CustomerID,
+		PlanID:                input.PlanID,
+		Amount:                input.Amount,
+		Currency:              input.Currency,
+		ProofOfPayment:        input.ProofOfPayment,
+		Status:                input.Status,
+		Provider:              input.Provider,
+		ProviderTransactionID: input.ProviderTransactionID,
+		RedirectURL:           input.RedirectURL,
+		PaidAt:                input.PaidAt,
+	}
+	if orderID := strings.TrimSpace(input.ProviderOrderID); orderID != "" {
+		payment.ProviderOrderID = &orderID
 	}
 	if err := r.DB.WithContext(ctx).Model(&model.Payment{}).Create(&payment).Error; err != nil {
 		return "", err
@@ -71,6 +104,21 @@ func (r *PaymentRepository) Create(ctx context.Context, input PaymentCreateInput
 	return payment.ID, nil
 }
 
+func (r *PaymentRepository) GetByID(ctx context.Context, paymentID string) (model.Payment, bool, error) {
+	var payment model.Payment
+	err := r.DB.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ?", paymentID).
+		First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, false, nil
+	}
+	if err != nil {
+		return model.Payment{}, false, err
+	}
+	return payment, true, nil
+}
+
 func (r *PaymentRepository) GetByIDAndCustomer(ctx context.Context, paymentID, customerID string) (model.Payment, bool, error) {
 	var payment model.Payment
 	err := r.DB.WithContext(ctx).
@@ -102,7 +150,7 @@ func (r *PaymentRepository) GetLatestByCustomer(ctx context.Context, customerID
 	return payment, true, nil
 }
 
-func (r *PaymentRepository) GetByMidtransOrderID(ctx context.Context, orderID string) (model.Payment, bool, error) {
+func (r *PaymentRepository) GetByProviderOrderID(ctx context.Context, provider, orderID string) (model.Payment, bool, error) {
 	orderID = strings.TrimSpace(orderID)
 	if orderID == "" {
 		return model.Payment{}, false, nil
@@ -111,13 +159,7 @@ func (r *PaymentRepository) GetByMidtransOrderID(ctx context.Context, orderID st
 	var payment model.Payment
 	err := r.DB.WithContext(ctx).
 		Model(&model.Payment{}).
-		Where(
-			"(CASE WHEN proof_of_payment LIKE '{%' THEN proof_of_payment::jsonb ->> 'order_id' ELSE NULL END) = ? OR proof_of_payment = ? OR proof_of_payment = ?",
-			orderID,
-			orderID,
-			"midtrans:"+orderID,
-		).
-		Order("created_at DESC").
+		Where("provider = ? AND provider_order_id = ?", provider, orderID).
 		First(&payment).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return model.Payment{}, false, nil
@@ -142,10 +184,96 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID, status
 		Updates(updates).Error
 }
 
+// GetByIDForUpdateTx locks the payment for the rest of the transaction, so status changes to it
+// are applied one at a time.
+func (r *PaymentRepository) GetByIDForUpdateTx(ctx context.Context, tx *gorm.DB, paymentID string) (model.Payment, bool, error) {
+	var payment model.Payment
+	err := tx.WithContext(ctx).
+		Model(&model.Payment{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", paymentID).
+		First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, false, nil
+	}
+	if err != nil {
+		return model.Payment{}, false, err
+	}
+	return payment, true, nil
+}
+
+func (r *PaymentRepository) UpdateProviderStatusTx(ctx context.Context, tx *gorm.DB, paymentID string, update ProviderStatusUpdate) error {
+	updates := map[string]any{
+		"status":     update.Status,
+		"raw_status": update.RawStatus,
+	}
+	if update.PaidAt != nil {
+		updates["paid_at"] = *update.PaidAt
+	}
+	if update.TransactionID != "" {
+		updates["provider_transaction_id"] = update.TransactionID
+	}
+	if update.PaymentType != "" {
+		updates["payment_type"] = update.PaymentType
+	}
+
+	return tx.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ?", paymentID).
+		Updates(updates).Error
+}
+
+func (r *PaymentRepository) ReviewTx(ctx context.Context, tx *gorm.DB, paymentID string, input PaymentReviewInput) error {
+	return tx.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("id = ?", paymentID).
+		Updates(map[string]any{
+			"reviewed_by": input.ReviewerID,
+			"reviewed_at": input.At,
+			"review_note": input.Note,
+		}).Error
+}
+
+// CountPaidByCustomerTx counts the customer's paid payments other than excludeID.
+func (r *PaymentRepository) CountPaidByCustomerTx(ctx context.Context, tx *gorm.DB, customerID, excludeID string) (int64, error) {
+	var count int64
+	err := tx.WithContext(ctx).
+		Model(&model.Payment{}).
+		Where("customer_id = ? AND status = 'paid' AND id <> ?", customerID, excludeID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateEventTx records a payment event. It returns false without inserting when an event with
+// the same provider and key was already recorded.
+func (r *PaymentRepository) CreateEventTx(ctx context.Context, tx *gorm.DB, event model.PaymentEvent) (bool, error) {
+	result := tx.WithContext(ctx).
+		Model(&model.PaymentEvent{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "event_key"}},
+			DoNothing: true,
+		}).
+		Create(&event)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) ListEvents(ctx context.Context, paymentID string) ([]model.PaymentEvent, error) {
+	events := make([]model.PaymentEvent, 0)
+	err := r.DB.WithContext(ctx).
+		Model(&model.PaymentEvent{}).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
 func (r *PaymentRepository) ListAdmin(ctx context.Context, filters AdminPaymentFilters) ([]AdminPaymentRow, error) {
 	query := r.DB.WithContext(ctx).
 		Table("payments").
-		Select("payments.id, payments.customer_id, customers.full_name as customer_name, customers.email as customer_email, payments.plan_id, plans.code as plan_code, plans.name as plan_name, payments.amount, payments.currency, payments.status, payments.paid_at, payments.created_at, payments.updated_at").
+		Select("payments.id, payments.customer_id, customers.full_name as customer_name, customers.email as customer_email, payments.plan_id, plans.code as plan_code, plans.name as plan_name, payments.amount, payments.currency, payments.status, payments.provider, payments.review_note, payments.reviewed_at, payments.paid_at, payments.created_at, payments.updated_at").
 		Joins("JOIN customers ON customers.id = payments.customer_id").
 		Joins("JOIN plans ON plans.id = payments.plan_id")
 
@@ -202,6 +330,7 @@ func (r *PaymentRepository) SummaryAdmin(ctx context.Context) (AdminPaymentSumma
 			"COALESCE(SUM(CASE WHEN status = 'paid' THEN amount ELSE 0 END), 0) as total_revenue, " +
 				"SUM(CASE WHEN status = 'paid' THEN 1 ELSE 0 END) as paid_count, " +
 				"SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) as pending_count, " +
+				"SUM(CASE WHEN status = 'awaiting_review' THEN 1 ELSE 0 END) as awaiting_review_count, " +
 				"SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as failed_count, " +
 				"SUM(CASE WHEN status = 'refunded' THEN 1 ELSE 0 END) as refunded_count",
 		).
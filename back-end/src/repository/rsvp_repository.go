@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"strings"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RsvpRepository struct {
@@ -13,22 +16,229 @@ type RsvpRepository struct {
 
 type CreateRsvpInput struct {
 	InvitationID string
+	GuestID      *string
+	IdentityKey  string
 	GuestName    string
 	Attendance   string
 	GuestsCount  int
 	Message      string
 }
 
+type UpdateRsvpInput struct {
+	GuestID     *string
+	GuestName   string
+	Attendance  string
+	GuestsCount int
+	Message     string
+}
+
+type RsvpListFilters struct {
+	InvitationID string
+	Attendance   string
+	Query        string
+	Limit        int
+	Offset       int
+}
+
+type RsvpSummary struct {
+	TotalResponses    int64 `gorm:"column:total_responses"`
+	AttendingCount    int64 `gorm:"column:attending_count"`
+	NotAttendingCount int64 `gorm:"column:not_attending_count"`
+	TotalGuests       int64 `gorm:"column:total_guests"`
+}
+
 func (r *RsvpRepository) Create(ctx context.Context, input CreateRsvpInput) (model.RSVP, error) {
-	item := model.RSVP{
+	item := newRsvp(input)
+	if err := r.DB.WithContext(ctx).Model(&model.RSVP{}).Create(&item).Error; err != nil {
+		return model.RSVP{}, err
+	}
+	return item, nil
+}
+
+// CreateIfAbsentTx inserts the RSVP unless one with the same identity already exists for the
+// invitation, in which case it returns false.
+func (r *RsvpRepository) CreateIfAbsentTx(ctx context.Context, tx *gorm.DB, input CreateRsvpInput) (model.RSVP, bool, error) {
+	item := newRsvp(input)
+	result := tx.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "invitation_id"}, {Name: "identity_key"}},
+			DoNothing: true,
+		}).
+		Create(&item)
+	if result.Error != nil {
+		return model.RSVP{}, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.RSVP{}, false, nil
+	}
+	return item, true, nil
+}
+
+// FindByIdentityForUpdateTx locks the RSVP of one identity for the rest of the transaction.
+func (r *RsvpRepository) FindByIdentityForUpdateTx(ctx context.Context, tx *gorm.DB, invitationID, identityKey string) (model.RSVP, bool, error) {
+	var item model.RSVP
+	err := tx.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("invitation_id = ? AND identity_key = ?", invitationID, identityKey).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.RSVP{}, false, nil
+	}
+	if err != nil {
+		return model.RSVP{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *RsvpRepository) UpdateTx(ctx context.Context, tx *gorm.DB, id string, input UpdateRsvpInput) error {
+	return tx.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"guest_id":     input.GuestID,
+			"guest_name":   input.GuestName,
+			"attendance":   input.Attendance,
+			"guests_count": input.GuestsCount,
+			"message":      input.Message,
+			"updated_at":   gorm.Expr("now()"),
+		}).Error
+}
+
+// CreateRevisionTx archives the current answer of an RSVP before it is overwritten.
+func (r *RsvpRepository) CreateRevisionTx(ctx context.Context, tx *gorm.DB, rsvp model.RSVP) error {
+	revision := model.RSVPRevision{
+		RsvpID:      rsvp.ID,
+		GuestName:   rsvp.GuestName,
+		Attendance:  rsvp.Attendance,
+		GuestsCount: rsvp.GuestsCount,
+		Message:     rsvp.Message,
+		AnsweredAt:  rsvp.UpdatedAt,
+	}
+	return tx.WithContext(ctx).Model(&model.RSVPRevision{}).Create(&revision).Error
+}
+
+func newRsvp(input CreateRsvpInput) model.RSVP {
+	return model.RSVP{
 		InvitationID: input.InvitationID,
+		GuestID:      input.GuestID,
+		IdentityKey:  input.IdentityKey,
 		GuestName:    input.GuestName,
 		Attendance:   input.Attendance,
 		GuestsCount:  input.GuestsCount,
 		Message:      input.Message,
 	}
-	if err := r.DB.WithContext(ctx).Model(&model.RSVP{}).Create(&item).Error; err != nil {
-		return model.RSVP{}, err
+}
+
+func (r *RsvpRepository) filteredQuery(ctx context.Context, filters RsvpListFilters) *gorm.DB {
+	query := r.DB.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Where("invitation_id = ?", filters.InvitationID)
+
+	if attendance := strings.TrimSpace(filters.Attendance); attendance != "" {
+		query = query.Where("attendance = ?", attendance)
 	}
-	return item, nil
+	if q := strings.TrimSpace(filters.Query); q != "" {
+		query = query.Where("guest_name ILIKE ?", containsPattern(q))
+	}
+	return query
+}
+
+func (r *RsvpRepository) ListByInvitationID(ctx context.Context, filters RsvpListFilters) ([]model.RSVP, int64, error) {
+	var total int64
+	if err := r.filteredQuery(ctx, filters).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]model.RSVP, 0)
+	err := r.filteredQuery(ctx, filters).
+		Order("updated_at DESC").
+		Limit(filters.Limit).
+		Offset(filters.Offset).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// EachByInvitationID streams every RSVP matching the filters, oldest first, ignoring Limit and Offset.
+func (r *RsvpRepository) EachByInvitationID(ctx context.Context, filters RsvpListFilters, fn func(model.RSVP) error) error {
+	rows, err := r.filteredQuery(ctx, filters).Order("created_at ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.RSVP
+		if err := r.DB.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CountByInvitationIDTx counts the RSVPs of the invitation, including rows the transaction has
+// just inserted.
+func (r *RsvpRepository) CountByInvitationIDTx(ctx context.Context, tx *gorm.DB, invitationID string) (int64, error) {
+	var total int64
+	err := tx.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Where("invitation_id = ?", invitationID).
+		Count(&total).Error
+	return total, err
+}
+
+// CountByInvitationIDs counts the RSVPs of each of the given invitations.
+func (r *RsvpRepository) CountByInvitationIDs(ctx context.Context, invitationIDs []string) (map[string]int64, error) {
+	return countByInvitation(r.DB.WithContext(ctx).Model(&model.RSVP{}), invitationIDs)
+}
+
+func (r *RsvpRepository) SummaryByInvitationID(ctx context.Context, invitationID string) (RsvpSummary, error) {
+	summary := RsvpSummary{}
+	err := r.DB.WithContext(ctx).
+		Model(&model.RSVP{}).
+		Select(
+			"COUNT(*) as total_responses, "+
+				"COALESCE(SUM(CASE WHEN attendance = 'attending' THEN 1 ELSE 0 END), 0) as attending_count, "+
+				"COALESCE(SUM(CASE WHEN attendance = 'not_attending' THEN 1 ELSE 0 END), 0) as not_attending_count, "+
+				"COALESCE(SUM(CASE WHEN attendance = 'attending' THEN guests_count ELSE 0 END), 0) as total_guests",
+		).
+		Where("invitation_id = ?", invitationID).
+		Scan(&summary).Error
+	if err != nil {
+		return RsvpSummary{}, err
+	}
+	return summary, nil
+}
+
+// countByInvitation groups the rows of query by invitation_id. Invitations without rows are
+// left out of the result.
+func countByInvitation(query *gorm.DB, invitationIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(invitationIDs))
+	if len(invitationIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		InvitationID string `gorm:"column:invitation_id"`
+		Total        int64  `gorm:"column:total"`
+	}
+	err := query.
+		Select("invitation_id, COUNT(*) as total").
+		Where("invitation_id IN ?", invitationIDs).
+		Group("invitation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.InvitationID] = row.Total
+	}
+	return counts, nil
 }
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openSchemaDB applies schema.sql to a new Postgres schema of TEST_DATABASE_URL, which is dropped
+// after the test. Tests that need a real database are skipped without it.
+func openSchemaDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	conn, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// search_path is per connection.
+	conn.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("schema_test_%d", time.Now().UnixNano())
+	if _, err := conn.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = conn.Exec("DROP SCHEMA " + schema + " CASCADE")
+		_ = conn.Close()
+	})
+	if _, err := conn.Exec("SET search_path TO " + schema + ", public"); err != nil {
+		t.Fatal(err)
+	}
+
+	applySchema(t, conn)
+	return conn
+}
+
+// applySchema runs schema.sql, which is written to be applied again on every deploy.
+func applySchema(t *testing.T, conn *sql.DB) {
+	t.Helper()
+	raw, err := os.ReadFile("../../schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(string(raw)); err != nil {
+		t.Fatalf("apply schema.sql: %v", err)
+	}
+}
+
+type schemaPayment struct {
+	provider    string
+	orderID     sql.NullString
+	redirectURL string
+	proof       sql.NullString
+}
+
+func TestSchemaMigratesLegacyPayments(t *testing.T) {
+	conn := openSchemaDB(t)
+
+	var customerID, planID string
+	if err := conn.QueryRow(`INSERT INTO customers (full_name, email, password_hash, domain)
+		VALUES ('Rina', 'rina@example.com', 'x', 'rina') RETURNING id`).Scan(&customerID); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRow(`SELECT id FROM plans WHERE code = 'premium'`).Scan(&planID); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(provider, orderID, proof string) string {
+		t.Helper()
+		var id string
+		err := conn.QueryRow(`INSERT INTO payments (customer_id, plan_id, amount, provider, provider_order_id, proof_of_payment)
+			VALUES ($1, $2, 99000, $3, NULLIF($4, ''), NULLIF($5, '')) RETURNING id`,
+			customerID, planID, provider, orderID, proof).Scan(&id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	jsonProof := insert("", "", `{"order_id": "ORDER-1", "redirect_url": "https://pay.example/1"}`)
+	prefixed := insert("", "", "midtrans:ORDER-2")
+	broken := insert("", "", `{"order_id": "ORDER-3"`)
+	// Another gateway may use the same order id.
+	insert("xendit", "ORDER-1", "")
+	// A manual transfer keeps its receipt key in proof_of_payment and has no order id.
+	receiptKey := "receipts/" + customerID + "/transfer.jpg"
+	manual := insert("manual_transfer", "", receiptKey)
+
+	applySchema(t, conn)
+
+	get := func(id string) schemaPayment {
+		t.Helper()
+		var p schemaPayment
+		err := conn.QueryRow(`SELECT provider, provider_order_id, redirect_url, proof_of_payment FROM payments WHERE id = $1`, id).
+			Scan(&p.provider, &p.orderID, &p.redirectURL, &p.proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	if p := get(jsonProof); p.provider != "midtrans" || p.orderID.String != "ORDER-1" || p.redirectURL != "https://pay.example/1" || p.proof.Valid {
+		t.Errorf("JSON proof migrated to %+v", p)
+	}
+	if p := get(prefixed); p.provider != "midtrans" || p.orderID.String != "ORDER-2" || p.proof.Valid {
+		t.Errorf("prefixed proof migrated to %+v", p)
+	}
+	if p := get(broken); p.provider != "" || p.orderID.Valid || p.proof.String != `{"order_id": "ORDER-3"` {
+		t.Errorf("malformed proof changed to %+v", p)
+	}
+	if p := get(manual); p.provider != "manual_transfer" || p.orderID.Valid || p.proof.String != receiptKey {
+		t.Errorf("manual transfer changed to %+v", p)
+	}
+
+	// Applying the schema again changes nothing.
+	applySchema(t, conn)
+	if p := get(broken); p.proof.String != `{"order_id": "ORDER-3"` {
+		t.Errorf("second run changed the malformed proof to %+v", p)
+	}
+	if p := get(manual); p.provider != "manual_transfer" || p.proof.String != receiptKey {
+		t.Errorf("second run changed the manual transfer to %+v", p)
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/cache"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+const (
+	DefaultTenantCacheSize = 1000
+	DefaultTenantCacheTTL  = 30 * time.Second
+)
+
+// TenantCache keeps the lookups every public request makes, host to customer and customer plus
+// slug to published invitation, so a popular invitation is not read from the database on each
+// visit. Misses are cached too, which keeps unknown hosts cheap. Successful writes through the
+// repositories invalidate affected entries on this instance; writes made in a caller's
+// transaction are invalidated by the caller once it commits. Other instances catch up when the
+// TTL expires. A nil *TenantCache disables caching.
+type TenantCache struct {
+	customers   *cache.LRU[string, cachedCustomer]
+	invitations *cache.LRU[invitationKey, cachedInvitation]
+
+	// Every invalidation starts a new generation, and a lookup that began in an older one is not
+	// stored: it may have read the row before the write behind the invalidation committed. mu
+	// keeps a fill from landing between the deletes and the bump.
+	mu            sync.Mutex
+	customerGen   uint64
+	invitationGen uint64
+}
+
+type cachedCustomer struct {
+	customer model.Customer
+	found    bool
+}
+
+type invitationKey struct {
+	customerID string
+	slug       string
+}
+
+type cachedInvitation struct {
+	invitation model.Invitation
+	found      bool
+}
+
+// TenantCacheStats reports hits and misses of both lookups.
+type TenantCacheStats struct {
+	Customers   cache.Stats `json:"customers"`
+	Invitations cache.Stats `json:"invitations"`
+}
+
+func NewTenantCache(size int, ttl time.Duration) *TenantCache {
+	return &TenantCache{
+		customers:   cache.NewLRU[string, cachedCustomer](size, ttl),
+		invitations: cache.NewLRU[invitationKey, cachedInvitation](size, ttl),
+	}
+}
+
+func (t *TenantCache) Stats() TenantCacheStats {
+	if t == nil {
+		return TenantCacheStats{}
+	}
+	return TenantCacheStats{
+		Customers:   t.customers.Stats(),
+		Invitations: t.invitations.Stats(),
+	}
+}
+
+// customer returns the cached lookup, or the generation to pass to putCustomer on a miss.
+func (t *TenantCache) customer(key string) (cachedCustomer, uint64, bool) {
+	if t == nil {
+		return cachedCustomer{}, 0, false
+	}
+	t.mu.Lock()
+	gen := t.customerGen
+	t.mu.Unlock()
+
+	cached, ok := t.customers.Get(key)
+	return cached, gen, ok
+}
+
+func (t *TenantCache) putCustomer(gen uint64, key string, customer model.Customer, found bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if gen == t.customerGen {
+		t.customers.Set(key, cachedCustomer{customer: customer, found: found})
+	}
+}
+
+// forgetCustomer drops the entries of the customer and every cached miss, since a changed domain
+// may now match a host that was unknown before.
+func (t *TenantCache) forgetCustomer(id string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.customers.DeleteFunc(func(_ string, entry cachedCustomer) bool {
+		return !entry.found || entry.customer.ID == id
+	})
+	t.customerGen++
+}
+
+func (t *TenantCache) forgetHosts() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.customers.Purge()
+	t.customerGen++
+}
+
+// invitation returns the cached lookup, or the generation to pass to putInvitation on a miss.
+func (t *TenantCache) invitation(customerID, slug string) (cachedInvitation, uint64, bool) {
+	if t == nil {
+		return cachedInvitation{}, 0, false
+	}
+	t.mu.Lock()
+	gen := t.invitationGen
+	t.mu.Unlock()
+
+	cached, ok := t.invitations.Get(invitationKey{customerID: customerID, slug: slug})
+	return cached, gen, ok
+}
+
+func (t *TenantCache) putInvitation(gen uint64, customerID, slug string, invitation model.Invitation, found bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if gen == t.invitationGen {
+		t.invitations.Set(invitationKey{customerID: customerID, slug: slug}, cachedInvitation{invitation: invitation, found: found})
+	}
+}
+
+// forgetInvitations drops the given invitations and every cached miss, since a publish or slug
+// change can make a missing invitation appear.
+func (t *TenantCache) forgetInvitations(ids ...string) {
+	if t == nil || len(ids) == 0 {
+		return
+	}
+	drop := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		drop[id] = struct{}{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.invitations.DeleteFunc(func(_ invitationKey, entry cachedInvitation) bool {
+		_, ok := drop[entry.invitation.ID]
+		return ok || !entry.found
+	})
+	t.invitationGen++
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+const testCustomerID = "5b0c1f8e-3d4a-4c7b-9e2f-1a2b3c4d5e6f"
+
+func TestTenantCacheDropsStaleFill(t *testing.T) {
+	tenants := NewTenantCache(10, time.Minute)
+
+	// A lookup misses and reads the row, then a write commits and invalidates before the lookup
+	// stores what it read.
+	_, gen, ok := tenants.customer("domain:rina")
+	if ok {
+		t.Fatal("empty cache had an entry")
+	}
+	tenants.forgetCustomer(testCustomerID)
+	tenants.putCustomer(gen, "domain:rina", model.Customer{ID: testCustomerID, Status: "pending"}, true)
+	if _, _, ok := tenants.customer("domain:rina"); ok {
+		t.Fatal("a fill from before the invalidation was cached")
+	}
+
+	// A lookup that starts after the invalidation is cached as usual.
+	_, gen, _ = tenants.customer("domain:rina")
+	tenants.putCustomer(gen, "domain:rina", model.Customer{ID: testCustomerID, Status: "paid"}, true)
+	if cached, _, ok := tenants.customer("domain:rina"); !ok || cached.customer.Status != "paid" {
+		t.Fatalf("fresh fill not cached: %+v, %v", cached, ok)
+	}
+
+	_, gen, _ = tenants.invitation(testCustomerID, "rina-dan-bayu")
+	tenants.forgetInvitations("some-other-invitation")
+	tenants.putInvitation(gen, testCustomerID, "rina-dan-bayu", model.Invitation{}, false)
+	if _, _, ok := tenants.invitation(testCustomerID, "rina-dan-bayu"); ok {
+		t.Fatal("an invitation fill from before the invalidation was cached")
+	}
+}
+
+func TestTenantCacheNil(t *testing.T) {
+	var tenants *TenantCache
+	_, gen, ok := tenants.customer("domain:rina")
+	tenants.putCustomer(gen, "domain:rina", model.Customer{}, false)
+	tenants.forgetCustomer(testCustomerID)
+	tenants.forgetHosts()
+	tenants.forgetInvitations("x")
+	if ok || tenants.Stats() != (TenantCacheStats{}) {
+		t.Fatal("nil cache reported entries")
+	}
+}
+
+func TestCustomerRepositoryInvalidatesOnlyAfterSuccess(t *testing.T) {
+	db, mock := testdb.New(t)
+	tenants := NewTenantCache(10, time.Minute)
+	repo := &CustomerRepository{DB: db, Cache: tenants}
+	ctx := context.Background()
+
+	customerColumns := []string{"id", "domain", "status"}
+	mock.ExpectQuery(`SELECT \* FROM "customers" WHERE domain = \$1`).
+		WithArgs("rina", 1).
+		WillReturnRows(sqlmock.NewRows(customerColumns).AddRow(testCustomerID, "rina", "pending"))
+	if _, found, err := repo.FindByDomain(ctx, "rina"); err != nil || !found {
+		t.Fatalf("FindByDomain = %v, %v", found, err)
+	}
+
+	// A failed write changed nothing, so the entry stays.
+	mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).WillReturnError(errors.New("connection reset"))
+	if err := repo.UpdateStatus(ctx, testCustomerID, "paid"); err == nil {
+		t.Fatal("UpdateStatus succeeded against a failing database")
+	}
+	if _, _, ok := tenants.customer("domain:rina"); !ok {
+		t.Fatal("a failed write invalidated the cache")
+	}
+
+	// Inside a transaction the caller invalidates after commit.
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := repo.UpdateStatusTx(ctx, tx, testCustomerID, "paid"); err != nil {
+			return err
+		}
+		if _, _, ok := tenants.customer("domain:rina"); !ok {
+			t.Error("UpdateStatusTx invalidated before the commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	repo.ForgetCached(testCustomerID)
+	if _, _, ok := tenants.customer("domain:rina"); ok {
+		t.Fatal("ForgetCached kept the customer")
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "customers" WHERE domain = \$1`).
+		WithArgs("rina", 1).
+		WillReturnRows(sqlmock.NewRows(customerColumns).AddRow(testCustomerID, "rina", "paid"))
+	if _, _, err := repo.FindByDomain(ctx, "rina"); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.UpdateStatus(ctx, testCustomerID, "pending"); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if _, _, ok := tenants.customer("domain:rina"); ok {
+		t.Fatal("a successful write kept the stale entry")
+	}
+}
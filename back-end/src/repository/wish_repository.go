@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
 	"gorm.io/gorm"
 )
 
@@ -13,39 +16,226 @@ type WishRepository struct {
 
 type CreateWishInput struct {
 	InvitationID string
+	RsvpID       *string
 	GuestName    string
 	Message      string
+	Status       string
+	FlagReason   string
+}
+
+type WishListFilters struct {
+	InvitationID string
+	Status       string
+	Limit        int
+	Offset       int
+}
+
+// WishModerationInput changes only the fields that are set.
+type WishModerationInput struct {
+	Status *string
+	Pinned *bool
 }
 
 func (r *WishRepository) Create(ctx context.Context, input CreateWishInput) (model.Wish, error) {
+	return r.createWithDB(ctx, r.DB, input)
+}
+
+func (r *WishRepository) CreateTx(ctx context.Context, tx *gorm.DB, input CreateWishInput) (model.Wish, error) {
+	return r.createWithDB(ctx, tx, input)
+}
+
+func (r *WishRepository) createWithDB(ctx context.Context, db *gorm.DB, input CreateWishInput) (model.Wish, error) {
 	item := model.Wish{
 		InvitationID: input.InvitationID,
+		RsvpID:       input.RsvpID,
 		GuestName:    input.GuestName,
 		Message:      input.Message,
+		Status:       input.Status,
+		FlagReason:   input.FlagReason,
 	}
-	if err := r.DB.WithContext(ctx).Model(&model.Wish{}).Create(&item).Error; err != nil {
+	if item.Status == "" {
+		item.Status = model.WishStatusApproved
+	}
+	if err := db.WithContext(ctx).Model(&model.Wish{}).Create(&item).Error; err != nil {
 		return model.Wish{}, err
 	}
 	return item, nil
 }
 
-func (r *WishRepository) ListByInvitationID(ctx context.Context, invitationID string, limit int) ([]model.Wish, error) {
-	if limit <= 0 {
-		limit = 20
+func (r *WishRepository) FindByRsvpIDTx(ctx context.Context, tx *gorm.DB, rsvpID string) (model.Wish, bool, error) {
+	var item model.Wish
+	err := tx.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("rsvp_id = ?", rsvpID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Wish{}, false, nil
 	}
-	if limit > 100 {
-		limit = 100
+	if err != nil {
+		return model.Wish{}, false, err
 	}
+	return item, true, nil
+}
 
-	items := make([]model.Wish, 0)
+func (r *WishRepository) UpdateMessageTx(ctx context.Context, tx *gorm.DB, id, guestName, message, status, flagReason string) error {
+	return tx.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"guest_name":  guestName,
+			"message":     message,
+			"status":      status,
+			"flag_reason": flagReason,
+		}).Error
+}
+
+// messageKeySQL is moderation.MessageKey in SQL.
+const messageKeySQL = "regexp_replace(lower(message), '[^[:alnum:]]+', '', 'g')"
+
+// CountRecentMessages counts wishes of the invitation with the same message key, created
+// since the given time.
+func (r *WishRepository) CountRecentMessages(ctx context.Context, invitationID, key string, since time.Time) (int64, error) {
+	var total int64
 	err := r.DB.WithContext(ctx).
 		Model(&model.Wish{}).
-		Where("invitation_id = ?", invitationID).
+		Where("invitation_id = ? AND "+messageKeySQL+" = ? AND created_at >= ?", invitationID, key, since).
+		Count(&total).Error
+	return total, err
+}
+
+// HasMessageFrom reports whether the guest already posted a wish with the same message key.
+func (r *WishRepository) HasMessageFrom(ctx context.Context, invitationID, guestName, key string) (bool, error) {
+	var total int64
+	err := r.DB.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("invitation_id = ? AND lower(btrim(guest_name)) = lower(btrim(?)) AND "+messageKeySQL+" = ?", invitationID, guestName, key).
+		Limit(1).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// CountGuestbookByInvitationID counts the wishes guests posted directly, in any status. Messages
+// attached to an RSVP are limited by the RSVP quota instead.
+func (r *WishRepository) CountGuestbookByInvitationID(ctx context.Context, invitationID string) (int64, error) {
+	return r.countGuestbookWithDB(r.DB.WithContext(ctx), invitationID)
+}
+
+func (r *WishRepository) CountGuestbookByInvitationIDTx(ctx context.Context, tx *gorm.DB, invitationID string) (int64, error) {
+	return r.countGuestbookWithDB(tx.WithContext(ctx), invitationID)
+}
+
+func (r *WishRepository) countGuestbookWithDB(db *gorm.DB, invitationID string) (int64, error) {
+	var total int64
+	err := db.
+		Model(&model.Wish{}).
+		Where("invitation_id = ? AND rsvp_id IS NULL", invitationID).
+		Count(&total).Error
+	return total, err
+}
+
+// CountGuestbookByInvitationIDs is CountGuestbookByInvitationID for several invitations.
+func (r *WishRepository) CountGuestbookByInvitationIDs(ctx context.Context, invitationIDs []string) (map[string]int64, error) {
+	return countByInvitation(r.DB.WithContext(ctx).Model(&model.Wish{}).Where("rsvp_id IS NULL"), invitationIDs)
+}
+
+func (r *WishRepository) DeleteTx(ctx context.Context, tx *gorm.DB, id string) error {
+	return tx.WithContext(ctx).Where("id = ?", id).Delete(&model.Wish{}).Error
+}
+
+// ListApprovedByInvitationID returns a page of the wishes shown on the public page, pinned ones
+// first, continuing after the given cursor. It fetches one extra row so callers can tell whether
+// another page exists.
+func (r *WishRepository) ListApprovedByInvitationID(ctx context.Context, invitationID string, after *query.WishCursor, limit int) ([]model.Wish, error) {
+	db := r.DB.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("invitation_id = ? AND status = ?", invitationID, model.WishStatusApproved)
+	if after != nil {
+		db = db.Where("(pinned, created_at, id) < (?, ?, ?)", after.Pinned, after.CreatedAt, after.ID)
+	}
+
+	items := make([]model.Wish, 0, limit+1)
+	err := db.
+		Order("pinned DESC").
 		Order("created_at DESC").
-		Limit(limit).
+		Order("id DESC").
+		Limit(limit + 1).
 		Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+func (r *WishRepository) CountApprovedByInvitationID(ctx context.Context, invitationID string) (int64, error) {
+	var total int64
+	err := r.DB.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("invitation_id = ? AND status = ?", invitationID, model.WishStatusApproved).
+		Count(&total).Error
+	return total, err
+}
+
+// ListByInvitationID returns wishes in any status for moderation, pinned ones first.
+func (r *WishRepository) ListByInvitationID(ctx context.Context, filters WishListFilters) ([]model.Wish, int64, error) {
+	scoped := func() *gorm.DB {
+		q := r.DB.WithContext(ctx).Model(&model.Wish{}).Where("invitation_id = ?", filters.InvitationID)
+		if filters.Status != "" {
+			q = q.Where("status = ?", filters.Status)
+		}
+		return q
+	}
+
+	var total int64
+	if err := scoped().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]model.Wish, 0)
+	err := scoped().
+		Order("pinned DESC").
+		Order("created_at DESC").
+		Limit(filters.Limit).
+		Offset(filters.Offset).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *WishRepository) GetByIDAndInvitation(ctx context.Context, id, invitationID string) (model.Wish, bool, error) {
+	var item model.Wish
+	err := r.DB.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("id = ? AND invitation_id = ?", id, invitationID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Wish{}, false, nil
+	}
+	if err != nil {
+		return model.Wish{}, false, err
+	}
+	return item, true, nil
+}
+
+func (r *WishRepository) UpdateModeration(ctx context.Context, id string, input WishModerationInput) error {
+	updates := map[string]any{}
+	if input.Status != nil {
+		updates["status"] = *input.Status
+	}
+	if input.Pinned != nil {
+		updates["pinned"] = *input.Pinned
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return r.DB.WithContext(ctx).
+		Model(&model.Wish{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *WishRepository) Delete(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.Wish{}).Error
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+const DefaultInterval = time.Minute
+
+// Publication applies the publish_at and archive_at schedules of invitations. It runs inside the
+// API process; several instances may run it at once since every row is claimed atomically.
+type Publication struct {
+	Repo     *repository.InvitationRepository
+	Interval time.Duration
+	Now      func() time.Time
+}
+
+// Run ticks until ctx is done. A tick in progress is allowed to finish so no schedule is left
+// half applied on shutdown.
+func (p *Publication) Run(ctx context.Context) {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.tick()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.tick()
+		}
+	}
+}
+
+func (p *Publication) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := p.RunOnce(ctx); err != nil {
+		log.Printf("scheduler: %v", err)
+	}
+}
+
+// RunOnce publishes and archives everything that is due now.
+func (p *Publication) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+
+	published, err := p.Repo.PublishDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, id := range published {
+		log.Printf("scheduler: published invitation %s", id)
+	}
+
+	archived, err := p.Repo.ArchiveDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, id := range archived {
+		log.Printf("scheduler: archived invitation %s", id)
+	}
+
+	return nil
+}
@@ -15,6 +15,12 @@ import (
 type InvitationService struct {
 	Repo         *repository.InvitationRepository
 	CustomerRepo *repository.CustomerRepository
+	Retention    RevisionRetention
+}
+
+// RevisionRetention reports how many content revisions a customer's plan keeps per invitation.
+type RevisionRetention interface {
+	RevisionRetention(ctx context.Context, customerID string) (int, error)
 }
 
 var (
@@ -66,9 +72,23 @@ func (s *InvitationService) Update(ctx context.Context, id string, input reposit
 		}
 		input.Slug = slug
 	}
+	if s.Retention != nil {
+		keep, err := s.Retention.RevisionRetention(ctx, input.CustomerID)
+		if err != nil {
+			return err
+		}
+		input.KeepRevisions = keep
+	}
 	if err := s.Repo.Update(ctx, id, input); err != nil {
 		return err
 	}
+	if input.IsPublished {
+		if _, err := s.Repo.Publish(ctx, id); err != nil {
+			return err
+		}
+	} else if _, err := s.Repo.Unpublish(ctx, id); err != nil {
+		return err
+	}
 	if !input.IsPublished || s.CustomerRepo == nil {
 		return nil
 	}
@@ -107,3 +127,8 @@ func (s *InvitationService) ensureSlug(ctx context.Context, customerID, inputSlu
 func (s *InvitationService) ListWithCustomers(ctx context.Context, filters query.InvitationListFilters) ([]repository.InvitationWithCustomer, error) {
 	return s.Repo.ListWithCustomer(ctx, filters)
 }
+
+// ListScheduled returns invitations waiting on the publication scheduler.
+func (s *InvitationService) ListScheduled(ctx context.Context, limit, offset int) ([]repository.InvitationWithCustomer, int64, error) {
+	return s.Repo.ListScheduled(ctx, limit, offset)
+}
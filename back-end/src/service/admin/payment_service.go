@@ -2,15 +2,51 @@ package admin
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
+	"gorm.io/gorm"
 )
 
 type PaymentService struct {
 	Repo *repository.PaymentRepository
+	// Receipts is the private store manual transfer receipts are read from.
+	Receipts storage.Storage
+	Settler  PaymentSettler
 }
 
+// PaymentSettler applies a payment's new status the way gateway webhooks do, including marking
+// the customer paid, and refunds payments through their gateway.
+type PaymentSettler interface {
+	ApplyStatusTx(ctx context.Context, tx *gorm.DB, paymentID string, status external.GatewayStatus, event model.PaymentEvent) (model.Payment, bool, error)
+	ForgetCustomer(customerID string)
+	Refund(ctx context.Context, paymentID, actorID, reason string) (model.Payment, error)
+}
+
+// The refund errors are the ones the settler returns, so handlers can match either package.
+var (
+	ErrPaymentNotFound             = customerService.ErrPaymentNotFound
+	ErrPaymentNotAwaitingReview    = errors.New("payment is not awaiting review")
+	ErrReviewNoteRequired          = errors.New("a note is required when rejecting a payment")
+	ErrProofNotFound               = errors.New("payment has no receipt")
+	ErrPaymentNotRefundable        = customerService.ErrPaymentNotRefundable
+	ErrPaymentGatewayNotConfigured = customerService.ErrPaymentGatewayNotConfigured
+	ErrRefundFailed                = customerService.ErrRefundFailed
+)
+
+// Raw statuses stored on reviewed manual transfers, in place of a gateway's own status.
+const (
+	reviewApproved = "approved"
+	reviewRejected = "rejected"
+)
+
 type PaymentListItem struct {
 	ID            string     `json:"id"`
 	CustomerID    string     `json:"customer_id"`
@@ -22,17 +58,33 @@ type PaymentListItem struct {
 	Amount        int        `json:"amount"`
 	Currency      string     `json:"currency"`
 	Status        string     `json:"status"`
+	Provider      string     `json:"provider"`
+	ReviewNote    string     `json:"review_note"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
 	PaidAt        *time.Time `json:"paid_at"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
+type PaymentEventItem struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	Source     string    `json:"source"`
+	RawStatus  string    `json:"raw_status"`
+	Status     string    `json:"status"`
+	FromStatus string    `json:"from_status"`
+	Applied    bool      `json:"applied"`
+	Payload    string    `json:"payload"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type PaymentSummary struct {
-	TotalRevenue  int64 `json:"total_revenue"`
-	PaidCount     int64 `json:"paid_count"`
-	PendingCount  int64 `json:"pending_count"`
-	FailedCount   int64 `json:"failed_count"`
-	RefundedCount int64 `json:"refunded_count"`
+	TotalRevenue        int64 `json:"total_revenue"`
+	PaidCount           int64 `json:"paid_count"`
+	PendingCount        int64 `json:"pending_count"`
+	AwaitingReviewCount int64 `json:"awaiting_review_count"`
+	FailedCount         int64 `json:"failed_count"`
+	RefundedCount       int64 `json:"refunded_count"`
 }
 
 type PaymentListResult struct {
@@ -66,6 +118,9 @@ func (s *PaymentService) List(ctx context.Context, filters repository.AdminPayme
 			Amount:        row.Amount,
 			Currency:      row.Currency,
 			Status:        row.Status,
+			Provider:      row.Provider,
+			ReviewNote:    row.ReviewNote,
+			ReviewedAt:    row.ReviewedAt,
 			PaidAt:        row.PaidAt,
 			CreatedAt:     row.CreatedAt,
 			UpdatedAt:     row.UpdatedAt,
@@ -77,11 +132,130 @@ func (s *PaymentService) List(ctx context.Context, filters repository.AdminPayme
 		Limit:  filters.Limit,
 		Offset: filters.Offset,
 		Summary: PaymentSummary{
-			TotalRevenue:  summary.TotalRevenue,
-			PaidCount:     summary.PaidCount,
-			PendingCount:  summary.PendingCount,
-			FailedCount:   summary.FailedCount,
-			RefundedCount: summary.RefundedCount,
+			TotalRevenue:        summary.TotalRevenue,
+			PaidCount:           summary.PaidCount,
+			PendingCount:        summary.PendingCount,
+			AwaitingReviewCount: summary.AwaitingReviewCount,
+			FailedCount:         summary.FailedCount,
+			RefundedCount:       summary.RefundedCount,
 		},
 	}, nil
 }
+
+// Approve accepts a manual transfer; the customer's plan is active from then on.
+func (s *PaymentService) Approve(ctx context.Context, paymentID, reviewerID, note string) (model.Payment, error) {
+	return s.review(ctx, paymentID, reviewerID, note, true)
+}
+
+// Reject declines a manual transfer. The note tells the customer why.
+func (s *PaymentService) Reject(ctx context.Context, paymentID, reviewerID, note string) (model.Payment, error) {
+	if strings.TrimSpace(note) == "" {
+		return model.Payment{}, ErrReviewNoteRequired
+	}
+	return s.review(ctx, paymentID, reviewerID, note, false)
+}
+
+func (s *PaymentService) review(ctx context.Context, paymentID, reviewerID, note string, approve bool) (model.Payment, error) {
+	status := external.GatewayStatus{RawStatus: reviewRejected, Status: external.PaymentStatusFailed}
+	if approve {
+		status = external.GatewayStatus{RawStatus: reviewApproved, Status: external.PaymentStatusPaid}
+	}
+
+	var (
+		payment model.Payment
+		applied bool
+	)
+	err := s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The lock makes a second admin deciding at the same time see the first decision.
+		current, ok, err := s.Repo.GetByIDForUpdateTx(ctx, tx, paymentID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrPaymentNotFound
+		}
+		if current.Status != model.PaymentStatusAwaitingReview {
+			return ErrPaymentNotAwaitingReview
+		}
+
+		if err := s.Repo.ReviewTx(ctx, tx, current.ID, repository.PaymentReviewInput{
+			ReviewerID: reviewerID,
+			Note:       strings.TrimSpace(note),
+			At:         time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+		payment, applied, err = s.Settler.ApplyStatusTx(ctx, tx, current.ID, status, model.PaymentEvent{
+			Source:  model.PaymentEventSourceReview,
+			ActorID: &reviewerID,
+			Payload: strings.TrimSpace(note),
+		})
+		return err
+	})
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if applied {
+		s.Settler.ForgetCustomer(payment.CustomerID)
+	}
+	return payment, nil
+}
+
+// Refund returns a paid payment's money through its gateway. The customer loses the plan unless
+// another paid payment still covers it, and content beyond the remaining plan goes offline.
+func (s *PaymentService) Refund(ctx context.Context, paymentID, adminID, reason string) (model.Payment, error) {
+	return s.Settler.Refund(ctx, paymentID, adminID, reason)
+}
+
+// Events returns every status notification the payment received, oldest first.
+func (s *PaymentService) Events(ctx context.Context, paymentID string) ([]PaymentEventItem, error) {
+	_, ok, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+
+	events, err := s.Repo.ListEvents(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]PaymentEventItem, 0, len(events))
+	for _, event := range events {
+		items = append(items, PaymentEventItem{
+			ID:         event.ID,
+			Provider:   event.Provider,
+			Source:     event.Source,
+			RawStatus:  event.RawStatus,
+			Status:     event.Status,
+			FromStatus: event.FromStatus,
+			Applied:    event.Applied,
+			Payload:    event.Payload,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Proof returns the receipt uploaded for a manual transfer and its content type.
+func (s *PaymentService) Proof(ctx context.Context, paymentID string) ([]byte, string, error) {
+	payment, ok, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", ErrPaymentNotFound
+	}
+	key := strings.TrimSpace(payment.ProofOfPayment)
+	if payment.Provider != model.PaymentProviderManualTransfer || key == "" || s.Receipts == nil {
+		return nil, "", ErrProofNotFound
+	}
+
+	data, err := s.Receipts.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, http.DetectContentType(data), nil
+}
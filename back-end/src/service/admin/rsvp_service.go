@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+type RsvpService struct {
+	Repo           *repository.RsvpRepository
+	InvitationRepo *repository.InvitationRepository
+}
+
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+type RsvpListItem struct {
+	ID          string    `json:"id"`
+	GuestID     *string   `json:"guest_id"`
+	GuestName   string    `json:"guest_name"`
+	Attendance  string    `json:"attendance"`
+	GuestsCount int       `json:"guests_count"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type RsvpListResult struct {
+	Items  []RsvpListItem `json:"items"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+type RsvpSummary struct {
+	TotalResponses    int64 `json:"total_responses"`
+	AttendingCount    int64 `json:"attending_count"`
+	NotAttendingCount int64 `json:"not_attending_count"`
+	TotalGuests       int64 `json:"total_guests"`
+}
+
+func (s *RsvpService) List(ctx context.Context, filters repository.RsvpListFilters) (RsvpListResult, error) {
+	if err := s.ensureInvitation(ctx, filters.InvitationID); err != nil {
+		return RsvpListResult{}, err
+	}
+	rows, total, err := s.Repo.ListByInvitationID(ctx, filters)
+	if err != nil {
+		return RsvpListResult{}, err
+	}
+
+	items := make([]RsvpListItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, RsvpListItem{
+			ID:          row.ID,
+			GuestID:     row.GuestID,
+			GuestName:   row.GuestName,
+			Attendance:  row.Attendance,
+			GuestsCount: row.GuestsCount,
+			Message:     row.Message,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		})
+	}
+
+	return RsvpListResult{
+		Items:  items,
+		Total:  total,
+		Limit:  filters.Limit,
+		Offset: filters.Offset,
+	}, nil
+}
+
+func (s *RsvpService) Summary(ctx context.Context, invitationID string) (RsvpSummary, error) {
+	if err := s.ensureInvitation(ctx, invitationID); err != nil {
+		return RsvpSummary{}, err
+	}
+	summary, err := s.Repo.SummaryByInvitationID(ctx, invitationID)
+	if err != nil {
+		return RsvpSummary{}, err
+	}
+
+	return RsvpSummary{
+		TotalResponses:    summary.TotalResponses,
+		AttendingCount:    summary.AttendingCount,
+		NotAttendingCount: summary.NotAttendingCount,
+		TotalGuests:       summary.TotalGuests,
+	}, nil
+}
+
+func (s *RsvpService) Export(ctx context.Context, filters repository.RsvpListFilters, fn func(model.RSVP) error) error {
+	if err := s.ensureInvitation(ctx, filters.InvitationID); err != nil {
+		return err
+	}
+	return s.Repo.EachByInvitationID(ctx, filters, fn)
+}
+
+func (s *RsvpService) ensureInvitation(ctx context.Context, invitationID string) error {
+	_, ok, err := s.InvitationRepo.GetByID(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
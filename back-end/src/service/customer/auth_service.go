@@ -12,7 +12,6 @@ import (
 	"github.com/proxima-labs/wedding-invitation-back-end/src/auth"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
-	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
 )
@@ -37,6 +36,13 @@ type RegisterInput struct {
 	Content   []byte
 }
 
+type LoginResult struct {
+	CustomerID  string
+	Email       string
+	Domain      string
+	Invitations []model.Invitation
+}
+
 type IssueRefreshTokenInput struct {
 	CustomerID string
 	UserAgent  string
@@ -108,19 +114,24 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (custom
 			ThemeKey:    input.ThemeKey,
 			IsPublished: false,
 			Content:     content.Normalize(input.Content, input.FullName),
+			Author:      repository.RevisionAuthor{Type: model.RevisionAuthorCustomer, ID: customerID},
 		})
 		return err
 	})
 	if err != nil {
 		return "", "", "", "", err
 	}
+	// A visit before signing up may have cached the new subdomain as unknown.
+	s.CustomerRepo.ForgetCached(customerID)
 
 	return customerID, invitationID, customerSlug, domain, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (customerID, invitationID, customerSlug, domain, normalizedEmail string, err error) {
+// Login checks the credentials and returns the customer with all of their invitations; the client
+// picks which one to open.
+func (s *AuthService) Login(ctx context.Context, email, password string) (LoginResult, error) {
 	if s.CustomerRepo == nil || s.InvitationRepo == nil {
-		return "", "", "", "", "", ErrInvalidCredentials
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
 	email = strings.TrimSpace(strings.ToLower(email))
@@ -128,26 +139,23 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (custom
 
 	customer, ok, dbErr := s.CustomerRepo.FindByEmail(ctx, email)
 	if dbErr != nil || !ok {
-		return "", "", "", "", "", ErrInvalidCredentials
+		return LoginResult{}, ErrInvalidCredentials
 	}
 	if err := bcryptCompare([]byte(customer.PasswordHash), []byte(password)); err != nil {
-		return "", "", "", "", "", ErrInvalidCredentials
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
-	items, dbErr := s.InvitationRepo.List(ctx, query.InvitationListFilters{
-		CustomerID: customer.ID,
-		Limit:      1,
-		Offset:     0,
-	})
+	items, dbErr := s.InvitationRepo.ListByCustomerID(ctx, customer.ID)
 	if dbErr != nil {
-		return "", "", "", "", "", dbErr
-	}
-	if len(items) == 0 {
-		return "", "", "", "", "", ErrInvitationNotFound
+		return LoginResult{}, dbErr
 	}
 
-	inv := items[0]
-	return customer.ID, inv.ID, inv.Slug, customer.Domain, customer.Email, nil
+	return LoginResult{
+		CustomerID:  customer.ID,
+		Email:       customer.Email,
+		Domain:      customer.Domain,
+		Invitations: items,
+	}, nil
 }
 
 func (s *AuthService) IssueRefreshToken(ctx context.Context, input IssueRefreshTokenInput) (string, error) {
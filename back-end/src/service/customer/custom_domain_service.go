@@ -0,0 +1,231 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
+)
+
+var (
+	ErrCustomDomainNotFound     = errors.New("custom domain not found")
+	ErrInvalidHostname          = errors.New("invalid hostname")
+	ErrCustomDomainNotAllowed   = errors.New("custom domain not included in your plan")
+	ErrCustomDomainLimit        = errors.New("custom domain limit reached")
+	ErrCustomDomainExists       = errors.New("custom domain already registered")
+	ErrCustomDomainTaken        = errors.New("domain is already used by another account")
+	ErrDomainVerificationFailed = errors.New("domain verification failed")
+)
+
+const (
+	// CustomDomainRecordPrefix is prepended to the hostname to form the TXT record name.
+	CustomDomainRecordPrefix = "_janjiakad-verify"
+	customDomainRecordValue  = "janjiakad-verify="
+	customDomainTokenSize    = 24
+	customDomainDNSTimeout   = 10 * time.Second
+)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver satisfies it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type CustomDomainService struct {
+	Repo *repository.CustomDomainRepository
+	// CustomerRepo serializes registrations of one customer so they cannot exceed the plan limit.
+	CustomerRepo *repository.CustomerRepository
+	Enforcer     *PlanEnforcer
+	// Resolver checks verification records; nil uses the system resolver.
+	Resolver TXTResolver
+}
+
+// VerificationRecord returns the TXT record the customer must publish to prove control of domain.
+func VerificationRecord(domain model.CustomDomain) (name, value string) {
+	return CustomDomainRecordPrefix + "." + domain.Hostname, customDomainRecordValue + domain.VerificationToken
+}
+
+func (s *CustomDomainService) List(ctx context.Context, customerID string) ([]model.CustomDomain, error) {
+	return s.Repo.ListByCustomer(ctx, customerID)
+}
+
+// Register claims a hostname for the customer. It is not served until Verify succeeds.
+func (s *CustomDomainService) Register(ctx context.Context, customerID, hostname string) (model.CustomDomain, error) {
+	hostname, err := NormalizeHostname(hostname)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+	limits, err := s.ensureAllowed(ctx, customerID)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+
+	taken, err := s.Repo.VerifiedByOther(ctx, hostname, customerID)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+	if taken {
+		return model.CustomDomain{}, ErrCustomDomainTaken
+	}
+
+	token, err := slug.GenerateToken(customDomainTokenSize)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+
+	var domain model.CustomDomain
+	err = s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.CustomerRepo.LockTx(ctx, tx, customerID); err != nil {
+			return err
+		}
+		existing, err := s.Repo.ListByCustomerTx(ctx, tx, customerID)
+		if err != nil {
+			return err
+		}
+		for _, item := range existing {
+			if item.Hostname == hostname {
+				return ErrCustomDomainExists
+			}
+		}
+		if len(existing) >= limits.MaxCustomDomains {
+			return ErrCustomDomainLimit
+		}
+
+		domain, err = s.Repo.CreateTx(ctx, tx, customerID, hostname, token)
+		return err
+	})
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+	return domain, nil
+}
+
+// Verify looks up the domain's TXT record and marks the domain verified when it carries the
+// expected token. A failed check is recorded on the domain and returned wrapped in
+// ErrDomainVerificationFailed.
+func (s *CustomDomainService) Verify(ctx context.Context, customerID, id string) (model.CustomDomain, error) {
+	domain, ok, err := s.Repo.GetByIDAndCustomer(ctx, id, customerID)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+	if !ok {
+		return model.CustomDomain{}, ErrCustomDomainNotFound
+	}
+	if _, err := s.ensureAllowed(ctx, customerID); err != nil {
+		return model.CustomDomain{}, err
+	}
+
+	taken, err := s.Repo.VerifiedByOther(ctx, domain.Hostname, customerID)
+	if err != nil {
+		return model.CustomDomain{}, err
+	}
+	if taken {
+		return model.CustomDomain{}, ErrCustomDomainTaken
+	}
+
+	now := time.Now()
+	if reason := s.checkRecord(ctx, domain); reason != "" {
+		if err := s.Repo.MarkFailed(ctx, domain.ID, now, reason); err != nil {
+			return model.CustomDomain{}, err
+		}
+		return model.CustomDomain{}, fmt.Errorf("%w: %s", ErrDomainVerificationFailed, reason)
+	}
+
+	if err := s.Repo.MarkVerified(ctx, domain.ID, now); err != nil {
+		// The unique index on verified hostnames rejects a customer who verified concurrently.
+		if taken, checkErr := s.Repo.VerifiedByOther(ctx, domain.Hostname, customerID); checkErr == nil && taken {
+			return model.CustomDomain{}, ErrCustomDomainTaken
+		}
+		return model.CustomDomain{}, err
+	}
+	domain.Status = model.CustomDomainStatusVerified
+	domain.VerifiedAt = &now
+	domain.LastCheckedAt = &now
+	domain.LastError = ""
+	return domain, nil
+}
+
+func (s *CustomDomainService) Delete(ctx context.Context, customerID, id string) error {
+	domain, ok, err := s.Repo.GetByIDAndCustomer(ctx, id, customerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCustomDomainNotFound
+	}
+	return s.Repo.Delete(ctx, domain.ID)
+}
+
+func (s *CustomDomainService) ensureAllowed(ctx context.Context, customerID string) (PlanLimits, error) {
+	limits, err := s.Enforcer.GetCustomerLimits(ctx, customerID)
+	if err != nil {
+		return PlanLimits{}, err
+	}
+	if !limits.CustomDomain || limits.MaxCustomDomains <= 0 {
+		return PlanLimits{}, ErrCustomDomainNotAllowed
+	}
+	return limits, nil
+}
+
+// checkRecord returns why verification failed, or "" when the record is in place.
+func (s *CustomDomainService) checkRecord(ctx context.Context, domain model.CustomDomain) string {
+	var resolver TXTResolver = net.DefaultResolver
+	if s.Resolver != nil {
+		resolver = s.Resolver
+	}
+
+	name, value := VerificationRecord(domain)
+	ctx, cancel := context.WithTimeout(ctx, customDomainDNSTimeout)
+	defer cancel()
+
+	records, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Sprintf("no TXT record found at %s", name)
+		}
+		return fmt.Sprintf("dns lookup of %s failed", name)
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == value {
+			return ""
+		}
+	}
+	return fmt.Sprintf("TXT record at %s does not contain %s", name, value)
+}
+
+// NormalizeHostname lowercases a hostname and checks it is a fully qualified DNS name. IP
+// addresses, ports, wildcards and non-ASCII names (which must be given in punycode) are rejected.
+func NormalizeHostname(raw string) (string, error) {
+	hostname := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+	if hostname == "" || len(hostname) > 253 {
+		return "", ErrInvalidHostname
+	}
+
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidHostname
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", ErrInvalidHostname
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return "", ErrInvalidHostname
+			}
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return "", ErrInvalidHostname
+	}
+	return hostname, nil
+}
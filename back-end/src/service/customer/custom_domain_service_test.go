@@ -0,0 +1,184 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+const (
+	testCustomerID = "5b0c1f8e-3d4a-4c7b-9e2f-1a2b3c4d5e6f"
+	testDomainID   = "8f3e2d1c-0b9a-4f8e-8d7c-6b5a4f3e2d1c"
+	testHostname   = "rina-dan-bayu.com"
+	testToken      = "abc123"
+)
+
+var customDomainColumns = []string{"id", "customer_id", "hostname", "verification_token", "status", "verified_at", "last_checked_at", "last_error", "created_at"}
+
+type fakeTXTResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r fakeTXTResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.records[name], nil
+}
+
+func TestCustomDomainVerify(t *testing.T) {
+	recordName := CustomDomainRecordPrefix + "." + testHostname
+
+	tests := []struct {
+		name          string
+		resolver      fakeTXTResolver
+		takenByOther  bool
+		wantErr       error
+		wantErrDetail string
+		wantUpdate    string
+	}{
+		{
+			name:       "matching token",
+			resolver:   fakeTXTResolver{records: map[string][]string{recordName: {"v=spf1 -all", " janjiakad-verify=abc123 "}}},
+			wantUpdate: model.CustomDomainStatusVerified,
+		},
+		{
+			name:          "missing token",
+			resolver:      fakeTXTResolver{records: map[string][]string{recordName: {"janjiakad-verify=other"}}},
+			wantErr:       ErrDomainVerificationFailed,
+			wantErrDetail: "does not contain janjiakad-verify=abc123",
+			wantUpdate:    model.CustomDomainStatusFailed,
+		},
+		{
+			name:          "nxdomain",
+			resolver:      fakeTXTResolver{err: &net.DNSError{Err: "no such host", Name: recordName, IsNotFound: true}},
+			wantErr:       ErrDomainVerificationFailed,
+			wantErrDetail: "no TXT record found at " + recordName,
+			wantUpdate:    model.CustomDomainStatusFailed,
+		},
+		{
+			name:          "lookup error",
+			resolver:      fakeTXTResolver{err: &net.DNSError{Err: "i/o timeout", Name: recordName, IsTimeout: true}},
+			wantErr:       ErrDomainVerificationFailed,
+			wantErrDetail: "dns lookup of " + recordName + " failed",
+			wantUpdate:    model.CustomDomainStatusFailed,
+		},
+		{
+			name:         "verified by another customer",
+			resolver:     fakeTXTResolver{records: map[string][]string{recordName: {"janjiakad-verify=abc123"}}},
+			takenByOther: true,
+			wantErr:      ErrCustomDomainTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := testdb.New(t)
+			svc := &CustomDomainService{
+				Repo:     &repository.CustomDomainRepository{DB: db},
+				Enforcer: &PlanEnforcer{PaymentRepo: &repository.PaymentRepository{DB: db}},
+				Resolver: tt.resolver,
+			}
+
+			mock.ExpectQuery(`FROM "custom_domains" WHERE id = \$1 AND customer_id = \$2`).
+				WithArgs(testDomainID, testCustomerID, 1).
+				WillReturnRows(sqlmock.NewRows(customDomainColumns).
+					AddRow(testDomainID, testCustomerID, testHostname, testToken, model.CustomDomainStatusPending, nil, nil, "", time.Now()))
+			expectActivePlan(mock, testCustomerID, "exclusive", `{"custom_domain": true}`)
+
+			taken := 0
+			if tt.takenByOther {
+				taken = 1
+			}
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "custom_domains" WHERE hostname = \$1 AND status = \$2 AND customer_id <> \$3`).
+				WithArgs(testHostname, model.CustomDomainStatusVerified, testCustomerID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(taken))
+			if tt.wantUpdate != "" {
+				mock.ExpectExec(`UPDATE "custom_domains" SET .*"status"=\$\d`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			domain, err := svc.Verify(context.Background(), testCustomerID, testDomainID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErrDetail) {
+					t.Fatalf("Verify err = %q, want it to mention %q", err, tt.wantErrDetail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if domain.Status != model.CustomDomainStatusVerified || domain.VerifiedAt == nil {
+				t.Fatalf("Verify returned status %q, verified_at %v", domain.Status, domain.VerifiedAt)
+			}
+		})
+	}
+}
+
+func TestCustomDomainRegisterLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   string
+		existing int
+		wantErr  error
+	}{
+		{name: "plan without custom domains", limits: `{"custom_domain": false}`, wantErr: ErrCustomDomainNotAllowed},
+		{name: "default limit reached", limits: `{"custom_domain": true}`, existing: 2, wantErr: ErrCustomDomainLimit},
+		{name: "limit from plan", limits: `{"custom_domain": true, "max_custom_domains": 3}`, existing: 2},
+		{name: "limit from plan reached", limits: `{"custom_domain": true, "max_custom_domains": 3}`, existing: 3, wantErr: ErrCustomDomainLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := testdb.New(t)
+			svc := &CustomDomainService{
+				Repo:         &repository.CustomDomainRepository{DB: db},
+				CustomerRepo: &repository.CustomerRepository{DB: db},
+				Enforcer:     &PlanEnforcer{PaymentRepo: &repository.PaymentRepository{DB: db}},
+			}
+
+			expectActivePlan(mock, testCustomerID, "exclusive", tt.limits)
+			if !errors.Is(tt.wantErr, ErrCustomDomainNotAllowed) {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "custom_domains"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectBegin()
+				// The count happens under the customer's row lock so concurrent requests queue.
+				mock.ExpectExec(`SELECT 1 FROM customers WHERE id = \$1 FOR UPDATE`).
+					WithArgs(testCustomerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(customDomainColumns)
+				for i := 0; i < tt.existing; i++ {
+					rows.AddRow("id-"+string(rune('a'+i)), testCustomerID, "host"+string(rune('a'+i))+".com", "t", model.CustomDomainStatusPending, nil, nil, "", time.Now())
+				}
+				mock.ExpectQuery(`FROM "custom_domains" WHERE customer_id = \$1 ORDER BY created_at ASC`).
+					WithArgs(testCustomerID).
+					WillReturnRows(rows)
+				if tt.wantErr == nil {
+					mock.ExpectQuery(`INSERT INTO "custom_domains"`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(testDomainID, time.Now()))
+					mock.ExpectCommit()
+				} else {
+					mock.ExpectRollback()
+				}
+			}
+
+			_, err := svc.Register(context.Background(), testCustomerID, testHostname)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Register err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,31 +2,64 @@ package customer
 
 import (
 	"context"
+	"net"
 	"strings"
 
+	"golang.org/x/net/idna"
+
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 )
 
+// reservedSubdomains are labels under a base domain that belong to the app itself.
+var reservedSubdomains = map[string]struct{}{
+	"www":   {},
+	"api":   {},
+	"admin": {},
+}
+
 type CustomerService struct {
-	Repo *repository.CustomerRepository
+	Repo       *repository.CustomerRepository
+	DomainRepo *repository.CustomDomainRepository
+	// BaseDomains are the app's own domains, e.g. "ourapp.id"; <domain>.ourapp.id resolves to the
+	// customer whose domain is <domain>.
+	BaseDomains []string
 }
 
+// ResolveByHost finds the customer a request Host belongs to: a single label under one of the
+// base domains is matched against customers.domain, any other host against verified custom
+// domains.
 func (s *CustomerService) ResolveByHost(ctx context.Context, host string) (model.Customer, bool, error) {
-	host = strings.TrimSpace(host)
-	if host == "" {
+	host, ok := normalizeHost(host)
+	if !ok {
 		return model.Customer{}, false, nil
 	}
-	if strings.Contains(host, ":") {
-		host = strings.Split(host, ":")[0]
-	}
 
-	customer, ok, err := s.Repo.FindByDomain(ctx, host)
-	if err != nil || ok {
-		return customer, ok, err
+	for _, base := range s.BaseDomains {
+		base, ok := normalizeHost(base)
+		if !ok {
+			continue
+		}
+		if host == base {
+			return model.Customer{}, false, nil
+		}
+		label, found := strings.CutSuffix(host, "."+base)
+		if !found {
+			continue
+		}
+		if strings.Contains(label, ".") {
+			return model.Customer{}, false, nil
+		}
+		if _, reserved := reservedSubdomains[label]; reserved {
+			return model.Customer{}, false, nil
+		}
+		return s.Repo.FindByDomain(ctx, label)
 	}
 
-	return model.Customer{}, false, nil
+	if s.DomainRepo == nil {
+		return model.Customer{}, false, nil
+	}
+	return s.DomainRepo.FindVerifiedCustomer(ctx, host)
 }
 
 func (s *CustomerService) ResolveByDomain(ctx context.Context, domain string) (model.Customer, bool, error) {
@@ -37,3 +70,22 @@ func (s *CustomerService) ResolveByDomain(ctx context.Context, domain string) (m
 
 	return s.Repo.FindByDomain(ctx, domain)
 }
+
+// normalizeHost drops the port and trailing dot of a Host header and converts it to lowercase
+// ASCII, turning internationalized names into punycode.
+func normalizeHost(host string) (string, bool) {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return "", false
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", false
+	}
+	return strings.ToLower(ascii), true
+}
@@ -0,0 +1,102 @@
+package customer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host   string
+		want   string
+		wantOK bool
+	}{
+		{host: "Foo.OurApp.id:8443", want: "foo.ourapp.id", wantOK: true},
+		{host: "rina.ourapp.id.", want: "rina.ourapp.id", wantOK: true},
+		{host: " rina.ourapp.id. ", want: "rina.ourapp.id", wantOK: true},
+		{host: "Ñina.OurApp.id", want: "xn--ina-6ma.ourapp.id", wantOK: true},
+		{host: "undangan.みんな:443", want: "undangan.xn--q9jyb4c", wantOK: true},
+		{host: "[::1]:8443", wantOK: false},
+		{host: "::1", wantOK: false},
+		{host: "", wantOK: false},
+		{host: ".", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := normalizeHost(tt.host)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("normalizeHost(%q) = %q, %v, want %q, %v", tt.host, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestResolveByHost(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		// domain is the customers.domain looked up, hostname the verified custom domain; neither
+		// means the host is refused without a query.
+		domain    string
+		hostname  string
+		wantFound bool
+	}{
+		{name: "subdomain with port and mixed case", host: "Foo.OurApp.id:8443", domain: "foo", wantFound: true},
+		{name: "subdomain with trailing dot", host: "foo.ourapp.id.", domain: "foo", wantFound: true},
+		{name: "second base domain", host: "foo.ourapp.com", domain: "foo", wantFound: true},
+		{name: "unknown subdomain", host: "bar.ourapp.id", domain: "bar"},
+		{name: "unicode label", host: "Ñina.ourapp.id", domain: "xn--ina-6ma", wantFound: true},
+		{name: "custom domain", host: "Rina-Dan-Bayu.com.", hostname: "rina-dan-bayu.com", wantFound: true},
+		{name: "unicode custom domain", host: "undangan.みんな", hostname: "undangan.xn--q9jyb4c", wantFound: true},
+		{name: "reserved www", host: "www.ourapp.id"},
+		{name: "reserved api", host: "API.ourapp.id"},
+		{name: "reserved admin", host: "admin.ourapp.id:443"},
+		{name: "bare base domain", host: "ourapp.id"},
+		{name: "bare base domain with trailing dot", host: "OurApp.id."},
+		{name: "nested subdomain", host: "a.b.ourapp.id"},
+		{name: "ipv6 with port", host: "[2001:db8::1]:8443"},
+		{name: "empty host", host: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := testdb.New(t)
+			svc := &CustomerService{
+				Repo:        &repository.CustomerRepository{DB: db},
+				DomainRepo:  &repository.CustomDomainRepository{DB: db},
+				BaseDomains: []string{"OurApp.id", "ourapp.com."},
+			}
+
+			rows := sqlmock.NewRows([]string{"id", "domain"})
+			if tt.wantFound {
+				rows.AddRow(testCustomerID, tt.domain)
+			}
+			switch {
+			case tt.domain != "":
+				mock.ExpectQuery(`SELECT \* FROM "customers" WHERE domain = \$1`).
+					WithArgs(tt.domain, 1).
+					WillReturnRows(rows)
+			case tt.hostname != "":
+				mock.ExpectQuery(`JOIN custom_domains ON custom_domains.customer_id = customers.id WHERE custom_domains.hostname = \$1 AND custom_domains.status = \$2`).
+					WithArgs(tt.hostname, model.CustomDomainStatusVerified, 1).
+					WillReturnRows(rows)
+			}
+
+			customer, found, err := svc.ResolveByHost(context.Background(), tt.host)
+			if err != nil {
+				t.Fatalf("ResolveByHost(%q): %v", tt.host, err)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("ResolveByHost(%q) found = %v, want %v", tt.host, found, tt.wantFound)
+			}
+			if found && customer.ID != testCustomerID {
+				t.Fatalf("ResolveByHost(%q) = customer %q, want %q", tt.host, customer.ID, testCustomerID)
+			}
+		})
+	}
+}
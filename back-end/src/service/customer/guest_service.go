@@ -0,0 +1,258 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
+)
+
+var (
+	ErrGuestServiceNotConfigured = errors.New("guest service not configured")
+	ErrGuestNotFound             = errors.New("guest not found")
+)
+
+const guestTokenSize = 12
+
+type GuestService struct {
+	Repo           *repository.GuestRepository
+	InvitationRepo *repository.InvitationRepository
+}
+
+type GuestInput struct {
+	Name         string
+	Phone        string
+	GroupName    string
+	Seat         string
+	MaxPartySize int
+}
+
+// GuestImportColumns reports which optional columns an import file has. A matched guest keeps
+// its stored value for every column the file lacks.
+type GuestImportColumns struct {
+	Phone        bool
+	GroupName    bool
+	Seat         bool
+	MaxPartySize bool
+}
+
+type ImportGuestsResult struct {
+	Created int
+	Updated int
+}
+
+func (s *GuestService) List(ctx context.Context, customerID, invitationID string) ([]model.Guest, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return nil, err
+	}
+	return s.Repo.ListByInvitationID(ctx, invitationID)
+}
+
+func (s *GuestService) Create(ctx context.Context, customerID, invitationID string, input GuestInput) (model.Guest, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return model.Guest{}, err
+	}
+
+	token, err := slug.GenerateToken(guestTokenSize)
+	if err != nil {
+		return model.Guest{}, err
+	}
+
+	input = normalizeGuestInput(input)
+	return s.Repo.Create(ctx, repository.GuestCreateInput{
+		InvitationID: invitationID,
+		Name:         input.Name,
+		Phone:        input.Phone,
+		GroupName:    input.GroupName,
+		Seat:         input.Seat,
+		MaxPartySize: input.MaxPartySize,
+		Token:        token,
+	})
+}
+
+func (s *GuestService) Update(ctx context.Context, customerID, invitationID, guestID string, input GuestInput) (model.Guest, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return model.Guest{}, err
+	}
+
+	guest, ok, err := s.Repo.GetByIDAndInvitation(ctx, guestID, invitationID)
+	if err != nil {
+		return model.Guest{}, err
+	}
+	if !ok {
+		return model.Guest{}, ErrGuestNotFound
+	}
+
+	input = normalizeGuestInput(input)
+	if err := s.Repo.Update(ctx, guest.ID, repository.GuestUpdateInput{
+		Name:         input.Name,
+		Phone:        input.Phone,
+		GroupName:    input.GroupName,
+		Seat:         input.Seat,
+		MaxPartySize: input.MaxPartySize,
+	}); err != nil {
+		return model.Guest{}, err
+	}
+
+	guest.Name = input.Name
+	guest.Phone = input.Phone
+	guest.GroupName = input.GroupName
+	guest.Seat = input.Seat
+	guest.MaxPartySize = input.MaxPartySize
+	return guest, nil
+}
+
+func (s *GuestService) Delete(ctx context.Context, customerID, invitationID, guestID string) error {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return err
+	}
+
+	guest, ok, err := s.Repo.GetByIDAndInvitation(ctx, guestID, invitationID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrGuestNotFound
+	}
+
+	return s.Repo.Delete(ctx, guest.ID)
+}
+
+// Import upserts guests in a single transaction, matching existing guests by phone number or, when
+// no phone is given, by name. Later rows win when the same guest appears twice in one file. Only
+// the columns present in the file are written to matched guests, so an export imported again
+// leaves the list as it was.
+func (s *GuestService) Import(ctx context.Context, customerID, invitationID string, rows []GuestInput, columns GuestImportColumns) (ImportGuestsResult, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return ImportGuestsResult{}, err
+	}
+
+	result := ImportGuestsResult{}
+	err := s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := s.Repo.ListByInvitationIDTx(ctx, tx, invitationID)
+		if err != nil {
+			return err
+		}
+
+		index := make(map[string]*model.Guest, len(existing)*2)
+		for i := range existing {
+			guest := &existing[i]
+			for _, key := range guestMatchKeys(guest.Name, guest.Phone) {
+				index[key] = guest
+			}
+		}
+
+		for _, row := range rows {
+			row = normalizeGuestInput(row)
+			keys := guestMatchKeys(row.Name, row.Phone)
+
+			if guest, ok := index[keys[0]]; ok {
+				guest.Name = row.Name
+				if columns.Phone {
+					guest.Phone = row.Phone
+				}
+				if columns.GroupName {
+					guest.GroupName = row.GroupName
+				}
+				if columns.Seat {
+					guest.Seat = row.Seat
+				}
+				if columns.MaxPartySize {
+					guest.MaxPartySize = row.MaxPartySize
+				}
+				if err := s.Repo.UpdateTx(ctx, tx, guest.ID, repository.GuestUpdateInput{
+					Name:         guest.Name,
+					Phone:        guest.Phone,
+					GroupName:    guest.GroupName,
+					Seat:         guest.Seat,
+					MaxPartySize: guest.MaxPartySize,
+				}); err != nil {
+					return err
+				}
+				result.Updated++
+				continue
+			}
+
+			token, err := slug.GenerateToken(guestTokenSize)
+			if err != nil {
+				return err
+			}
+			guest, err := s.Repo.CreateTx(ctx, tx, repository.GuestCreateInput{
+				InvitationID: invitationID,
+				Name:         row.Name,
+				Phone:        row.Phone,
+				GroupName:    row.GroupName,
+				Seat:         row.Seat,
+				MaxPartySize: row.MaxPartySize,
+				Token:        token,
+			})
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				index[key] = &guest
+			}
+			result.Created++
+		}
+		return nil
+	})
+	if err != nil {
+		return ImportGuestsResult{}, err
+	}
+	return result, nil
+}
+
+// Export walks the guest list with each guest's latest RSVP without loading it all in memory.
+func (s *GuestService) Export(ctx context.Context, customerID, invitationID string, fn func(repository.GuestWithRsvp) error) error {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return err
+	}
+	return s.Repo.EachWithRsvp(ctx, invitationID, fn)
+}
+
+func (s *GuestService) ensureOwnership(ctx context.Context, customerID, invitationID string) error {
+	if s == nil || s.Repo == nil || s.InvitationRepo == nil {
+		return ErrGuestServiceNotConfigured
+	}
+	return ensureInvitationOwner(ctx, s.InvitationRepo, customerID, invitationID)
+}
+
+func normalizeGuestInput(input GuestInput) GuestInput {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Phone = strings.TrimSpace(input.Phone)
+	input.GroupName = strings.TrimSpace(input.GroupName)
+	input.Seat = strings.TrimSpace(input.Seat)
+	if input.MaxPartySize <= 0 {
+		input.MaxPartySize = 1
+	}
+	return input
+}
+
+// guestMatchKeys returns the lookup keys for a guest, most specific first.
+func guestMatchKeys(name, phone string) []string {
+	nameKey := "name:" + strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if phone := normalizePhone(phone); phone != "" {
+		return []string{"phone:" + phone, nameKey}
+	}
+	return []string{nameKey}
+}
+
+// normalizePhone keeps digits only and rewrites local 08xx numbers to the 628xx form.
+func normalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	if strings.HasPrefix(digits, "0") {
+		digits = "62" + strings.TrimPrefix(digits, "0")
+	}
+	return digits
+}
@@ -0,0 +1,109 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+func newGuestService(t *testing.T) (*GuestService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := testdb.New(t)
+	return &GuestService{
+		Repo:           &repository.GuestRepository{DB: db},
+		InvitationRepo: &repository.InvitationRepository{DB: db},
+	}, mock
+}
+
+// expectOwnedInvitation answers the ownership check of every guest operation.
+func expectOwnedInvitation(mock sqlmock.Sqlmock, ownerID string) {
+	mock.ExpectQuery(`SELECT \* FROM "invitations" WHERE id = \$1`).
+		WithArgs(testInvitationID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id"}).AddRow(testInvitationID, ownerID))
+}
+
+func TestGuestMatchKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		guest string
+		phone string
+		want  []string
+	}{
+		{name: "local number", guest: "Pak  Hendra", phone: "0812-3456-789", want: []string{"phone:628123456789", "name:pak hendra"}},
+		{name: "international number", guest: "Pak Hendra", phone: "+62 812 3456 789", want: []string{"phone:628123456789", "name:pak hendra"}},
+		{name: "no phone", guest: " PAK hendra ", want: []string{"name:pak hendra"}},
+		{name: "phone without digits", guest: "Pak Hendra", phone: "-", want: []string{"name:pak hendra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guestMatchKeys(tt.guest, tt.phone); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("guestMatchKeys(%q, %q) = %v, want %v", tt.guest, tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuestCreateIssuesToken(t *testing.T) {
+	svc, mock := newGuestService(t)
+	expectOwnedInvitation(mock, testCustomerID)
+	mock.ExpectQuery(`INSERT INTO "guests"`).
+		WithArgs(testInvitationID, "Pak Hendra", "0812", "", "", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testGuestID))
+
+	guest, err := svc.Create(context.Background(), testCustomerID, testInvitationID, GuestInput{Name: " Pak Hendra ", Phone: "0812"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Twelve random bytes in unpadded base64url.
+	if len(guest.Token) != 16 {
+		t.Fatalf("guest token %q is not a 12-byte token", guest.Token)
+	}
+	if guest.MaxPartySize != 1 {
+		t.Fatalf("MaxPartySize = %d, want the default of 1", guest.MaxPartySize)
+	}
+}
+
+func TestGuestCreateForeignInvitation(t *testing.T) {
+	svc, mock := newGuestService(t)
+	expectOwnedInvitation(mock, "another-customer")
+
+	if _, err := svc.Create(context.Background(), testCustomerID, testInvitationID, GuestInput{Name: "Pak Hendra"}); !errors.Is(err, ErrInvitationNotFound) {
+		t.Fatalf("Create err = %v, want ErrInvitationNotFound", err)
+	}
+}
+
+func TestGuestImportKeepsMissingColumns(t *testing.T) {
+	svc, mock := newGuestService(t)
+	expectOwnedInvitation(mock, testCustomerID)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "guests" WHERE invitation_id = \$1 ORDER BY created_at ASC`).
+		WithArgs(testInvitationID).
+		WillReturnRows(sqlmock.NewRows(guestColumns).
+			AddRow(testGuestID, testInvitationID, "Pak Hendra", "0812-3456", "Keluarga", "A3", 2, testGuestToken))
+	// The file has no group, seat or party size column, so the stored values are written back.
+	mock.ExpectExec(`UPDATE "guests" SET "group_name"=\$1,"max_party_size"=\$2,"name"=\$3,"phone"=\$4,"seat"=\$5,"updated_at"=\$6 WHERE id = \$7`).
+		WithArgs("Keluarga", 2, "Pak Hendra", "+62 812 3456", "A3", sqlmock.AnyArg(), testGuestID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "guests"`).
+		WithArgs(testInvitationID, "Bu Sari", "", "", "", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("guest-2"))
+	mock.ExpectCommit()
+
+	result, err := svc.Import(context.Background(), testCustomerID, testInvitationID, []GuestInput{
+		{Name: "Pak Hendra", Phone: "+62 812 3456"},
+		{Name: "Bu Sari"},
+	}, GuestImportColumns{Phone: true})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result != (ImportGuestsResult{Created: 1, Updated: 1}) {
+		t.Fatalf("Import = %+v", result)
+	}
+}
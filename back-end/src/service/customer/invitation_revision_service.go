@@ -0,0 +1,112 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+var (
+	ErrRevisionNotFound   = errors.New("revision not found")
+	ErrRevisionNotAllowed = errors.New("revision not allowed by plan")
+)
+
+type RevisionDiff struct {
+	From    int
+	To      int
+	Changes []content.Change
+}
+
+// ListRevisions returns the kept revisions of the customer's invitation, newest first.
+func (s *InvitationService) ListRevisions(ctx context.Context, customerID, invitationID string) ([]model.InvitationRevision, error) {
+	if err := ensureInvitationOwner(ctx, s.Repo, customerID, invitationID); err != nil {
+		return nil, err
+	}
+	return s.Repo.ListRevisions(ctx, invitationID)
+}
+
+func (s *InvitationService) GetRevision(ctx context.Context, customerID, invitationID string, version int) (model.InvitationRevision, error) {
+	if err := ensureInvitationOwner(ctx, s.Repo, customerID, invitationID); err != nil {
+		return model.InvitationRevision{}, err
+	}
+	return s.findRevision(ctx, invitationID, version)
+}
+
+// DiffRevisions compares two revisions of the customer's invitation at the JSON-path level.
+func (s *InvitationService) DiffRevisions(ctx context.Context, customerID, invitationID string, from, to int) (RevisionDiff, error) {
+	if err := ensureInvitationOwner(ctx, s.Repo, customerID, invitationID); err != nil {
+		return RevisionDiff{}, err
+	}
+
+	left, err := s.findRevision(ctx, invitationID, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	right, err := s.findRevision(ctx, invitationID, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	changes, err := content.Diff(left.Content, right.Content)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	return RevisionDiff{From: from, To: to, Changes: changes}, nil
+}
+
+// RestoreRevision makes an earlier revision the current draft again. The rollback itself is
+// recorded as a new revision so it can be undone as well; guests keep seeing the published
+// snapshot until the customer publishes.
+func (s *InvitationService) RestoreRevision(ctx context.Context, customerID, invitationID string, version int) (model.InvitationRevision, error) {
+	inv, ok, err := s.Repo.GetByID(ctx, invitationID)
+	if err != nil {
+		return model.InvitationRevision{}, err
+	}
+	if !ok || inv.CustomerID != customerID {
+		return model.InvitationRevision{}, ErrInvitationNotFound
+	}
+
+	revision, err := s.findRevision(ctx, invitationID, version)
+	if err != nil {
+		return model.InvitationRevision{}, err
+	}
+
+	limits, err := s.Enforcer.GetCustomerLimits(ctx, customerID)
+	if err != nil {
+		return model.InvitationRevision{}, err
+	}
+	if err := s.Enforcer.ValidateContent(ctx, customerID, revision.Content, limits); err != nil {
+		return model.InvitationRevision{}, fmt.Errorf("%w: %w", ErrRevisionNotAllowed, err)
+	}
+
+	err = s.Update(ctx, invitationID, repository.InvitationUpdateInput{
+		CustomerID:   inv.CustomerID,
+		Slug:         inv.Slug,
+		Title:        inv.Title,
+		SearchName:   inv.SearchName,
+		EventDate:    inv.EventDate,
+		ThemeKey:     inv.ThemeKey,
+		Content:      revision.Content,
+		RestoredFrom: &version,
+	})
+	if err != nil {
+		return model.InvitationRevision{}, err
+	}
+
+	return revision, nil
+}
+
+func (s *InvitationService) findRevision(ctx context.Context, invitationID string, version int) (model.InvitationRevision, error) {
+	revision, ok, err := s.Repo.GetRevision(ctx, invitationID, version)
+	if err != nil {
+		return model.InvitationRevision{}, err
+	}
+	if !ok {
+		return model.InvitationRevision{}, ErrRevisionNotFound
+	}
+	return revision, nil
+}
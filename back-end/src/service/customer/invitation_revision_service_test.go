@@ -0,0 +1,131 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+var revisionColumns = []string{"id", "invitation_id", "version", "content", "author_type", "author_id", "restored_from"}
+
+func newRevisionService(t *testing.T) (*InvitationService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := testdb.New(t)
+	return &InvitationService{
+		Repo:     &repository.InvitationRepository{DB: db},
+		Enforcer: &PlanEnforcer{PaymentRepo: &repository.PaymentRepository{DB: db}},
+	}, mock
+}
+
+func expectInvitation(mock sqlmock.Sqlmock, ownerID string) {
+	mock.ExpectQuery(`SELECT \* FROM "invitations" WHERE id = \$1`).
+		WithArgs(testInvitationID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "slug", "content"}).
+			AddRow(testInvitationID, ownerID, testSlug, []byte(premiumContent)))
+}
+
+func expectRevision(mock sqlmock.Sqlmock, version int, raw string) {
+	mock.ExpectQuery(`SELECT \* FROM "invitation_revisions" WHERE invitation_id = \$1 AND version = \$2`).
+		WithArgs(testInvitationID, version, 1).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).
+			AddRow(fmt.Sprintf("revision-%d", version), testInvitationID, version, []byte(raw), model.RevisionAuthorCustomer, testCustomerID, nil))
+}
+
+func TestRestoreRevision(t *testing.T) {
+	svc, mock := newRevisionService(t)
+
+	expectInvitation(mock, testCustomerID)
+	expectRevision(mock, 2, allowedContent)
+	expectNoPlan(mock, testCustomerID)
+	// The update reloads the invitation and keeps the plan's five revisions.
+	expectInvitation(mock, testCustomerID)
+	expectNoPlan(mock, testCustomerID)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id","content" FROM "invitations" WHERE id = \$1 .*FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(testInvitationID, []byte(premiumContent)))
+	mock.ExpectExec(`UPDATE "invitations" SET .*"content"=`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "invitation_revisions" WHERE invitation_id = \$1 ORDER BY version DESC`).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).
+			AddRow("revision-4", testInvitationID, 4, []byte(premiumContent), model.RevisionAuthorCustomer, testCustomerID, nil))
+	// The rollback is a new version that points at the restored one.
+	mock.ExpectQuery(`INSERT INTO "invitation_revisions"`).
+		WithArgs(testInvitationID, 5, []byte(allowedContent), model.RevisionAuthorCustomer, testCustomerID, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("revision-5"))
+	mock.ExpectExec(`DELETE FROM "invitation_revisions" WHERE invitation_id = \$1 AND version <= \$2`).
+		WithArgs(testInvitationID, 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	restored, err := svc.RestoreRevision(context.Background(), testCustomerID, testInvitationID, 2)
+	if err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if restored.Version != 2 || string(restored.Content) != allowedContent {
+		t.Fatalf("RestoreRevision = version %d, %s", restored.Version, restored.Content)
+	}
+}
+
+func TestRestoreRevisionOutsidePlan(t *testing.T) {
+	svc, mock := newRevisionService(t)
+
+	// Five gallery photos were saved under a plan the customer no longer has.
+	expectInvitation(mock, testCustomerID)
+	expectRevision(mock, 2, premiumContent)
+	expectNoPlan(mock, testCustomerID)
+
+	_, err := svc.RestoreRevision(context.Background(), testCustomerID, testInvitationID, 2)
+	if !errors.Is(err, ErrRevisionNotAllowed) || !errors.Is(err, ErrPlanLimitExceeded) {
+		t.Fatalf("RestoreRevision err = %v, want ErrRevisionNotAllowed and ErrPlanLimitExceeded", err)
+	}
+}
+
+func TestRestoreRevisionNotFound(t *testing.T) {
+	t.Run("another customer's invitation", func(t *testing.T) {
+		svc, mock := newRevisionService(t)
+		expectInvitation(mock, "another-customer")
+
+		if _, err := svc.RestoreRevision(context.Background(), testCustomerID, testInvitationID, 2); !errors.Is(err, ErrInvitationNotFound) {
+			t.Fatalf("RestoreRevision err = %v, want ErrInvitationNotFound", err)
+		}
+	})
+
+	t.Run("pruned revision", func(t *testing.T) {
+		svc, mock := newRevisionService(t)
+		expectInvitation(mock, testCustomerID)
+		mock.ExpectQuery(`SELECT \* FROM "invitation_revisions" WHERE invitation_id = \$1 AND version = \$2`).
+			WillReturnRows(sqlmock.NewRows(revisionColumns))
+
+		if _, err := svc.RestoreRevision(context.Background(), testCustomerID, testInvitationID, 1); !errors.Is(err, ErrRevisionNotFound) {
+			t.Fatalf("RestoreRevision err = %v, want ErrRevisionNotFound", err)
+		}
+	})
+}
+
+func TestDiffRevisions(t *testing.T) {
+	svc, mock := newRevisionService(t)
+	expectInvitation(mock, testCustomerID)
+	expectRevision(mock, 1, allowedContent)
+	expectRevision(mock, 3, `{"gallery": {"photos": ["https://cdn.example.com/2.jpg"]}, "theme": {"theme": "rustic"}}`)
+
+	diff, err := svc.DiffRevisions(context.Background(), testCustomerID, testInvitationID, 1, 3)
+	if err != nil {
+		t.Fatalf("DiffRevisions: %v", err)
+	}
+	want := RevisionDiff{From: 1, To: 3, Changes: []content.Change{
+		{Path: "$.gallery.photos[0]", Op: content.ChangeChanged, From: "https://cdn.example.com/1.jpg", To: "https://cdn.example.com/2.jpg"},
+		{Path: "$.theme", Op: content.ChangeAdded, To: map[string]any{"theme": "rustic"}},
+	}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("DiffRevisions = %+v, want %+v", diff, want)
+	}
+}
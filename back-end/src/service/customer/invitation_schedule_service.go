@@ -0,0 +1,76 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+type ScheduleInput struct {
+	// PublishAt and ArchiveAt replace the current schedule; nil clears it.
+	PublishAt *time.Time
+	ArchiveAt *time.Time
+	// ArchiveWeeksAfterEvent derives ArchiveAt from the invitation's event_date.
+	ArchiveWeeksAfterEvent *int
+}
+
+// SetSchedule sets when the draft goes live and when the invitation is taken offline again.
+func (s *InvitationService) SetSchedule(ctx context.Context, customerID, invitationID string, input ScheduleInput) (model.Invitation, error) {
+	inv, ok, err := s.Repo.GetByID(ctx, invitationID)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	if !ok || inv.CustomerID != customerID {
+		return model.Invitation{}, ErrInvitationNotFound
+	}
+
+	archiveAt := input.ArchiveAt
+	if input.ArchiveWeeksAfterEvent != nil {
+		if inv.EventDate == nil {
+			return model.Invitation{}, fmt.Errorf("%w: event_date is not set", ErrInvalidSchedule)
+		}
+		at := inv.EventDate.AddDate(0, 0, 7*(*input.ArchiveWeeksAfterEvent))
+		archiveAt = &at
+	}
+
+	now := time.Now()
+	if input.PublishAt != nil && !input.PublishAt.After(now) {
+		return model.Invitation{}, fmt.Errorf("%w: publish_at must be in the future", ErrInvalidSchedule)
+	}
+	if archiveAt != nil && !archiveAt.After(now) {
+		return model.Invitation{}, fmt.Errorf("%w: archive_at must be in the future", ErrInvalidSchedule)
+	}
+	if input.PublishAt != nil && archiveAt != nil && !archiveAt.After(*input.PublishAt) {
+		return model.Invitation{}, fmt.Errorf("%w: archive_at must be after publish_at", ErrInvalidSchedule)
+	}
+
+	// Like Publish, the plan is checked under the customer's lock so a refund's Downgrade cannot
+	// miss a publish scheduled on the plan from before it.
+	err = s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if input.PublishAt != nil {
+			if err := s.CustomerRepo.LockTx(ctx, tx, customerID); err != nil {
+				return err
+			}
+			limits, err := s.customerLimits(ctx, customerID)
+			if err != nil {
+				return err
+			}
+			if err := s.Enforcer.ValidateContent(ctx, customerID, inv.Content, limits); err != nil {
+				return fmt.Errorf("%w: %w", ErrDraftNotAllowed, err)
+			}
+		}
+		return s.Repo.SetScheduleTx(ctx, tx, invitationID, input.PublishAt, archiveAt)
+	})
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	inv, _, err = s.Repo.GetByID(ctx, invitationID)
+	return inv, err
+}
@@ -2,8 +2,12 @@ package customer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"gorm.io/gorm"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
@@ -12,9 +16,28 @@ import (
 	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
 )
 
+var (
+	ErrInvitationLimitReached = errors.New("invitation limit reached")
+	ErrDraftNotAllowed        = errors.New("draft not allowed by plan")
+	// ErrPlanEnforcerNotConfigured keeps a wiring mistake from silently lifting plan limits.
+	ErrPlanEnforcerNotConfigured = errors.New("plan enforcer not configured")
+)
+
+const maxSlugAttempts = 20
+
 type InvitationService struct {
 	Repo         *repository.InvitationRepository
 	CustomerRepo *repository.CustomerRepository
+	Enforcer     *PlanEnforcer
+}
+
+type CreateInvitationInput struct {
+	CustomerID string
+	Slug       string
+	Title      string
+	EventDate  *time.Time
+	ThemeKey   string
+	Content    []byte
 }
 
 func (s *InvitationService) GetByID(ctx context.Context, id string) (model.Invitation, bool, error) {
@@ -35,6 +58,13 @@ func (s *InvitationService) Update(ctx context.Context, id string, input reposit
 		inputSlug = strings.TrimSpace(current.Slug)
 	}
 	input.Slug = inputSlug
+	input.Author = repository.RevisionAuthor{Type: model.RevisionAuthorCustomer, ID: current.CustomerID}
+
+	limits, err := s.customerLimits(ctx, current.CustomerID)
+	if err != nil {
+		return err
+	}
+	input.KeepRevisions = limits.RevisionHistory
 
 	if err := s.Repo.Update(ctx, id, input); err != nil {
 		return err
@@ -49,18 +79,186 @@ func (s *InvitationService) Update(ctx context.Context, id string, input reposit
 	return nil
 }
 
+// ListForCustomer returns every invitation the customer owns, newest first.
+func (s *InvitationService) ListForCustomer(ctx context.Context, customerID string) ([]model.Invitation, error) {
+	return s.Repo.ListByCustomerID(ctx, customerID)
+}
+
+// Create adds another invitation for the customer, e.g. separate akad and resepsi pages, as long
+// as the plan's max_invitations allows it.
+func (s *InvitationService) Create(ctx context.Context, input CreateInvitationInput) (model.Invitation, error) {
+	if s.CustomerRepo == nil {
+		return model.Invitation{}, fmt.Errorf("customer repository not configured")
+	}
+
+	customer, ok, err := s.CustomerRepo.FindByID(ctx, input.CustomerID)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	if !ok {
+		return model.Invitation{}, ErrInvitationNotFound
+	}
+
+	limits, err := s.customerLimits(ctx, input.CustomerID)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+
+	title := strings.TrimSpace(input.Title)
+	themeKey := strings.TrimSpace(input.ThemeKey)
+	if themeKey == "" {
+		themeKey = "elegant"
+	}
+	searchName := title
+	if searchName == "" {
+		searchName = strings.TrimSpace(customer.FullName)
+	}
+
+	base := slug.Slugify(input.Slug)
+	if base == "" {
+		base = slug.Slugify(title)
+	}
+	if base == "" {
+		base = "undangan"
+	}
+	base = base + "-" + slug.ShortID(input.CustomerID)
+
+	var invitationID string
+	err = s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.CustomerRepo.LockTx(ctx, tx, input.CustomerID); err != nil {
+			return err
+		}
+
+		count, err := s.Repo.CountByCustomerTx(ctx, tx, input.CustomerID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(limits.MaxInvitations) {
+			return fmt.Errorf("%w (max %d)", ErrInvitationLimitReached, limits.MaxInvitations)
+		}
+
+		invitationSlug, err := s.availableSlug(ctx, tx, input.CustomerID, base)
+		if err != nil {
+			return err
+		}
+
+		invitationID, err = s.Repo.CreateTx(ctx, tx, repository.InvitationCreateInput{
+			CustomerID:  input.CustomerID,
+			Slug:        invitationSlug,
+			Title:       title,
+			SearchName:  searchName,
+			EventDate:   input.EventDate,
+			ThemeKey:    themeKey,
+			IsPublished: false,
+			Content:     content.Normalize(input.Content, customer.FullName),
+			Author:      repository.RevisionAuthor{Type: model.RevisionAuthorCustomer, ID: input.CustomerID},
+		})
+		return err
+	})
+	if err != nil {
+		return model.Invitation{}, err
+	}
+
+	inv, _, err := s.Repo.GetByID(ctx, invitationID)
+	return inv, err
+}
+
+// availableSlug appends a counter to base until it no longer collides with another invitation of
+// the same customer.
+func (s *InvitationService) availableSlug(ctx context.Context, tx *gorm.DB, customerID, base string) (string, error) {
+	candidate := base
+	for i := 2; i <= maxSlugAttempts+1; i++ {
+		exists, err := s.Repo.ExistsByCustomerAndSlugTx(ctx, tx, customerID, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", fmt.Errorf("no free slug for %s", base)
+}
+
+// GetPublishedContent returns the snapshot guests see, never the draft being edited.
 func (s *InvitationService) GetPublishedContent(ctx context.Context, customerID, slug string) (map[string]any, bool, error) {
 	inv, ok, err := s.Repo.FindPublishedByCustomerAndSlug(ctx, customerID, slug)
 	if err != nil || !ok {
 		return nil, ok, err
 	}
-	decoded, err := content.Decode(inv.Content)
+	decoded, err := content.Decode(inv.PublishedContent)
 	if err != nil {
 		return nil, false, err
 	}
 	return decoded, true, nil
 }
 
+// GetDraftContent returns the draft of the customer's invitation for the editor preview.
+func (s *InvitationService) GetDraftContent(ctx context.Context, customerID, invitationID string) (map[string]any, error) {
+	inv, ok, err := s.Repo.GetByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || inv.CustomerID != customerID {
+		return nil, ErrInvitationNotFound
+	}
+	return content.Decode(inv.Content)
+}
+
+// Publish makes the current draft what guests see.
+func (s *InvitationService) Publish(ctx context.Context, customerID, invitationID string) (model.Invitation, error) {
+	inv, ok, err := s.Repo.GetByID(ctx, invitationID)
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	if !ok || inv.CustomerID != customerID {
+		return model.Invitation{}, ErrInvitationNotFound
+	}
+
+	// The plan is checked under the customer's lock, which a refund's Downgrade also takes, so a
+	// publish racing a refund either lands before the downgrade or sees the plan after it.
+	err = s.Repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.CustomerRepo.LockTx(ctx, tx, customerID); err != nil {
+			return err
+		}
+		limits, err := s.customerLimits(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if err := s.Enforcer.ValidateContent(ctx, customerID, inv.Content, limits); err != nil {
+			return fmt.Errorf("%w: %w", ErrDraftNotAllowed, err)
+		}
+		_, err = s.Repo.PublishTx(ctx, tx, invitationID)
+		return err
+	})
+	if err != nil {
+		return model.Invitation{}, err
+	}
+	s.Repo.ForgetCached(invitationID)
+
+	inv, _, err = s.Repo.GetByID(ctx, invitationID)
+	return inv, err
+}
+
+// Unpublish takes the invitation offline; the draft and last published snapshot are kept.
+func (s *InvitationService) Unpublish(ctx context.Context, customerID, invitationID string) (model.Invitation, error) {
+	if err := ensureInvitationOwner(ctx, s.Repo, customerID, invitationID); err != nil {
+		return model.Invitation{}, err
+	}
+	if _, err := s.Repo.Unpublish(ctx, invitationID); err != nil {
+		return model.Invitation{}, err
+	}
+	inv, _, err := s.Repo.GetByID(ctx, invitationID)
+	return inv, err
+}
+
+func (s *InvitationService) customerLimits(ctx context.Context, customerID string) (PlanLimits, error) {
+	if s.Enforcer == nil {
+		return PlanLimits{}, ErrPlanEnforcerNotConfigured
+	}
+	return s.Enforcer.GetCustomerLimits(ctx, customerID)
+}
+
 func (s *InvitationService) normalizeListFilters(filters query.InvitationListFilters) query.InvitationListFilters {
 	if filters.Limit <= 0 {
 		filters.Limit = 20
@@ -78,6 +276,18 @@ func (s *InvitationService) List(ctx context.Context, filters query.InvitationLi
 	return s.Repo.List(ctx, s.normalizeListFilters(filters))
 }
 
+// ensureInvitationOwner returns ErrInvitationNotFound unless the invitation belongs to the customer.
+func ensureInvitationOwner(ctx context.Context, repo *repository.InvitationRepository, customerID, invitationID string) error {
+	inv, ok, err := repo.GetByID(ctx, strings.TrimSpace(invitationID))
+	if err != nil {
+		return err
+	}
+	if !ok || inv.CustomerID != customerID {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
 func (s *InvitationService) syncDomainWithSlug(ctx context.Context, customerID, oldSlug, newSlug string) error {
 	oldSlug = strings.TrimSpace(oldSlug)
 	newSlug = strings.TrimSpace(newSlug)
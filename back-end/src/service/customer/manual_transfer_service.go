@@ -0,0 +1,117 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/imaging"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+// MaxReceiptBytes bounds transfer receipt uploads; a phone screenshot is well below it. The form
+// must also fit the router's 5 MB body limit.
+const MaxReceiptBytes = 4 << 20
+
+var (
+	ErrManualTransferNotConfigured = errors.New("manual transfer not configured")
+	ErrUnsupportedReceipt          = errors.New("receipt must be a JPEG, PNG or WebP image")
+	ErrReceiptTooLarge             = errors.New("receipt file too large")
+)
+
+// ManualTransferAccount is the bank account customers transfer to.
+type ManualTransferAccount struct {
+	BankName      string
+	AccountNumber string
+	AccountName   string
+}
+
+type CreateManualTransferInput struct {
+	CustomerID string
+	PlanCode   string
+	Receipt    MediaUpload
+}
+
+// ManualTransferInstructions returns the account to transfer to; plan prices come from the plan
+// list.
+func (s *PaymentService) ManualTransferInstructions() (ManualTransferAccount, error) {
+	if s.ManualTransfer == nil {
+		return ManualTransferAccount{}, ErrManualTransferNotConfigured
+	}
+	return *s.ManualTransfer, nil
+}
+
+// CreateManualTransfer records a bank transfer for the plan with the customer's receipt. The
+// payment waits in awaiting_review until an admin approves or rejects it.
+func (s *PaymentService) CreateManualTransfer(ctx context.Context, input CreateManualTransferInput) (model.Payment, error) {
+	if s.CustomerRepo == nil || s.PlanRepo == nil || s.PaymentRepo == nil || s.Receipts == nil {
+		return model.Payment{}, ErrPaymentServiceNotConfigured
+	}
+	if s.ManualTransfer == nil {
+		return model.Payment{}, ErrManualTransferNotConfigured
+	}
+
+	if len(input.Receipt.Data) > MaxReceiptBytes {
+		return model.Payment{}, fmt.Errorf("%w (max %d MB)", ErrReceiptTooLarge, MaxReceiptBytes>>20)
+	}
+	detected, ok := sniffMedia(input.Receipt.Data)
+	if !ok || detected.Kind != model.MediaKindImage || detected.ContentType == "image/gif" {
+		return model.Payment{}, ErrUnsupportedReceipt
+	}
+
+	customer, ok, err := s.CustomerRepo.FindByID(ctx, strings.TrimSpace(input.CustomerID))
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if !ok {
+		return model.Payment{}, ErrCustomerNotFound
+	}
+
+	plan, ok, err := s.PlanRepo.FindByCode(ctx, strings.TrimSpace(input.PlanCode))
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if !ok {
+		return model.Payment{}, ErrPlanNotFound
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(plan.Currency))
+	if currency == "" {
+		currency = "IDR"
+	}
+
+	data, err := imaging.StripMetadata(detected.ContentType, input.Receipt.Data)
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("%w: %v", ErrUnsupportedReceipt, err)
+	}
+	key, err := newMediaKey(customer.ID, detected.Ext)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	key = "receipts/" + key
+	if err := s.Receipts.Put(ctx, key, detected.ContentType, data); err != nil {
+		return model.Payment{}, err
+	}
+
+	paymentID, err := s.PaymentRepo.Create(ctx, repository.PaymentCreateInput{
+		CustomerID:     customer.ID,
+		PlanID:         plan.ID,
+		Amount:         plan.PriceAmount,
+		Currency:       currency,
+		ProofOfPayment: key,
+		Status:         model.PaymentStatusAwaitingReview,
+		Provider:       model.PaymentProviderManualTransfer,
+	})
+	if err != nil {
+		if delErr := s.Receipts.Delete(context.Background(), key); delErr != nil {
+			log.Printf("payments: remove orphaned receipt %s: %v", key, delErr)
+		}
+		return model.Payment{}, err
+	}
+
+	payment, _, err := s.PaymentRepo.GetByID(ctx, paymentID)
+	return payment, err
+}
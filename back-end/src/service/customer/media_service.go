@@ -0,0 +1,267 @@
+package customer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/imaging"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
+)
+
+var (
+	ErrMediaNotFound    = errors.New("media not found")
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	ErrMediaTooLarge    = errors.New("media file too large")
+	ErrMediaNotAllowed  = errors.New("media type not included in your plan")
+	ErrMediaInUse       = errors.New("media is still used by an invitation")
+)
+
+type MediaService struct {
+	Repo           *repository.MediaRepository
+	InvitationRepo *repository.InvitationRepository
+	Storage        storage.Storage
+	Enforcer       *PlanEnforcer
+	// Queue renders resized variants of uploaded photos in the background.
+	Queue MediaQueue
+}
+
+type MediaQueue interface {
+	Enqueue(mediaID string) bool
+}
+
+type MediaUpload struct {
+	Filename string
+	Data     []byte
+}
+
+type mediaType struct {
+	Kind        string
+	ContentType string
+	Ext         string
+}
+
+// sniffedMediaTypes maps what http.DetectContentType reports to the files customers may upload.
+// The declared Content-Type of the upload is never trusted.
+var sniffedMediaTypes = map[string]mediaType{
+	"image/jpeg":      {Kind: model.MediaKindImage, ContentType: "image/jpeg", Ext: ".jpg"},
+	"image/png":       {Kind: model.MediaKindImage, ContentType: "image/png", Ext: ".png"},
+	"image/gif":       {Kind: model.MediaKindImage, ContentType: "image/gif", Ext: ".gif"},
+	"image/webp":      {Kind: model.MediaKindImage, ContentType: "image/webp", Ext: ".webp"},
+	"audio/mpeg":      {Kind: model.MediaKindAudio, ContentType: "audio/mpeg", Ext: ".mp3"},
+	"application/ogg": {Kind: model.MediaKindAudio, ContentType: "audio/ogg", Ext: ".ogg"},
+	"audio/wave":      {Kind: model.MediaKindAudio, ContentType: "audio/wav", Ext: ".wav"},
+}
+
+// Upload stores a photo or music file for the customer after checking its real type and the
+// plan's size limit. Photos are stored without their location and camera metadata and get their
+// resized variants shortly after.
+func (s *MediaService) Upload(ctx context.Context, customerID string, upload MediaUpload) (model.Media, error) {
+	detected, ok := sniffMedia(upload.Data)
+	if !ok {
+		return model.Media{}, ErrUnsupportedMedia
+	}
+
+	limits, err := s.Enforcer.GetCustomerLimits(ctx, customerID)
+	if err != nil {
+		return model.Media{}, err
+	}
+	if limit := int64(limits.UploadMaxMB) << 20; int64(len(upload.Data)) > limit {
+		return model.Media{}, fmt.Errorf("%w (max %d MB)", ErrMediaTooLarge, limits.UploadMaxMB)
+	}
+	if detected.Kind == model.MediaKindAudio && !limits.Music {
+		return model.Media{}, fmt.Errorf("%w: music not included in your plan", ErrMediaNotAllowed)
+	}
+
+	data := upload.Data
+	status := model.MediaStatusReady
+	if detected.Kind == model.MediaKindImage {
+		data, err = imaging.StripMetadata(detected.ContentType, upload.Data)
+		if err != nil {
+			return model.Media{}, fmt.Errorf("%w: %v", ErrUnsupportedMedia, err)
+		}
+		status = model.MediaStatusPending
+	}
+
+	key, err := newMediaKey(customerID, detected.Ext)
+	if err != nil {
+		return model.Media{}, err
+	}
+	if err := s.Storage.Put(ctx, key, detected.ContentType, data); err != nil {
+		return model.Media{}, err
+	}
+
+	item, err := s.Repo.Create(ctx, repository.MediaCreateInput{
+		CustomerID:       customerID,
+		Kind:             detected.Kind,
+		ContentType:      detected.ContentType,
+		SizeBytes:        int64(len(data)),
+		StorageKey:       key,
+		URL:              s.Storage.URL(key),
+		OriginalName:     originalName(upload.Filename),
+		ProcessingStatus: status,
+	})
+	if err != nil {
+		if delErr := s.Storage.Delete(context.Background(), key); delErr != nil {
+			log.Printf("media: remove orphaned %s: %v", key, delErr)
+		}
+		return model.Media{}, err
+	}
+
+	if status == model.MediaStatusPending && s.Queue != nil {
+		s.Queue.Enqueue(item.ID)
+	}
+	return item, nil
+}
+
+func (s *MediaService) List(ctx context.Context, customerID string) ([]model.Media, error) {
+	return s.Repo.ListByCustomer(ctx, customerID)
+}
+
+// Delete removes an uploaded file the customer no longer uses. Files still embedded in a draft
+// or published invitation are kept so guests never see a broken image.
+func (s *MediaService) Delete(ctx context.Context, customerID, mediaID string) error {
+	item, ok, err := s.Repo.GetByIDAndCustomer(ctx, mediaID, customerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMediaNotFound
+	}
+
+	if s.InvitationRepo != nil {
+		used, err := s.InvitationRepo.ReferencesText(ctx, customerID, item.URL)
+		if err != nil {
+			return err
+		}
+		if used {
+			return ErrMediaInUse
+		}
+	}
+
+	// The row goes first: a worker that finishes afterwards finds it gone and removes its own
+	// variants, while variants stored before are returned by the delete.
+	deleted, ok, err := s.Repo.Delete(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMediaNotFound
+	}
+
+	keys := []string{deleted.StorageKey}
+	for _, variant := range DecodeMediaVariants(deleted) {
+		keys = append(keys, variant.StorageKey)
+	}
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResponsiveImages returns the processed uploads that published content embeds, keyed by the
+// URL used in the content, so guests' browsers can pick a variant that fits the screen.
+func (s *MediaService) ResponsiveImages(ctx context.Context, customerID string, doc map[string]any) (map[string]model.Media, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0)
+	for _, asset := range content.DecodeAssets(raw) {
+		if _, ok := s.Storage.KeyFromURL(asset.URL); ok {
+			urls = append(urls, asset.URL)
+		}
+	}
+
+	items, err := s.Repo.FindReadyByURLs(ctx, customerID, urls)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]model.Media, len(items))
+	for _, item := range items {
+		if item.Kind == model.MediaKindImage {
+			out[item.URL] = item
+		}
+	}
+	return out, nil
+}
+
+// DecodeMediaVariants returns the resized variants of a processed photo, smallest first.
+func DecodeMediaVariants(item model.Media) []model.MediaVariant {
+	variants := make([]model.MediaVariant, 0)
+	if len(item.Variants) == 0 {
+		return variants
+	}
+	if err := json.Unmarshal(item.Variants, &variants); err != nil {
+		return []model.MediaVariant{}
+	}
+	return variants
+}
+
+func sniffMedia(data []byte) (mediaType, bool) {
+	if len(data) == 0 {
+		return mediaType{}, false
+	}
+
+	detected := http.DetectContentType(data)
+	if i := strings.IndexByte(detected, ';'); i >= 0 {
+		detected = detected[:i]
+	}
+	if t, ok := sniffedMediaTypes[detected]; ok {
+		return t, true
+	}
+
+	// DetectContentType only recognises MP3 files that start with an ID3 tag; most encoders
+	// also write bare MPEG audio frames.
+	if isMPEGAudioFrame(data) {
+		return sniffedMediaTypes["audio/mpeg"], true
+	}
+	// M4A files are MP4 containers that DetectContentType reports as video.
+	if len(data) >= 12 && bytes.Equal(data[4:8], []byte("ftyp")) && bytes.HasPrefix(data[8:12], []byte("M4A")) {
+		return mediaType{Kind: model.MediaKindAudio, ContentType: "audio/mp4", Ext: ".m4a"}, true
+	}
+	return mediaType{}, false
+}
+
+func isMPEGAudioFrame(data []byte) bool {
+	if len(data) < 3 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (data[1] >> 3) & 0x03
+	layer := (data[1] >> 1) & 0x03
+	bitrate := data[2] >> 4
+	return version != 0x01 && layer == 0x01 && bitrate != 0x00 && bitrate != 0x0F
+}
+
+func newMediaKey(customerID, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return customerID + "/" + hex.EncodeToString(buf) + ext, nil
+}
+
+func originalName(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
@@ -2,24 +2,34 @@ package customer
 
 import (
 	"context"
-	"crypto/sha512"
+	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
 )
 
 type PaymentService struct {
 	CustomerRepo *repository.CustomerRepository
 	PlanRepo     *repository.PlanRepository
 	PaymentRepo  *repository.PaymentRepository
-	Midtrans     *external.MidtransService
+	// Gateways are the configured payment providers; webhooks and progress checks work for all of
+	// them, new checkouts go through CheckoutProvider.
+	Gateways         []external.PaymentGateway
+	CheckoutProvider string
+	// ManualTransfer is the bank account for manual transfers; nil disables them. Receipts go to
+	// the private Receipts store, never to public media storage.
+	ManualTransfer *ManualTransferAccount
+	Receipts       storage.Storage
+	// Enforcer takes content the customer's plan no longer allows offline after a refund.
+	Enforcer *PlanEnforcer
 }
 
 type CreatePaymentInput struct {
@@ -28,14 +38,15 @@ type CreatePaymentInput struct {
 }
 
 type CreatePaymentResult struct {
-	PaymentID         string
-	Status            string
-	Amount            int
-	Currency          string
-	MidtransOrderID   string
-	MidtransClientKey string
-	MidtransToken     string
-	MidtransRedirect  string
+	PaymentID   string
+	Status      string
+	Amount      int
+	Currency    string
+	Provider    string
+	OrderID     string
+	ClientKey   string
+	Token       string
+	RedirectURL string
 }
 
 type PaymentProgressInput struct {
@@ -44,24 +55,24 @@ type PaymentProgressInput struct {
 }
 
 type PaymentProgressResult struct {
-	PaymentID       string
-	Status          string
-	MidtransStatus  string
-	PaidAt          *time.Time
-	MidtransOrderID string
-	RedirectURL     string
+	PaymentID      string
+	Status         string
+	Provider       string
+	ProviderStatus string
+	PaidAt         *time.Time
+	OrderID        string
+	RedirectURL    string
+	ReviewNote     string
 }
 
-type MidtransWebhookInput struct {
-	OrderID           string
-	TransactionStatus string
-	FraudStatus       string
-	StatusCode        string
-	GrossAmount       string
-	SignatureKey      string
+// PaymentWebhookInput is a notification as received on the provider's webhook route.
+type PaymentWebhookInput struct {
+	Provider string
+	Header   http.Header
+	Body     []byte
 }
 
-type MidtransWebhookResult struct {
+type PaymentWebhookResult struct {
 	PaymentID string
 	Status    string
 	PaidAt    *time.Time
@@ -69,31 +80,29 @@ type MidtransWebhookResult struct {
 
 var (
 	ErrPaymentServiceNotConfigured = errors.New("payment service not configured")
-	ErrMidtransNotConfigured       = errors.New("midtrans not configured")
+	ErrPaymentGatewayNotConfigured = errors.New("payment gateway not configured")
 	ErrCustomerNotFound            = errors.New("customer not found")
 	ErrPlanNotFound                = errors.New("plan not found")
 	ErrPaymentNotFound             = errors.New("payment not found")
-	ErrMidtransOrderNotFound       = errors.New("midtrans order id not found")
-	ErrInvalidMidtransSignature    = errors.New("invalid midtrans signature")
+	ErrPaymentOrderNotFound        = errors.New("payment order id not found")
+	ErrPaymentNotRefundable        = errors.New("only paid payments can be refunded")
+	ErrRefundFailed                = external.ErrRefundFailed
+	ErrInvalidWebhookSignature     = external.ErrInvalidWebhookSignature
+	ErrInvalidWebhookPayload       = external.ErrInvalidWebhookPayload
 )
 
-type paymentMeta struct {
-	Provider    string `json:"provider"`
-	OrderID     string `json:"order_id"`
-	RedirectURL string `json:"redirect_url,omitempty"`
-	SnapToken   string `json:"snap_token,omitempty"`
-	PlanCode    string `json:"plan_code,omitempty"`
-	PlanName    string `json:"plan_name,omitempty"`
-	Amount      int    `json:"amount,omitempty"`
-	Currency    string `json:"currency,omitempty"`
-}
+const (
+	PaymentProviderMidtrans = external.PaymentProviderMidtrans
+	PaymentProviderXendit   = external.PaymentProviderXendit
+)
 
 func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (CreatePaymentResult, error) {
 	if s.CustomerRepo == nil || s.PlanRepo == nil || s.PaymentRepo == nil {
 		return CreatePaymentResult{}, ErrPaymentServiceNotConfigured
 	}
-	if s.Midtrans == nil {
-		return CreatePaymentResult{}, ErrMidtransNotConfigured
+	gateway, err := s.gateway(s.CheckoutProvider)
+	if err != nil {
+		return CreatePaymentResult{}, err
 	}
 
 	customer, ok, err := s.CustomerRepo.FindByID(ctx, strings.TrimSpace(input.CustomerID))
@@ -118,7 +127,7 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 	}
 
 	orderID := buildOrderID(customer.ID)
-	midtransResult, err := s.Midtrans.CreateTransaction(ctx, external.MidtransCreateTransactionInput{
+	transaction, err := gateway.CreateTransaction(ctx, external.GatewayTransactionInput{
 		OrderID:  orderID,
 		Amount:   plan.PriceAmount,
 		Currency: currency,
@@ -131,41 +140,31 @@ func (s *PaymentService) Create(ctx context.Context, input CreatePaymentInput) (
 		return CreatePaymentResult{}, err
 	}
 
-	metaRaw, err := marshalPaymentMeta(paymentMeta{
-		Provider:    "midtrans",
-		OrderID:     orderID,
-		RedirectURL: midtransResult.RedirectURL,
-		SnapToken:   midtransResult.Token,
-		PlanCode:    plan.Code,
-		PlanName:    plan.Name,
-		Amount:      plan.PriceAmount,
-		Currency:    currency,
-	})
-	if err != nil {
-		return CreatePaymentResult{}, err
-	}
-
 	paymentID, err := s.PaymentRepo.Create(ctx, repository.PaymentCreateInput{
-		CustomerID:     customer.ID,
-		PlanID:         plan.ID,
-		Amount:         plan.PriceAmount,
-		Currency:       currency,
-		ProofOfPayment: metaRaw,
-		Status:         "pending",
+		CustomerID:            customer.ID,
+		PlanID:                plan.ID,
+		Amount:                plan.PriceAmount,
+		Currency:              currency,
+		Status:                external.PaymentStatusPending,
+		Provider:              gateway.Provider(),
+		ProviderOrderID:       orderID,
+		ProviderTransactionID: transaction.TransactionID,
+		RedirectURL:           transaction.RedirectURL,
 	})
 	if err != nil {
 		return CreatePaymentResult{}, err
 	}
 
 	return CreatePaymentResult{
-		PaymentID:         paymentID,
-		Status:            "pending",
-		Amount:            plan.PriceAmount,
-		Currency:          currency,
-		MidtransOrderID:   orderID,
-		MidtransClientKey: s.Midtrans.ClientKey(),
-		MidtransToken:     midtransResult.Token,
-		MidtransRedirect:  midtransResult.RedirectURL,
+		PaymentID:   paymentID,
+		Status:      external.PaymentStatusPending,
+		Amount:      plan.PriceAmount,
+		Currency:    currency,
+		Provider:    gateway.Provider(),
+		OrderID:     orderID,
+		ClientKey:   transaction.ClientKey,
+		Token:       transaction.Token,
+		RedirectURL: transaction.RedirectURL,
 	}, nil
 }
 
@@ -173,9 +172,6 @@ func (s *PaymentService) Progress(ctx context.Context, input PaymentProgressInpu
 	if s.PaymentRepo == nil || s.CustomerRepo == nil {
 		return PaymentProgressResult{}, ErrPaymentServiceNotConfigured
 	}
-	if s.Midtrans == nil {
-		return PaymentProgressResult{}, ErrMidtransNotConfigured
-	}
 
 	customerID := strings.TrimSpace(input.CustomerID)
 	if customerID == "" {
@@ -201,98 +197,149 @@ func (s *PaymentService) Progress(ctx context.Context, input PaymentProgressInpu
 		return PaymentProgressResult{}, ErrPaymentNotFound
 	}
 
-	meta, err := parsePaymentMeta(payment.ProofOfPayment)
-	if err != nil {
-		return PaymentProgressResult{}, err
-	}
-	if strings.TrimSpace(meta.OrderID) == "" {
-		return PaymentProgressResult{}, ErrMidtransOrderNotFound
+	// Manual transfers only change when an admin reviews them.
+	if payment.Provider == model.PaymentProviderManualTransfer {
+		return PaymentProgressResult{
+			PaymentID:  payment.ID,
+			Status:     payment.Status,
+			Provider:   payment.Provider,
+			PaidAt:     payment.PaidAt,
+			ReviewNote: payment.ReviewNote,
+		}, nil
 	}
 
-	statusResult, err := s.Midtrans.GetTransactionStatus(ctx, meta.OrderID)
+	orderID := payment.OrderID()
+	if orderID == "" {
+		return PaymentProgressResult{}, ErrPaymentOrderNotFound
+	}
+	gateway, err := s.gateway(payment.Provider)
 	if err != nil {
 		return PaymentProgressResult{}, err
 	}
 
-	normalizedStatus := normalizeMidtransStatus(statusResult.TransactionStatus, statusResult.FraudStatus)
-	paidAt := paidAtForStatus(normalizedStatus, payment.PaidAt)
-
-	if err := s.PaymentRepo.UpdateStatus(ctx, payment.ID, normalizedStatus, paidAt); err != nil {
+	status, err := gateway.GetTransactionStatus(ctx, orderID)
+	if err != nil {
 		return PaymentProgressResult{}, err
 	}
 
-	if normalizedStatus == "paid" {
-		if err := s.CustomerRepo.UpdateStatus(ctx, customerID, "paid"); err != nil {
-			return PaymentProgressResult{}, err
-		}
+	payment, _, err = s.ApplyStatus(ctx, payment.ID, status, model.PaymentEvent{Source: model.PaymentEventSourcePoll})
+	if err != nil {
+		return PaymentProgressResult{}, err
 	}
 
 	return PaymentProgressResult{
-		PaymentID:       payment.ID,
-		Status:          normalizedStatus,
-		MidtransStatus:  strings.TrimSpace(statusResult.TransactionStatus),
-		PaidAt:          paidAt,
-		MidtransOrderID: meta.OrderID,
-		RedirectURL:     meta.RedirectURL,
+		PaymentID:      payment.ID,
+		Status:         payment.Status,
+		Provider:       payment.Provider,
+		ProviderStatus: status.RawStatus,
+		PaidAt:         payment.PaidAt,
+		OrderID:        orderID,
+		RedirectURL:    payment.RedirectURL,
 	}, nil
 }
 
-func (s *PaymentService) HandleMidtransWebhook(ctx context.Context, input MidtransWebhookInput) (MidtransWebhookResult, error) {
-	if s.PaymentRepo == nil || s.CustomerRepo == nil || s.Midtrans == nil {
-		return MidtransWebhookResult{}, ErrPaymentServiceNotConfigured
+// HandleWebhook applies a status notification after the provider's gateway has verified it. A
+// retried notification is acknowledged without being applied again.
+func (s *PaymentService) HandleWebhook(ctx context.Context, input PaymentWebhookInput) (PaymentWebhookResult, error) {
+	if s.PaymentRepo == nil || s.CustomerRepo == nil {
+		return PaymentWebhookResult{}, ErrPaymentServiceNotConfigured
 	}
-
-	if !verifyMidtransSignature(input, s.Midtrans.ServerKey()) {
-		return MidtransWebhookResult{}, ErrInvalidMidtransSignature
+	gateway, err := s.gateway(input.Provider)
+	if err != nil {
+		return PaymentWebhookResult{}, err
 	}
 
-	orderID := strings.TrimSpace(input.OrderID)
-	if orderID == "" {
-		return MidtransWebhookResult{}, ErrMidtransOrderNotFound
+	status, err := gateway.VerifyWebhook(input.Header, input.Body)
+	if err != nil {
+		return PaymentWebhookResult{}, err
+	}
+	if status.OrderID == "" {
+		return PaymentWebhookResult{}, ErrPaymentOrderNotFound
 	}
 
-	payment, ok, err := s.PaymentRepo.GetByMidtransOrderID(ctx, orderID)
+	payment, ok, err := s.PaymentRepo.GetByProviderOrderID(ctx, gateway.Provider(), status.OrderID)
 	if err != nil {
-		return MidtransWebhookResult{}, err
+		return PaymentWebhookResult{}, err
 	}
 	if !ok {
-		return MidtransWebhookResult{}, ErrPaymentNotFound
-	}
-
-	normalizedStatus := normalizeMidtransStatus(input.TransactionStatus, input.FraudStatus)
-	paidAt := paidAtForStatus(normalizedStatus, payment.PaidAt)
-
-	if err := s.PaymentRepo.UpdateStatus(ctx, payment.ID, normalizedStatus, paidAt); err != nil {
-		return MidtransWebhookResult{}, err
+		return PaymentWebhookResult{}, ErrPaymentNotFound
 	}
 
-	if normalizedStatus == "paid" {
-		if err := s.CustomerRepo.UpdateStatus(ctx, payment.CustomerID, "paid"); err != nil {
-			return MidtransWebhookResult{}, err
-		}
+	// Gateways resend a notification unchanged until it is acknowledged, so its body identifies
+	// it across retries.
+	digest := sha256.Sum256(input.Body)
+	eventKey := hex.EncodeToString(digest[:])
+	payment, _, err = s.ApplyStatus(ctx, payment.ID, status, model.PaymentEvent{
+		Source:   model.PaymentEventSourceWebhook,
+		EventKey: &eventKey,
+		Payload:  strings.ToValidUTF8(string(input.Body), "\uFFFD"),
+	})
+	if err != nil {
+		return PaymentWebhookResult{}, err
 	}
 
-	return MidtransWebhookResult{
+	return PaymentWebhookResult{
 		PaymentID: payment.ID,
-		Status:    normalizedStatus,
-		PaidAt:    paidAt,
+		Status:    payment.Status,
+		PaidAt:    payment.PaidAt,
 	}, nil
 }
 
-func verifyMidtransSignature(input MidtransWebhookInput, serverKey string) bool {
-	serverKey = strings.TrimSpace(serverKey)
-	if serverKey == "" {
-		return false
+// Refund refunds a paid payment through its gateway on behalf of the admin actorID and marks it
+// refunded, which revokes the plan unless the customer has another paid payment. Manual
+// transfers are refunded by bank transfer outside the app, so only their status changes.
+func (s *PaymentService) Refund(ctx context.Context, paymentID, actorID, reason string) (model.Payment, error) {
+	if s.PaymentRepo == nil || s.CustomerRepo == nil {
+		return model.Payment{}, ErrPaymentServiceNotConfigured
+	}
+
+	payment, ok, err := s.PaymentRepo.GetByID(ctx, strings.TrimSpace(paymentID))
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if !ok {
+		return model.Payment{}, ErrPaymentNotFound
+	}
+	if payment.Status != external.PaymentStatusPaid {
+		return model.Payment{}, ErrPaymentNotRefundable
+	}
+
+	reason = strings.TrimSpace(reason)
+	if payment.Provider != model.PaymentProviderManualTransfer {
+		gateway, err := s.gateway(payment.Provider)
+		if err != nil {
+			return model.Payment{}, err
+		}
+		// Gateways deduplicate refunds of the same order, so two admins refunding at once
+		// only return the money once.
+		if err := gateway.Refund(ctx, external.GatewayRefundInput{
+			OrderID:       payment.OrderID(),
+			TransactionID: payment.ProviderTransactionID,
+			Amount:        payment.Amount,
+			Reason:        reason,
+		}); err != nil {
+			return model.Payment{}, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
 	}
-	signatureKey := strings.ToLower(strings.TrimSpace(input.SignatureKey))
-	if signatureKey == "" {
-		return false
+
+	event := model.PaymentEvent{Source: model.PaymentEventSourceRefund, Payload: reason}
+	if actorID = strings.TrimSpace(actorID); actorID != "" {
+		event.ActorID = &actorID
 	}
+	payment, _, err = s.ApplyStatus(ctx, payment.ID, external.GatewayStatus{
+		RawStatus: external.PaymentStatusRefunded,
+		Status:    external.PaymentStatusRefunded,
+	}, event)
+	return payment, err
+}
 
-	raw := strings.TrimSpace(input.OrderID) + strings.TrimSpace(input.StatusCode) + strings.TrimSpace(input.GrossAmount) + serverKey
-	hash := sha512.Sum512([]byte(raw))
-	expected := hex.EncodeToString(hash[:])
-	return signatureKey == strings.ToLower(expected)
+func (s *PaymentService) gateway(provider string) (external.PaymentGateway, error) {
+	for _, gateway := range s.Gateways {
+		if gateway.Provider() == provider {
+			return gateway, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPaymentGatewayNotConfigured, provider)
 }
 
 func buildOrderID(customerID string) string {
@@ -306,31 +353,8 @@ func buildOrderID(customerID string) string {
 	return fmt.Sprintf("WED-%s-%d", cleaned, time.Now().UnixNano())
 }
 
-func normalizeMidtransStatus(transactionStatus, fraudStatus string) string {
-	status := strings.ToLower(strings.TrimSpace(transactionStatus))
-	fraud := strings.ToLower(strings.TrimSpace(fraudStatus))
-
-	switch status {
-	case "capture":
-		if fraud == "challenge" {
-			return "pending"
-		}
-		return "paid"
-	case "settlement":
-		return "paid"
-	case "pending":
-		return "pending"
-	case "deny", "cancel", "expire", "failure":
-		return "failed"
-	case "refund", "partial_refund", "chargeback", "partial_chargeback":
-		return "refunded"
-	default:
-		return "pending"
-	}
-}
-
 func paidAtForStatus(status string, existing *time.Time) *time.Time {
-	if status != "paid" {
+	if status != external.PaymentStatusPaid {
 		return nil
 	}
 	if existing != nil {
@@ -339,29 +363,3 @@ func paidAtForStatus(status string, existing *time.Time) *time.Time {
 	now := time.Now().UTC()
 	return &now
 }
-
-func marshalPaymentMeta(meta paymentMeta) (string, error) {
-	encoded, err := json.Marshal(meta)
-	if err != nil {
-		return "", fmt.Errorf("encode payment meta: %w", err)
-	}
-	return string(encoded), nil
-}
-
-func parsePaymentMeta(raw string) (paymentMeta, error) {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return paymentMeta{}, nil
-	}
-
-	var meta paymentMeta
-	if err := json.Unmarshal([]byte(raw), &meta); err == nil {
-		return meta, nil
-	}
-
-	if strings.HasPrefix(raw, "midtrans:") {
-		return paymentMeta{Provider: "midtrans", OrderID: strings.TrimPrefix(raw, "midtrans:")}, nil
-	}
-
-	return paymentMeta{Provider: "midtrans", OrderID: raw}, nil
-}
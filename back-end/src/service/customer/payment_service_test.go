@@ -0,0 +1,191 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+)
+
+// fakeGateway records refunds and fails them with refundErr.
+type fakeGateway struct {
+	refundErr error
+	refunds   []external.GatewayRefundInput
+}
+
+func (g *fakeGateway) Provider() string { return "midtrans" }
+
+func (g *fakeGateway) CreateTransaction(context.Context, external.GatewayTransactionInput) (external.GatewayTransaction, error) {
+	return external.GatewayTransaction{}, errors.New("not used")
+}
+
+func (g *fakeGateway) GetTransactionStatus(context.Context, string) (external.GatewayStatus, error) {
+	return external.GatewayStatus{}, errors.New("not used")
+}
+
+func (g *fakeGateway) VerifyWebhook(http.Header, []byte) (external.GatewayStatus, error) {
+	return external.GatewayStatus{}, errors.New("not used")
+}
+
+func (g *fakeGateway) Refund(_ context.Context, input external.GatewayRefundInput) error {
+	g.refunds = append(g.refunds, input)
+	return g.refundErr
+}
+
+func newRefundService(t *testing.T, gateway *fakeGateway) (*PaymentService, sqlmock.Sqlmock) {
+	t.Helper()
+	svc, mock := newPaymentStateService(t)
+	db := svc.PaymentRepo.DB
+	svc.Gateways = []external.PaymentGateway{gateway}
+	svc.Enforcer = &PlanEnforcer{
+		PaymentRepo:    svc.PaymentRepo,
+		InvitationRepo: &repository.InvitationRepository{DB: db},
+		DomainRepo:     &repository.CustomDomainRepository{DB: db},
+		CustomerRepo:   svc.CustomerRepo,
+	}
+	return svc, mock
+}
+
+func expectPayment(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`SELECT \* FROM "payments" WHERE id = \$1 ORDER BY`).
+		WithArgs(testPaymentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "amount", "status", "provider", "provider_order_id", "provider_transaction_id"}).
+			AddRow(testPaymentID, testCustomerID, 99000, status, "midtrans", "ORDER-1", "trx-1"))
+}
+
+// expectRefundCommit expects a paid payment to be marked refunded, which leaves its customer
+// without a paid payment.
+func expectRefundCommit(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	expectLockedPayment(mock, external.PaymentStatusPaid)
+	expectPaymentEvent(mock, model.PaymentEventSourceRefund, external.PaymentStatusPaid, external.PaymentStatusRefunded, true, false)
+	mock.ExpectExec(`UPDATE "payments" SET .*"status"=`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SELECT 1 FROM customers WHERE id = \$1 FOR UPDATE`).
+		WithArgs(testCustomerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "payments" WHERE customer_id = \$1 AND status = 'paid' AND id <> \$2`).
+		WithArgs(testCustomerID, testPaymentID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).
+		WithArgs("pending", testCustomerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestRefund(t *testing.T) {
+	t.Run("refunds through the gateway and downgrades", func(t *testing.T) {
+		gateway := &fakeGateway{}
+		svc, mock := newRefundService(t, gateway)
+
+		expectPayment(mock, external.PaymentStatusPaid)
+		expectRefundCommit(mock)
+		expectDowngradeStart(mock, sqlmock.NewRows(invitationColumns).
+			AddRow("inv-premium", testCustomerID, true, []byte(premiumContent), []byte(premiumContent), nil, nil, nil))
+		mock.ExpectExec(`UPDATE "invitations" SET "is_published"=\$1`).
+			WithArgs(false, sqlmock.AnyArg(), "inv-premium").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`FROM "custom_domains" WHERE customer_id = \$1`).
+			WithArgs(testCustomerID).
+			WillReturnRows(sqlmock.NewRows(customDomainColumns))
+
+		payment, err := svc.Refund(context.Background(), testPaymentID, "admin-1", "double payment")
+		if err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+		if payment.Status != external.PaymentStatusRefunded {
+			t.Fatalf("Refund left the payment %q", payment.Status)
+		}
+		want := external.GatewayRefundInput{OrderID: "ORDER-1", TransactionID: "trx-1", Amount: 99000, Reason: "double payment"}
+		if len(gateway.refunds) != 1 || gateway.refunds[0] != want {
+			t.Fatalf("gateway refunds = %+v, want %+v", gateway.refunds, want)
+		}
+	})
+
+	// The money is back with the customer by then; a retried refund notification runs the
+	// downgrade again.
+	t.Run("downgrade failure keeps the refund", func(t *testing.T) {
+		gateway := &fakeGateway{}
+		svc, mock := newRefundService(t, gateway)
+
+		expectPayment(mock, external.PaymentStatusPaid)
+		expectRefundCommit(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT 1 FROM customers WHERE id = \$1 FOR UPDATE`).
+			WithArgs(testCustomerID).
+			WillReturnError(errors.New("lock timeout"))
+		mock.ExpectRollback()
+
+		payment, err := svc.Refund(context.Background(), testPaymentID, "admin-1", "double payment")
+		if err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+		if payment.ID != testPaymentID || payment.Status != external.PaymentStatusRefunded {
+			t.Fatalf("Refund = %q (%q), want the refunded payment", payment.ID, payment.Status)
+		}
+		if len(gateway.refunds) != 1 {
+			t.Fatalf("gateway refunds = %d, want 1", len(gateway.refunds))
+		}
+	})
+
+	t.Run("gateway failure changes nothing", func(t *testing.T) {
+		svc, mock := newRefundService(t, &fakeGateway{refundErr: errors.New("status 412")})
+		expectPayment(mock, external.PaymentStatusPaid)
+
+		if _, err := svc.Refund(context.Background(), testPaymentID, "admin-1", "double payment"); !errors.Is(err, ErrRefundFailed) {
+			t.Fatalf("Refund err = %v, want ErrRefundFailed", err)
+		}
+	})
+
+	t.Run("gateway not configured", func(t *testing.T) {
+		svc, mock := newRefundService(t, &fakeGateway{})
+		svc.Gateways = nil
+		expectPayment(mock, external.PaymentStatusPaid)
+
+		if _, err := svc.Refund(context.Background(), testPaymentID, "admin-1", ""); !errors.Is(err, ErrPaymentGatewayNotConfigured) {
+			t.Fatalf("Refund err = %v, want ErrPaymentGatewayNotConfigured", err)
+		}
+	})
+
+	t.Run("unpaid payment", func(t *testing.T) {
+		gateway := &fakeGateway{}
+		svc, mock := newRefundService(t, gateway)
+		expectPayment(mock, external.PaymentStatusPending)
+
+		if _, err := svc.Refund(context.Background(), testPaymentID, "admin-1", ""); !errors.Is(err, ErrPaymentNotRefundable) {
+			t.Fatalf("Refund err = %v, want ErrPaymentNotRefundable", err)
+		}
+		if len(gateway.refunds) != 0 {
+			t.Fatal("an unpaid payment was refunded at the gateway")
+		}
+	})
+}
+
+func TestApplyStatusPartialRefundKeepsPlan(t *testing.T) {
+	svc, mock := newRefundService(t, &fakeGateway{})
+
+	// A partial refund is recorded on the paid payment; no downgrade follows.
+	status := external.GatewayStatus{RawStatus: "partial_refund", Status: external.NormalizeMidtransStatus("partial_refund", "")}
+	mock.ExpectBegin()
+	expectLockedPayment(mock, external.PaymentStatusPaid)
+	expectPaymentEvent(mock, model.PaymentEventSourceWebhook, external.PaymentStatusPaid, external.PaymentStatusPaid, false, false)
+	mock.ExpectExec(`UPDATE "payments" SET .*"raw_status"=`).
+		WithArgs(sqlmock.AnyArg(), "partial_refund", external.PaymentStatusPaid, sqlmock.AnyArg(), testPaymentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	key := "midtrans:ORDER-1:partial_refund"
+	payment, applied, err := svc.ApplyStatus(context.Background(), testPaymentID, status, model.PaymentEvent{Source: model.PaymentEventSourceWebhook, EventKey: &key})
+	if err != nil {
+		t.Fatalf("ApplyStatus: %v", err)
+	}
+	if applied || payment.Status != external.PaymentStatusPaid || payment.RawStatus != "partial_refund" {
+		t.Fatalf("ApplyStatus = status %q (%q), applied %v", payment.Status, payment.RawStatus, applied)
+	}
+}
@@ -0,0 +1,161 @@
+package customer
+
+import (
+	"context"
+	"log"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+	"gorm.io/gorm"
+)
+
+// paymentTransitions lists the statuses a payment may move to from each status. Gateways retry
+// notifications and deliver them out of order, so anything else, such as a late pending after
+// the payment settled, is recorded but not applied. A failed payment may still settle: Midtrans
+// reports a challenged card as denied until the merchant accepts it.
+var paymentTransitions = map[string][]string{
+	external.PaymentStatusPending:     {external.PaymentStatusPaid, external.PaymentStatusFailed},
+	model.PaymentStatusAwaitingReview: {external.PaymentStatusPaid, external.PaymentStatusFailed},
+	external.PaymentStatusFailed:      {external.PaymentStatusPaid},
+	external.PaymentStatusPaid:        {external.PaymentStatusRefunded},
+}
+
+func canTransitionPayment(from, to string) bool {
+	for _, next := range paymentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyStatus applies a payment's new status in its own transaction; see ApplyStatusTx. Once a
+// payment is refunded, content the customer's remaining plan does not allow is taken offline.
+// That also happens when a refund notification is retried, so a retry finishes a downgrade that
+// failed halfway. A failed downgrade is only logged: the refund has committed by then, and
+// callers must not report it as failed and refund again.
+func (s *PaymentService) ApplyStatus(ctx context.Context, paymentID string, status external.GatewayStatus, event model.PaymentEvent) (model.Payment, bool, error) {
+	var (
+		payment model.Payment
+		applied bool
+	)
+	err := s.PaymentRepo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		payment, applied, err = s.ApplyStatusTx(ctx, tx, paymentID, status, event)
+		return err
+	})
+	if err != nil {
+		return model.Payment{}, false, err
+	}
+	if applied {
+		s.ForgetCustomer(payment.CustomerID)
+	}
+
+	if s.Enforcer != nil && status.Status == external.PaymentStatusRefunded && payment.Status == external.PaymentStatusRefunded {
+		result, err := s.Enforcer.Downgrade(ctx, payment.CustomerID)
+		if err != nil {
+			log.Printf("payments: downgrade customer %s after refund of %s: %v", payment.CustomerID, payment.ID, err)
+		} else if changed := len(result.UnpublishedInvitations) + len(result.UnscheduledInvitations) + len(result.DisabledDomains); changed > 0 {
+			log.Printf("payments: downgraded customer %s after refund of %s: unpublished %v, unscheduled %v, disabled domains %v",
+				payment.CustomerID, payment.ID, result.UnpublishedInvitations, result.UnscheduledInvitations, result.DisabledDomains)
+		}
+	}
+	return payment, applied, nil
+}
+
+// ForgetCustomer drops the cached lookups of the customer once a transaction that ran
+// ApplyStatusTx has committed, since the customer's status may have changed with it.
+func (s *PaymentService) ForgetCustomer(customerID string) {
+	s.CustomerRepo.ForgetCached(customerID)
+}
+
+// ApplyStatusTx moves a payment to the status reported by its gateway or an admin review and
+// keeps the customer's status in line: paid once a payment is paid, back to pending when its
+// last paid payment is refunded. The payment row stays locked until the transaction ends; the
+// caller calls ForgetCustomer after it commits when the status was applied.
+//
+// event describes where the status came from; the rest of it is filled in here. A webhook with
+// an event key that was already recorded is a retry and changes nothing. Progress checks are
+// only recorded when they change the status. It returns the payment as stored afterwards and
+// whether its status changed.
+func (s *PaymentService) ApplyStatusTx(ctx context.Context, tx *gorm.DB, paymentID string, status external.GatewayStatus, event model.PaymentEvent) (model.Payment, bool, error) {
+	payment, ok, err := s.PaymentRepo.GetByIDForUpdateTx(ctx, tx, paymentID)
+	if err != nil {
+		return model.Payment{}, false, err
+	}
+	if !ok {
+		return model.Payment{}, false, ErrPaymentNotFound
+	}
+
+	transition := canTransitionPayment(payment.Status, status.Status)
+	event.PaymentID = payment.ID
+	event.Provider = payment.Provider
+	event.RawStatus = status.RawStatus
+	event.Status = status.Status
+	event.FromStatus = payment.Status
+	event.Applied = transition
+	if transition || event.Source != model.PaymentEventSourcePoll {
+		recorded, err := s.PaymentRepo.CreateEventTx(ctx, tx, event)
+		if err != nil {
+			return model.Payment{}, false, err
+		}
+		if !recorded {
+			return payment, false, nil
+		}
+	}
+
+	// The same status again may still carry details the gateway did not know before, such as
+	// the payment type.
+	if !transition && payment.Status != status.Status {
+		return payment, false, nil
+	}
+
+	paidAt := paidAtForStatus(status.Status, payment.PaidAt)
+	if err := s.PaymentRepo.UpdateProviderStatusTx(ctx, tx, payment.ID, repository.ProviderStatusUpdate{
+		Status:        status.Status,
+		PaidAt:        paidAt,
+		RawStatus:     status.RawStatus,
+		TransactionID: status.TransactionID,
+		PaymentType:   status.PaymentType,
+	}); err != nil {
+		return model.Payment{}, false, err
+	}
+	payment.Status = status.Status
+	payment.RawStatus = status.RawStatus
+	if paidAt != nil {
+		payment.PaidAt = paidAt
+	}
+	if status.TransactionID != "" {
+		payment.ProviderTransactionID = status.TransactionID
+	}
+	if status.PaymentType != "" {
+		payment.PaymentType = status.PaymentType
+	}
+	if !transition {
+		return payment, false, nil
+	}
+
+	switch status.Status {
+	case external.PaymentStatusPaid:
+		if err := s.CustomerRepo.UpdateStatusTx(ctx, tx, payment.CustomerID, "paid"); err != nil {
+			return model.Payment{}, false, err
+		}
+	case external.PaymentStatusRefunded:
+		// Locking the customer keeps a payment settling at the same time from being counted
+		// before it commits.
+		if err := s.CustomerRepo.LockTx(ctx, tx, payment.CustomerID); err != nil {
+			return model.Payment{}, false, err
+		}
+		others, err := s.PaymentRepo.CountPaidByCustomerTx(ctx, tx, payment.CustomerID, payment.ID)
+		if err != nil {
+			return model.Payment{}, false, err
+		}
+		if others == 0 {
+			if err := s.CustomerRepo.UpdateStatusTx(ctx, tx, payment.CustomerID, "pending"); err != nil {
+				return model.Payment{}, false, err
+			}
+		}
+	}
+	return payment, true, nil
+}
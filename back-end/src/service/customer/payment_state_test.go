@@ -0,0 +1,251 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
+)
+
+const testPaymentID = "0d9c8b7a-6f5e-4d3c-8b2a-1f0e9d8c7b6a"
+
+var paymentStatuses = []string{
+	external.PaymentStatusPending,
+	model.PaymentStatusAwaitingReview,
+	external.PaymentStatusPaid,
+	external.PaymentStatusFailed,
+	external.PaymentStatusRefunded,
+}
+
+func TestCanTransitionPayment(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{external.PaymentStatusPending, external.PaymentStatusPaid}:       true,
+		{external.PaymentStatusPending, external.PaymentStatusFailed}:     true,
+		{model.PaymentStatusAwaitingReview, external.PaymentStatusPaid}:   true,
+		{model.PaymentStatusAwaitingReview, external.PaymentStatusFailed}: true,
+		{external.PaymentStatusFailed, external.PaymentStatusPaid}:        true,
+		{external.PaymentStatusPaid, external.PaymentStatusRefunded}:      true,
+	}
+
+	for _, from := range paymentStatuses {
+		for _, to := range paymentStatuses {
+			want := allowed[[2]string{from, to}]
+			if got := canTransitionPayment(from, to); got != want {
+				t.Errorf("canTransitionPayment(%q, %q) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	if canTransitionPayment("", external.PaymentStatusPaid) || canTransitionPayment(external.PaymentStatusPending, "") {
+		t.Error("an unknown status may transition")
+	}
+}
+
+func newPaymentStateService(t *testing.T) (*PaymentService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := testdb.New(t)
+	svc := &PaymentService{
+		CustomerRepo: &repository.CustomerRepository{DB: db, Cache: repository.NewTenantCache(10, time.Minute)},
+		PaymentRepo:  &repository.PaymentRepository{DB: db},
+	}
+	return svc, mock
+}
+
+func expectLockedPayment(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`SELECT \* FROM "payments" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs(testPaymentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "provider"}).
+			AddRow(testPaymentID, testCustomerID, status, "midtrans"))
+}
+
+// expectPaymentEvent answers the event insert; a duplicate event key inserts nothing.
+func expectPaymentEvent(mock sqlmock.Sqlmock, source, from, to string, applied, duplicate bool) {
+	rows := sqlmock.NewRows([]string{"id"})
+	if !duplicate {
+		rows.AddRow("event-1")
+	}
+	mock.ExpectQuery(`INSERT INTO "payment_events" .* ON CONFLICT \("provider","event_key"\) DO NOTHING`).
+		WithArgs(testPaymentID, "midtrans", source, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), to, from, applied, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(rows)
+}
+
+func TestApplyStatusTx(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		source string
+		// duplicate is a retried webhook whose event key was already recorded.
+		duplicate bool
+		// otherPaid is the customer's count of other paid payments when a refund is applied.
+		otherPaid    int64
+		wantEvent    bool
+		wantUpdate   bool
+		wantCustomer string
+		wantApplied  bool
+		wantStatus   string
+	}{
+		{
+			name: "webhook settles a pending payment", from: external.PaymentStatusPending, to: external.PaymentStatusPaid, source: model.PaymentEventSourceWebhook,
+			wantEvent: true, wantUpdate: true, wantCustomer: "paid", wantApplied: true, wantStatus: external.PaymentStatusPaid,
+		},
+		{
+			name: "retried webhook with the same event key", from: external.PaymentStatusPending, to: external.PaymentStatusPaid, source: model.PaymentEventSourceWebhook, duplicate: true,
+			wantEvent: true, wantStatus: external.PaymentStatusPending,
+		},
+		{
+			name: "late pending after the payment settled", from: external.PaymentStatusPaid, to: external.PaymentStatusPending, source: model.PaymentEventSourceWebhook,
+			wantEvent: true, wantStatus: external.PaymentStatusPaid,
+		},
+		{
+			name: "same status again updates details only", from: external.PaymentStatusPaid, to: external.PaymentStatusPaid, source: model.PaymentEventSourceWebhook,
+			wantEvent: true, wantUpdate: true, wantStatus: external.PaymentStatusPaid,
+		},
+		{
+			name: "poll without a change is not recorded", from: external.PaymentStatusPending, to: external.PaymentStatusPending, source: model.PaymentEventSourcePoll,
+			wantUpdate: true, wantStatus: external.PaymentStatusPending,
+		},
+		{
+			name: "refused poll changes nothing", from: external.PaymentStatusRefunded, to: external.PaymentStatusPaid, source: model.PaymentEventSourcePoll,
+			wantStatus: external.PaymentStatusRefunded,
+		},
+		{
+			name: "approved manual transfer", from: model.PaymentStatusAwaitingReview, to: external.PaymentStatusPaid, source: model.PaymentEventSourceReview,
+			wantEvent: true, wantUpdate: true, wantCustomer: "paid", wantApplied: true, wantStatus: external.PaymentStatusPaid,
+		},
+		{
+			name: "denied card accepted later", from: external.PaymentStatusFailed, to: external.PaymentStatusPaid, source: model.PaymentEventSourceWebhook,
+			wantEvent: true, wantUpdate: true, wantCustomer: "paid", wantApplied: true, wantStatus: external.PaymentStatusPaid,
+		},
+		{
+			name: "refund of the last paid payment demotes the customer", from: external.PaymentStatusPaid, to: external.PaymentStatusRefunded, source: model.PaymentEventSourceWebhook,
+			wantEvent: true, wantUpdate: true, wantCustomer: "pending", wantApplied: true, wantStatus: external.PaymentStatusRefunded,
+		},
+		{
+			name: "refund with another paid payment keeps the customer paid", from: external.PaymentStatusPaid, to: external.PaymentStatusRefunded, source: model.PaymentEventSourceWebhook, otherPaid: 1,
+			wantEvent: true, wantUpdate: true, wantApplied: true, wantStatus: external.PaymentStatusRefunded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock := newPaymentStateService(t)
+			transition := canTransitionPayment(tt.from, tt.to)
+
+			expectLockedPayment(mock, tt.from)
+			if tt.wantEvent {
+				expectPaymentEvent(mock, tt.source, tt.from, tt.to, transition, tt.duplicate)
+			}
+			if tt.wantUpdate {
+				mock.ExpectExec(`UPDATE "payments" SET .*"status"=`).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if transition && tt.to == external.PaymentStatusRefunded && !tt.duplicate {
+				mock.ExpectExec(`SELECT 1 FROM customers WHERE id = \$1 FOR UPDATE`).
+					WithArgs(testCustomerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "payments" WHERE customer_id = \$1 AND status = 'paid' AND id <> \$2`).
+					WithArgs(testCustomerID, testPaymentID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.otherPaid))
+			}
+			if tt.wantCustomer != "" {
+				mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).
+					WithArgs(tt.wantCustomer, testCustomerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			key := "midtrans:ORDER-1:" + tt.to
+			event := model.PaymentEvent{Source: tt.source, EventKey: &key, Payload: "{}"}
+			if tt.source == model.PaymentEventSourcePoll {
+				event.EventKey = nil
+			}
+			status := external.GatewayStatus{Status: tt.to, RawStatus: "raw-" + tt.to}
+
+			payment, applied, err := svc.ApplyStatusTx(context.Background(), svc.PaymentRepo.DB, testPaymentID, status, event)
+			if err != nil {
+				t.Fatalf("ApplyStatusTx: %v", err)
+			}
+			if applied != tt.wantApplied || payment.Status != tt.wantStatus {
+				t.Fatalf("ApplyStatusTx = status %q, applied %v, want %q, %v", payment.Status, applied, tt.wantStatus, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestApplyStatusTxUnknownPayment(t *testing.T) {
+	svc, mock := newPaymentStateService(t)
+	mock.ExpectQuery(`SELECT \* FROM "payments" WHERE id = \$1 .*FOR UPDATE`).
+		WithArgs(testPaymentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := svc.ApplyStatusTx(context.Background(), svc.PaymentRepo.DB, testPaymentID, external.GatewayStatus{Status: external.PaymentStatusPaid}, model.PaymentEvent{})
+	if !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("ApplyStatusTx err = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestApplyStatusForgetsCustomerAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	paid := external.GatewayStatus{Status: external.PaymentStatusPaid, RawStatus: "settlement"}
+	key := "midtrans:ORDER-1:settlement"
+	event := model.PaymentEvent{Source: model.PaymentEventSourceWebhook, EventKey: &key, Payload: "{}"}
+
+	expectCustomerLookup := func(mock sqlmock.Sqlmock, status string) {
+		mock.ExpectQuery(`SELECT \* FROM "customers" WHERE domain = \$1`).
+			WithArgs("rina", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "domain", "status"}).AddRow(testCustomerID, "rina", status))
+	}
+	expectSettlement := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		expectLockedPayment(mock, external.PaymentStatusPending)
+		expectPaymentEvent(mock, model.PaymentEventSourceWebhook, external.PaymentStatusPending, external.PaymentStatusPaid, true, false)
+		mock.ExpectExec(`UPDATE "payments" SET .*"status"=`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "customers" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	t.Run("committed", func(t *testing.T) {
+		svc, mock := newPaymentStateService(t)
+		expectCustomerLookup(mock, "pending")
+		if _, _, err := svc.CustomerRepo.FindByDomain(ctx, "rina"); err != nil {
+			t.Fatal(err)
+		}
+
+		expectSettlement(mock)
+		mock.ExpectCommit()
+		if _, applied, err := svc.ApplyStatus(ctx, testPaymentID, paid, event); err != nil || !applied {
+			t.Fatalf("ApplyStatus = %v, %v", applied, err)
+		}
+
+		// The cached pending customer is gone, so the next lookup reads the new status.
+		expectCustomerLookup(mock, "paid")
+		customer, _, err := svc.CustomerRepo.FindByDomain(ctx, "rina")
+		if err != nil || customer.Status != "paid" {
+			t.Fatalf("FindByDomain after commit = %q, %v", customer.Status, err)
+		}
+	})
+
+	t.Run("commit failed", func(t *testing.T) {
+		svc, mock := newPaymentStateService(t)
+		expectCustomerLookup(mock, "pending")
+		if _, _, err := svc.CustomerRepo.FindByDomain(ctx, "rina"); err != nil {
+			t.Fatal(err)
+		}
+
+		expectSettlement(mock)
+		mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+		if _, _, err := svc.ApplyStatus(ctx, testPaymentID, paid, event); err == nil {
+			t.Fatal("ApplyStatus succeeded although the commit failed")
+		}
+
+		// Nothing changed, so the cached customer is still served.
+		customer, _, err := svc.CustomerRepo.FindByDomain(ctx, "rina")
+		if err != nil || customer.Status != "pending" {
+			t.Fatalf("FindByDomain after a failed commit = %q, %v", customer.Status, err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+)
+
+// DowngradeResult lists what Downgrade took offline.
+type DowngradeResult struct {
+	UnpublishedInvitations []string
+	UnscheduledInvitations []string
+	DisabledDomains        []string
+}
+
+// Downgrade brings what guests can see back within the limits of the plan the customer is
+// entitled to now, after a refund took a paid plan away. Published invitations whose snapshot
+// the plan no longer allows, or that exceed its invitation count, are unpublished; scheduled
+// publishes of drafts it does not allow are cancelled; custom domains stop being served when the
+// plan has none. Drafts are left alone, so the customer can edit them down and publish again.
+// Running it again changes nothing.
+//
+// The invitations are changed under the customer's row lock, which Publish and SetSchedule also
+// take before they check the plan, so none of them can act on the plan from before the refund.
+func (e *PlanEnforcer) Downgrade(ctx context.Context, customerID string) (DowngradeResult, error) {
+	if e == nil || e.InvitationRepo == nil || e.DomainRepo == nil || e.CustomerRepo == nil {
+		return DowngradeResult{}, errors.New("downgrade repositories not configured")
+	}
+
+	var (
+		limits PlanLimits
+		result DowngradeResult
+	)
+	err := e.InvitationRepo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := e.CustomerRepo.LockTx(ctx, tx, customerID); err != nil {
+			return err
+		}
+		var err error
+		limits, err = e.GetCustomerLimits(ctx, customerID)
+		if err != nil {
+			return err
+		}
+
+		invitations, err := e.InvitationRepo.ListByCustomerIDForUpdateTx(ctx, tx, customerID)
+		if err != nil {
+			return err
+		}
+		// The most recently published invitations are the ones kept online.
+		sort.SliceStable(invitations, func(i, j int) bool {
+			return publishedAfter(invitations[i].PublishedAt, invitations[j].PublishedAt)
+		})
+
+		kept := 0
+		for _, inv := range invitations {
+			if inv.IsPublished {
+				if kept < limits.MaxInvitations && ValidateContent(inv.PublishedContent, limits) == nil {
+					kept++
+				} else {
+					if _, err := e.InvitationRepo.UnpublishTx(ctx, tx, inv.ID); err != nil {
+						return err
+					}
+					result.UnpublishedInvitations = append(result.UnpublishedInvitations, inv.ID)
+				}
+			}
+			if inv.PublishAt != nil && ValidateContent(inv.Content, limits) != nil {
+				if err := e.InvitationRepo.SetScheduleTx(ctx, tx, inv.ID, nil, inv.ArchiveAt); err != nil {
+					return err
+				}
+				result.UnscheduledInvitations = append(result.UnscheduledInvitations, inv.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return DowngradeResult{}, err
+	}
+	e.InvitationRepo.ForgetCached(result.UnpublishedInvitations...)
+
+	if limits.CustomDomain {
+		return result, nil
+	}
+	domains, err := e.DomainRepo.ListByCustomer(ctx, customerID)
+	if err != nil {
+		return result, err
+	}
+	now := time.Now()
+	for _, domain := range domains {
+		if domain.Status != model.CustomDomainStatusVerified {
+			continue
+		}
+		if err := e.DomainRepo.MarkFailed(ctx, domain.ID, now, ErrCustomDomainNotAllowed.Error()); err != nil {
+			return result, err
+		}
+		result.DisabledDomains = append(result.DisabledDomains, domain.Hostname)
+	}
+	return result, nil
+}
+
+func publishedAfter(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil
+	}
+	return a.After(*b)
+}
@@ -0,0 +1,142 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+var invitationColumns = []string{"id", "customer_id", "is_published", "content", "published_content", "published_at", "publish_at", "archive_at"}
+
+const (
+	allowedContent = `{"gallery": {"photos": ["https://cdn.example.com/1.jpg"]}}`
+	// Basic allows four gallery photos.
+	premiumContent = `{"gallery": {"photos": ["https://cdn.example.com/1.jpg", "https://cdn.example.com/2.jpg", "https://cdn.example.com/3.jpg", "https://cdn.example.com/4.jpg", "https://cdn.example.com/5.jpg"]}}`
+)
+
+func newDowngradeEnforcer(t *testing.T) (*PlanEnforcer, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := testdb.New(t)
+	return &PlanEnforcer{
+		PaymentRepo:    &repository.PaymentRepository{DB: db},
+		InvitationRepo: &repository.InvitationRepository{DB: db},
+		DomainRepo:     &repository.CustomDomainRepository{DB: db},
+		CustomerRepo:   &repository.CustomerRepository{DB: db},
+	}, mock
+}
+
+// expectNoPlan answers the plan lookup for a customer without paid payments, who gets basic.
+func expectNoPlan(mock sqlmock.Sqlmock, customerID string) {
+	mock.ExpectQuery(`JOIN plans ON plans.id = payments.plan_id`).
+		WithArgs(customerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"plan_code", "plan_features", "plan_limits"}))
+}
+
+// expectDowngradeStart expects the customer lock, the plan lookup and every invitation, listed
+// without a limit and locked.
+func expectDowngradeStart(mock sqlmock.Sqlmock, invitations *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT 1 FROM customers WHERE id = \$1 FOR UPDATE`).
+		WithArgs(testCustomerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectNoPlan(mock, testCustomerID)
+	mock.ExpectQuery(`SELECT \* FROM "invitations" WHERE customer_id = \$1 ORDER BY created_at DESC FOR UPDATE$`).
+		WithArgs(testCustomerID).
+		WillReturnRows(invitations)
+}
+
+func TestDowngrade(t *testing.T) {
+	enforcer, mock := newDowngradeEnforcer(t)
+	now := time.Now()
+	hoursAgo := func(h int) *time.Time {
+		at := now.Add(-time.Duration(h) * time.Hour)
+		return &at
+	}
+	tomorrow := now.Add(24 * time.Hour)
+
+	expectDowngradeStart(mock, sqlmock.NewRows(invitationColumns).
+		// Newest publish, but its snapshot needs the refunded plan.
+		AddRow("inv-premium", testCustomerID, true, []byte(premiumContent), []byte(premiumContent), hoursAgo(1), nil, nil).
+		// Kept: basic allows one published invitation.
+		AddRow("inv-kept", testCustomerID, true, []byte(allowedContent), []byte(allowedContent), hoursAgo(2), nil, nil).
+		AddRow("inv-extra", testCustomerID, true, []byte(allowedContent), []byte(allowedContent), hoursAgo(3), nil, nil).
+		AddRow("inv-scheduled", testCustomerID, false, []byte(premiumContent), nil, nil, &tomorrow, nil).
+		AddRow("inv-draft", testCustomerID, false, []byte(allowedContent), nil, nil, &tomorrow, nil))
+	for _, id := range []string{"inv-premium", "inv-extra"} {
+		mock.ExpectExec(`UPDATE "invitations" SET "is_published"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+			WithArgs(false, sqlmock.AnyArg(), id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`UPDATE "invitations" SET "archive_at"=\$1,"publish_at"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+		WithArgs(nil, nil, sqlmock.AnyArg(), "inv-scheduled").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(`FROM "custom_domains" WHERE customer_id = \$1`).
+		WithArgs(testCustomerID).
+		WillReturnRows(sqlmock.NewRows(customDomainColumns).
+			AddRow("domain-1", testCustomerID, testHostname, testToken, model.CustomDomainStatusVerified, now, now, "", now).
+			AddRow("domain-2", testCustomerID, "pending.example.com", testToken, model.CustomDomainStatusPending, nil, nil, "", now))
+	mock.ExpectExec(`UPDATE "custom_domains" SET .*"status"=`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := enforcer.Downgrade(context.Background(), testCustomerID)
+	if err != nil {
+		t.Fatalf("Downgrade: %v", err)
+	}
+	want := DowngradeResult{
+		UnpublishedInvitations: []string{"inv-premium", "inv-extra"},
+		UnscheduledInvitations: []string{"inv-scheduled"},
+		DisabledDomains:        []string{testHostname},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("Downgrade = %+v, want %+v", result, want)
+	}
+}
+
+func TestDowngradeWithinLimits(t *testing.T) {
+	enforcer, mock := newDowngradeEnforcer(t)
+
+	expectDowngradeStart(mock, sqlmock.NewRows(invitationColumns).
+		AddRow("inv-kept", testCustomerID, true, []byte(allowedContent), []byte(allowedContent), time.Now(), nil, nil))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`FROM "custom_domains" WHERE customer_id = \$1`).
+		WithArgs(testCustomerID).
+		WillReturnRows(sqlmock.NewRows(customDomainColumns))
+
+	result, err := enforcer.Downgrade(context.Background(), testCustomerID)
+	if err != nil {
+		t.Fatalf("Downgrade: %v", err)
+	}
+	if len(result.UnpublishedInvitations)+len(result.UnscheduledInvitations)+len(result.DisabledDomains) != 0 {
+		t.Fatalf("Downgrade changed %+v within the plan's limits", result)
+	}
+}
+
+func TestDowngradeRollsBack(t *testing.T) {
+	enforcer, mock := newDowngradeEnforcer(t)
+
+	expectDowngradeStart(mock, sqlmock.NewRows(invitationColumns).
+		AddRow("inv-kept", testCustomerID, true, []byte(allowedContent), []byte(allowedContent), time.Now(), nil, nil).
+		AddRow("inv-extra", testCustomerID, true, []byte(allowedContent), []byte(allowedContent), time.Now().Add(-time.Hour), nil, nil))
+	mock.ExpectExec(`UPDATE "invitations" SET "is_published"=\$1`).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	// Nothing is reported taken offline, and custom domains are left for the retry.
+	result, err := enforcer.Downgrade(context.Background(), testCustomerID)
+	if err == nil {
+		t.Fatal("Downgrade succeeded against a failing database")
+	}
+	if !reflect.DeepEqual(result, DowngradeResult{}) {
+		t.Fatalf("failed Downgrade reported %+v", result)
+	}
+}
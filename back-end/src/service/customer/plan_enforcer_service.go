@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/proxima-labs/wedding-invitation-back-end/src/content"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
 )
 
 type PlanLimits struct {
@@ -16,15 +18,92 @@ type PlanLimits struct {
 	Gifts         bool
 	CustomDomain  bool
 	Templates     string
+	// MaxCustomDomains is how many hostnames the customer may register when CustomDomain is set.
+	MaxCustomDomains int
+	// MaxInvitations is how many invitations the customer may own.
+	MaxInvitations int
+	// RevisionHistory is how many content revisions are kept per invitation.
+	RevisionHistory int
+	// UploadMaxMB caps the size of a single uploaded media file.
+	UploadMaxMB int
+	// MaxRsvps and MaxWishes cap the guest responses and guestbook wishes each invitation accepts.
+	// A negative value, -1 in the plan's limits, lifts the cap.
+	MaxRsvps  int
+	MaxWishes int
+	// MaxContentKB caps the size of an invitation's content document, including the photos the
+	// editor embeds inline as data URLs, since every revision and published copy stores them too.
+	MaxContentKB int
+	// MaxStoryEntries caps the love story timeline.
+	MaxStoryEntries int
 }
 
+// defaultCustomDomains applies to plans with custom domains that do not set max_custom_domains.
+// It is enough for the bare domain and its www alias.
+const defaultCustomDomains = 2
+
 var basicPlanLimits = PlanLimits{
-	GalleryPhotos: 4,
-	Templates:     "1",
+	GalleryPhotos:   4,
+	Templates:       "1",
+	MaxInvitations:  1,
+	RevisionHistory: 5,
+	UploadMaxMB:     2,
+	MaxRsvps:        150,
+	MaxWishes:       150,
+	MaxContentKB:    64,
+	MaxStoryEntries: 3,
 }
 
+var (
+	// ErrPlanLimitExceeded matches every content check that fails because of the plan.
+	ErrPlanLimitExceeded = errors.New("plan limit exceeded")
+	ErrForeignMedia      = errors.New("media does not belong to this account")
+	ErrRsvpQuotaReached  = errors.New("rsvp limit reached for this invitation")
+	ErrWishQuotaReached  = errors.New("wish limit reached for this invitation")
+)
+
+// planLimitError is a content check the plan does not allow. Its message is shown to the
+// customer as is.
+type planLimitError string
+
+func (e planLimitError) Error() string {
+	return string(e)
+}
+
+func (e planLimitError) Is(target error) bool {
+	return target == ErrPlanLimitExceeded
+}
+
+var (
+	ErrContentTooLarge      error = planLimitError("invitation content is too large")
+	ErrStoryEntriesExceeded error = planLimitError("love story entries limit exceeded")
+)
+
 type PlanEnforcer struct {
 	PaymentRepo *repository.PaymentRepository
+	// MediaRepo and Storage let ValidateContent check that uploaded files embedded in content
+	// belong to the customer. Links to files hosted elsewhere are left alone.
+	MediaRepo *repository.MediaRepository
+	Storage   storage.Storage
+	// InvitationRepo, RsvpRepo and WishRepo back the usage report.
+	InvitationRepo *repository.InvitationRepository
+	RsvpRepo       *repository.RsvpRepository
+	WishRepo       *repository.WishRepository
+	// DomainRepo lets Downgrade take custom domains offline, and CustomerRepo lets it lock the
+	// customer against a concurrent publish.
+	DomainRepo   *repository.CustomDomainRepository
+	CustomerRepo *repository.CustomerRepository
+}
+
+// InvitationUsage is how much of the per-invitation quotas one invitation uses.
+type InvitationUsage struct {
+	InvitationID string
+	Slug         string
+	Title        string
+	Rsvps        int64
+	Wishes       int64
+	// ContentKB is the size of the draft content, rounded up.
+	ContentKB    int
+	StoryEntries int
 }
 
 func ParsePlanLimits(_, limits []byte) PlanLimits {
@@ -35,11 +114,20 @@ func ParsePlanLimits(_, limits []byte) PlanLimits {
 	}
 
 	var l struct {
-		GalleryPhotos interface{} `json:"gallery_photos"`
-		LoveStory     interface{} `json:"love_story"`
-		Music         interface{} `json:"music"`
-		Gifts         interface{} `json:"gifts"`
-		Templates     interface{} `json:"templates"`
+		GalleryPhotos    interface{} `json:"gallery_photos"`
+		LoveStory        interface{} `json:"love_story"`
+		Music            interface{} `json:"music"`
+		Gifts            interface{} `json:"gifts"`
+		CustomDomain     interface{} `json:"custom_domain"`
+		MaxCustomDomains interface{} `json:"max_custom_domains"`
+		Templates        interface{} `json:"templates"`
+		MaxInvitations   interface{} `json:"max_invitations"`
+		RevisionHistory  interface{} `json:"revision_history"`
+		UploadMaxMB      interface{} `json:"upload_max_mb"`
+		MaxRsvps         interface{} `json:"max_rsvps"`
+		MaxWishes        interface{} `json:"max_wishes"`
+		MaxContentKB     interface{} `json:"max_content_kb"`
+		MaxStoryEntries  interface{} `json:"max_story_entries"`
 	}
 	if err := json.Unmarshal(limits, &l); err != nil {
 		return pl
@@ -54,9 +142,38 @@ func ParsePlanLimits(_, limits []byte) PlanLimits {
 	if v, ok := toBool(l.Gifts); ok {
 		pl.Gifts = v
 	}
+	if v, ok := toBool(l.CustomDomain); ok {
+		pl.CustomDomain = v
+	}
+	if v, ok := toInt(l.MaxCustomDomains); ok && v > 0 {
+		pl.MaxCustomDomains = v
+	} else if pl.CustomDomain {
+		pl.MaxCustomDomains = defaultCustomDomains
+	}
 	if v, ok := toInt(l.GalleryPhotos); ok {
 		pl.GalleryPhotos = v
 	}
+	if v, ok := toInt(l.MaxInvitations); ok && v > 0 {
+		pl.MaxInvitations = v
+	}
+	if v, ok := toInt(l.RevisionHistory); ok && v > 0 {
+		pl.RevisionHistory = v
+	}
+	if v, ok := toInt(l.UploadMaxMB); ok && v > 0 {
+		pl.UploadMaxMB = v
+	}
+	if v, ok := toInt(l.MaxRsvps); ok && v != 0 {
+		pl.MaxRsvps = v
+	}
+	if v, ok := toInt(l.MaxWishes); ok && v != 0 {
+		pl.MaxWishes = v
+	}
+	if v, ok := toInt(l.MaxContentKB); ok && v > 0 {
+		pl.MaxContentKB = v
+	}
+	if v, ok := toInt(l.MaxStoryEntries); ok && v > 0 {
+		pl.MaxStoryEntries = v
+	}
 	switch v := l.Templates.(type) {
 	case string:
 		pl.Templates = v
@@ -83,6 +200,15 @@ func (e *PlanEnforcer) GetCustomerLimits(ctx context.Context, customerID string)
 	return ParsePlanLimits(row.PlanFeatures, row.PlanLimits), nil
 }
 
+// RevisionRetention reports how many content revisions the customer's plan keeps per invitation.
+func (e *PlanEnforcer) RevisionRetention(ctx context.Context, customerID string) (int, error) {
+	limits, err := e.GetCustomerLimits(ctx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	return limits.RevisionHistory, nil
+}
+
 func (e *PlanEnforcer) GetCustomerLimitsWithCode(ctx context.Context, customerID string) (string, PlanLimits, error) {
 	if e == nil || e.PaymentRepo == nil {
 		return "none", basicPlanLimits, nil
@@ -99,55 +225,131 @@ func (e *PlanEnforcer) GetCustomerLimitsWithCode(ctx context.Context, customerID
 	return row.PlanCode, ParsePlanLimits(row.PlanFeatures, row.PlanLimits), nil
 }
 
+// Usage reports the quota usage of every invitation the customer owns, newest first.
+func (e *PlanEnforcer) Usage(ctx context.Context, customerID string) ([]InvitationUsage, error) {
+	if e == nil || e.InvitationRepo == nil || e.RsvpRepo == nil || e.WishRepo == nil {
+		return nil, errors.New("usage repositories not configured")
+	}
+
+	invitations, err := e.InvitationRepo.ListByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(invitations))
+	for _, inv := range invitations {
+		ids = append(ids, inv.ID)
+	}
+
+	rsvps, err := e.RsvpRepo.CountByInvitationIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	wishes, err := e.WishRepo.CountGuestbookByInvitationIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]InvitationUsage, 0, len(invitations))
+	for _, inv := range invitations {
+		var doc content.Content
+		_ = json.Unmarshal(inv.Content, &doc)
+		stories := 0
+		if doc.Story != nil {
+			stories = len(doc.Story.Stories)
+		}
+
+		usage = append(usage, InvitationUsage{
+			InvitationID: inv.ID,
+			Slug:         inv.Slug,
+			Title:        inv.Title,
+			Rsvps:        rsvps[inv.ID],
+			Wishes:       wishes[inv.ID],
+			ContentKB:    (len(inv.Content) + 1023) / 1024,
+			StoryEntries: stories,
+		})
+	}
+	return usage, nil
+}
+
 var allowedBasicThemes = map[string]struct{}{
 	"elegant": {},
 }
 
-func ValidateContent(content []byte, limits PlanLimits) error {
-	if len(content) == 0 {
-		return nil
+// ValidateContent checks content against the schema and then against the plan's feature limits.
+func ValidateContent(raw []byte, limits PlanLimits) error {
+	if len(raw) > limits.MaxContentKB*1024 {
+		return fmt.Errorf("%w (max %d KB)", ErrContentTooLarge, limits.MaxContentKB)
 	}
 
-	var payload struct {
-		Gallery *struct {
-			Photos []interface{} `json:"photos"`
-		} `json:"gallery"`
-		Story *struct {
-			Stories []interface{} `json:"stories"`
-		} `json:"story"`
-		Music *struct {
-			Enabled bool `json:"enabled"`
-		} `json:"music"`
-		Gift *struct {
-			Banks []interface{} `json:"banks"`
-		} `json:"gift"`
-		Theme *struct {
-			Theme string `json:"theme"`
-		} `json:"theme"`
-	}
-
-	if err := json.Unmarshal(content, &payload); err != nil {
-		return nil
+	payload, err := content.Validate(raw)
+	if err != nil {
+		return err
 	}
 
 	if payload.Gallery != nil && len(payload.Gallery.Photos) > limits.GalleryPhotos {
-		return fmt.Errorf("gallery photos limit exceeded (max %d)", limits.GalleryPhotos)
+		return planLimitError(fmt.Sprintf("gallery photos limit exceeded (max %d)", limits.GalleryPhotos))
 	}
 	if !limits.LoveStory && payload.Story != nil && len(payload.Story.Stories) > 0 {
-		return errors.New("love story not included in your plan")
+		return planLimitError("love story not included in your plan")
+	}
+	if payload.Story != nil && len(payload.Story.Stories) > limits.MaxStoryEntries {
+		return fmt.Errorf("%w (max %d)", ErrStoryEntriesExceeded, limits.MaxStoryEntries)
 	}
 	if !limits.Music && payload.Music != nil && payload.Music.Enabled {
-		return errors.New("music not included in your plan")
+		return planLimitError("music not included in your plan")
 	}
 	if !limits.Gifts && payload.Gift != nil && len(payload.Gift.Banks) > 0 {
-		return errors.New("digital gift not included in your plan")
+		return planLimitError("digital gift not included in your plan")
 	}
 	if limits.Templates == "1" && payload.Theme != nil && payload.Theme.Theme != "" {
 		if _, allowed := allowedBasicThemes[payload.Theme.Theme]; !allowed {
-			return errors.New("this template not included in your plan")
+			return planLimitError("this template not included in your plan")
+		}
+	}
+
+	return nil
+}
+
+// ValidateContent runs the plan checks of the package-level ValidateContent and then makes sure
+// every uploaded file the content embeds was uploaded by the customer.
+func (e *PlanEnforcer) ValidateContent(ctx context.Context, customerID string, raw []byte, limits PlanLimits) error {
+	if err := ValidateContent(raw, limits); err != nil {
+		return err
+	}
+	if e == nil || e.MediaRepo == nil || e.Storage == nil {
+		return nil
+	}
+
+	payload, err := content.Validate(raw)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0)
+	fields := make(map[string]string)
+	for _, asset := range payload.Assets() {
+		key, ok := e.Storage.KeyFromURL(asset.URL)
+		if !ok {
+			continue
+		}
+		if _, seen := fields[key]; !seen {
+			keys = append(keys, key)
+			fields[key] = asset.Field
 		}
 	}
+	if len(keys) == 0 {
+		return nil
+	}
 
+	owned, err := e.MediaRepo.OwnedKeys(ctx, customerID, keys)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := owned[key]; !ok {
+			return fmt.Errorf("%w: %s", ErrForeignMedia, fields[key])
+		}
+	}
 	return nil
 }
 
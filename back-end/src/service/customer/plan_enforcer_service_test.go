@@ -0,0 +1,58 @@
+package customer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectActivePlan answers the plan lookup of PlanEnforcer.GetCustomerLimits with limits.
+func expectActivePlan(mock sqlmock.Sqlmock, customerID, code, limits string) {
+	mock.ExpectQuery(`JOIN plans ON plans.id = payments.plan_id`).
+		WithArgs(customerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"plan_code", "plan_features", "plan_limits"}).
+			AddRow(code, []byte("[]"), []byte(limits)))
+}
+
+func TestParsePlanLimitsQuotas(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    string
+		maxRsvps  int
+		maxWishes int
+	}{
+		{name: "plan values", limits: `{"max_rsvps": 500, "max_wishes": 400}`, maxRsvps: 500, maxWishes: 400},
+		{name: "missing values", limits: `{}`, maxRsvps: 150, maxWishes: 150},
+		{name: "zero or text keeps the default", limits: `{"max_rsvps": 0, "max_wishes": "many"}`, maxRsvps: 150, maxWishes: 150},
+		{name: "unlimited", limits: `{"max_rsvps": -1, "max_wishes": -1}`, maxRsvps: -1, maxWishes: -1},
+		{name: "broken json", limits: `{"max_rsvps":`, maxRsvps: 150, maxWishes: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := ParsePlanLimits(nil, []byte(tt.limits))
+			if limits.MaxRsvps != tt.maxRsvps || limits.MaxWishes != tt.maxWishes {
+				t.Fatalf("ParsePlanLimits = %d rsvps, %d wishes, want %d, %d", limits.MaxRsvps, limits.MaxWishes, tt.maxRsvps, tt.maxWishes)
+			}
+		})
+	}
+}
+
+// Inline photos are stored in every revision and published copy, so they count like any other field.
+func TestValidateContentCountsInlineImages(t *testing.T) {
+	limits := basicPlanLimits
+	limits.MaxContentKB = 4
+	photo := func(size int) string {
+		return `{"couple": {"bridePhoto": "data:image/jpeg;base64,` + strings.Repeat("A", size) + `"}}`
+	}
+
+	if err := ValidateContent([]byte(photo(3000)), limits); err != nil {
+		t.Fatalf("ValidateContent with a small photo: %v", err)
+	}
+	err := ValidateContent([]byte(photo(5000)), limits)
+	if !errors.Is(err, ErrContentTooLarge) || !errors.Is(err, ErrPlanLimitExceeded) {
+		t.Fatalf("ValidateContent err = %v, want ErrContentTooLarge", err)
+	}
+}
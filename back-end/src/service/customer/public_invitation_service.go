@@ -6,23 +6,41 @@ import (
 	"strings"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/moderation"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/realtime"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/slug"
 )
 
 var (
 	ErrPublicInvitationNotFound = errors.New("public invitation not found")
+	ErrInvalidGuestToken        = errors.New("invalid guest token")
+	ErrMessageRejected          = errors.New("message rejected by moderation")
+	ErrWishStreamUnavailable    = errors.New("wish stream unavailable")
 )
 
 type PublicInvitationService struct {
 	InvitationRepo *repository.InvitationRepository
 	RsvpRepo       *repository.RsvpRepository
 	WishRepo       *repository.WishRepository
+	GuestRepo      *repository.GuestRepository
+	// Moderator screens guest names and messages; nil allows everything.
+	Moderator moderation.Checker
+	// Broker feeds the live wishes stream; nil disables it.
+	Broker realtime.Broker
+	// Enforcer supplies the RSVP and wish quotas of the invitation owner's plan.
+	Enforcer *PlanEnforcer
 }
 
 type CreateRsvpInput struct {
 	CustomerID  string
 	Slug        string
+	GuestToken  string
+	DeviceID    string
 	GuestName   string
 	Attendance  string
 	GuestsCount int
@@ -35,7 +53,11 @@ type CreateRsvpResult struct {
 	Attendance  string
 	GuestsCount int
 	Message     string
-	CreatedAt   time.Time
+	Updated     bool
+	// MessageRejected reports that moderation dropped the message while the answer was saved.
+	MessageRejected bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 type CreateWishInput struct {
@@ -49,15 +71,34 @@ type CreateWishResult struct {
 	ID        string
 	GuestName string
 	Message   string
+	Status    string
 	CreatedAt time.Time
 }
 
+type GetGuestInput struct {
+	CustomerID string
+	Slug       string
+	Token      string
+}
+
 type ListWishesInput struct {
 	CustomerID string
 	Slug       string
+	Cursor     string
 	Limit      int
 }
 
+type StreamWishesInput struct {
+	CustomerID string
+	Slug       string
+}
+
+type ListWishesResult struct {
+	Items      []model.Wish
+	NextCursor string
+	Total      int64
+}
+
 func (s *PublicInvitationService) CreateRsvp(ctx context.Context, input CreateRsvpInput) (CreateRsvpResult, error) {
 	invitation, ok, err := s.InvitationRepo.FindPublishedByCustomerAndSlug(ctx, strings.TrimSpace(input.CustomerID), strings.TrimSpace(input.Slug))
 	if err != nil {
@@ -68,44 +109,188 @@ func (s *PublicInvitationService) CreateRsvp(ctx context.Context, input CreateRs
 	}
 
 	attendance := normalizeAttendance(input.Attendance)
+	guestName := strings.TrimSpace(input.GuestName)
 	guestsCount := input.GuestsCount
 	if guestsCount <= 0 {
 		guestsCount = 1
 	}
 
-	rsvp, err := s.RsvpRepo.Create(ctx, repository.CreateRsvpInput{
+	var guestID *string
+	if token := strings.TrimSpace(input.GuestToken); token != "" {
+		guest, err := s.findGuest(ctx, invitation.ID, token)
+		if err != nil {
+			return CreateRsvpResult{}, err
+		}
+		guestID = &guest.ID
+		guestName = guest.Name
+		if guest.MaxPartySize > 0 && guestsCount > guest.MaxPartySize {
+			guestsCount = guest.MaxPartySize
+		}
+	}
+
+	identityKey, err := rsvpIdentityKey(guestID, input.DeviceID, guestName)
+	if err != nil {
+		return CreateRsvpResult{}, err
+	}
+
+	message := strings.TrimSpace(input.Message)
+	verdict, err := s.screen(ctx, moderation.Input{
 		InvitationID: invitation.ID,
-		GuestName:    strings.TrimSpace(input.GuestName),
-		Attendance:   attendance,
-		GuestsCount:  guestsCount,
-		Message:      strings.TrimSpace(input.Message),
+		Source:       moderation.SourceRSVP,
+		GuestName:    guestName,
+		Message:      message,
 	})
+	messageRejected := false
+	switch {
+	case errors.Is(err, ErrMessageRejected):
+		// The attendance answer still counts; only the message is dropped.
+		message = ""
+		messageRejected = true
+		verdict = moderation.Result{Decision: moderation.Allow}
+	case err != nil:
+		return CreateRsvpResult{}, err
+	}
+
+	limits, err := s.Enforcer.GetCustomerLimits(ctx, invitation.CustomerID)
 	if err != nil {
 		return CreateRsvpResult{}, err
 	}
 
-	message := strings.TrimSpace(input.Message)
-	if message != "" {
-		_, err = s.WishRepo.Create(ctx, repository.CreateWishInput{
+	var rsvp model.RSVP
+	var changedWish *model.Wish
+	updated := false
+	err = s.RsvpRepo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Concurrent first answers would each count only their own insert without the lock.
+		if err := s.InvitationRepo.LockTx(ctx, tx, invitation.ID); err != nil {
+			return err
+		}
+		created, inserted, err := s.RsvpRepo.CreateIfAbsentTx(ctx, tx, repository.CreateRsvpInput{
 			InvitationID: invitation.ID,
-			GuestName:    strings.TrimSpace(input.GuestName),
+			GuestID:      guestID,
+			IdentityKey:  identityKey,
+			GuestName:    guestName,
+			Attendance:   attendance,
+			GuestsCount:  guestsCount,
 			Message:      message,
 		})
 		if err != nil {
-			return CreateRsvpResult{}, err
+			return err
 		}
+		if inserted {
+			// Counting after the insert keeps resubmissions free; going over rolls the insert back.
+			if limits.MaxRsvps >= 0 {
+				total, err := s.RsvpRepo.CountByInvitationIDTx(ctx, tx, invitation.ID)
+				if err != nil {
+					return err
+				}
+				if total > int64(limits.MaxRsvps) {
+					return ErrRsvpQuotaReached
+				}
+			}
+			rsvp = created
+		} else {
+			existing, ok, err := s.RsvpRepo.FindByIdentityForUpdateTx(ctx, tx, invitation.ID, identityKey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("rsvp vanished during resubmission")
+			}
+			if err := s.RsvpRepo.CreateRevisionTx(ctx, tx, existing); err != nil {
+				return err
+			}
+			if err := s.RsvpRepo.UpdateTx(ctx, tx, existing.ID, repository.UpdateRsvpInput{
+				GuestID:     guestID,
+				GuestName:   guestName,
+				Attendance:  attendance,
+				GuestsCount: guestsCount,
+				Message:     message,
+			}); err != nil {
+				return err
+			}
+			existing.GuestID = guestID
+			existing.GuestName = guestName
+			existing.Attendance = attendance
+			existing.GuestsCount = guestsCount
+			existing.Message = message
+			existing.UpdatedAt = time.Now()
+			rsvp = existing
+			updated = true
+		}
+		changedWish, err = s.syncRsvpWish(ctx, tx, invitation, rsvp, verdict)
+		return err
+	})
+	if err != nil {
+		return CreateRsvpResult{}, err
+	}
+	if changedWish != nil {
+		announceWish(ctx, s.Broker, *changedWish)
 	}
 
 	return CreateRsvpResult{
-		ID:          rsvp.ID,
-		GuestName:   rsvp.GuestName,
-		Attendance:  rsvp.Attendance,
-		GuestsCount: rsvp.GuestsCount,
-		Message:     rsvp.Message,
-		CreatedAt:   rsvp.CreatedAt,
+		ID:              rsvp.ID,
+		GuestName:       rsvp.GuestName,
+		Attendance:      rsvp.Attendance,
+		GuestsCount:     rsvp.GuestsCount,
+		Message:         rsvp.Message,
+		Updated:         updated,
+		MessageRejected: messageRejected,
+		CreatedAt:       rsvp.CreatedAt,
+		UpdatedAt:       rsvp.UpdatedAt,
 	}, nil
 }
 
+// syncRsvpWish keeps at most one wish per RSVP, following the message of its latest answer. An
+// edited message goes back to the approval queue when the invitation requires approval or the
+// moderation filter flagged it; a wish the couple hid stays hidden.
+func (s *PublicInvitationService) syncRsvpWish(ctx context.Context, tx *gorm.DB, invitation model.Invitation, rsvp model.RSVP, verdict moderation.Result) (*model.Wish, error) {
+	wish, ok, err := s.WishRepo.FindByRsvpIDTx(ctx, tx, rsvp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ok && rsvp.Message == "":
+		if err := s.WishRepo.DeleteTx(ctx, tx, wish.ID); err != nil {
+			return nil, err
+		}
+		wish.Status = model.WishStatusHidden
+		return &wish, nil
+	case ok:
+		if wish.Message == rsvp.Message && wish.GuestName == rsvp.GuestName {
+			return nil, nil
+		}
+		status, flagReason := wish.Status, wish.FlagReason
+		if wish.Message != rsvp.Message {
+			flagReason = verdict.Reason()
+			if status != model.WishStatusHidden {
+				status = wishStatus(invitation, verdict)
+			}
+		}
+		if err := s.WishRepo.UpdateMessageTx(ctx, tx, wish.ID, rsvp.GuestName, rsvp.Message, status, flagReason); err != nil {
+			return nil, err
+		}
+		wish.GuestName, wish.Message, wish.Status, wish.FlagReason = rsvp.GuestName, rsvp.Message, status, flagReason
+		return &wish, nil
+	case rsvp.Message != "":
+		rsvpID := rsvp.ID
+		created, err := s.WishRepo.CreateTx(ctx, tx, repository.CreateWishInput{
+			InvitationID: rsvp.InvitationID,
+			RsvpID:       &rsvpID,
+			GuestName:    rsvp.GuestName,
+			Message:      rsvp.Message,
+			Status:       wishStatus(invitation, verdict),
+			FlagReason:   verdict.Reason(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &created, nil
+	default:
+		return nil, nil
+	}
+}
+
 func (s *PublicInvitationService) CreateWish(ctx context.Context, input CreateWishInput) (CreateWishResult, error) {
 	invitation, ok, err := s.InvitationRepo.FindPublishedByCustomerAndSlug(ctx, strings.TrimSpace(input.CustomerID), strings.TrimSpace(input.Slug))
 	if err != nil {
@@ -115,33 +300,202 @@ func (s *PublicInvitationService) CreateWish(ctx context.Context, input CreateWi
 		return CreateWishResult{}, ErrPublicInvitationNotFound
 	}
 
-	wish, err := s.WishRepo.Create(ctx, repository.CreateWishInput{
+	limits, err := s.Enforcer.GetCustomerLimits(ctx, invitation.CustomerID)
+	if err != nil {
+		return CreateWishResult{}, err
+	}
+
+	guestName := strings.TrimSpace(input.GuestName)
+	message := strings.TrimSpace(input.Message)
+	verdict, err := s.screen(ctx, moderation.Input{
 		InvitationID: invitation.ID,
-		GuestName:    strings.TrimSpace(input.GuestName),
-		Message:      strings.TrimSpace(input.Message),
+		Source:       moderation.SourceWish,
+		GuestName:    guestName,
+		Message:      message,
+	})
+	if err != nil {
+		return CreateWishResult{}, err
+	}
+
+	var wish model.Wish
+	err = s.WishRepo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.InvitationRepo.LockTx(ctx, tx, invitation.ID); err != nil {
+			return err
+		}
+		if limits.MaxWishes >= 0 {
+			total, err := s.WishRepo.CountGuestbookByInvitationIDTx(ctx, tx, invitation.ID)
+			if err != nil {
+				return err
+			}
+			if total >= int64(limits.MaxWishes) {
+				return ErrWishQuotaReached
+			}
+		}
+
+		var err error
+		wish, err = s.WishRepo.CreateTx(ctx, tx, repository.CreateWishInput{
+			InvitationID: invitation.ID,
+			GuestName:    guestName,
+			Message:      message,
+			Status:       wishStatus(invitation, verdict),
+			FlagReason:   verdict.Reason(),
+		})
+		return err
 	})
 	if err != nil {
 		return CreateWishResult{}, err
 	}
+	if wish.Status == model.WishStatusApproved {
+		announceWish(ctx, s.Broker, wish)
+	}
 
 	return CreateWishResult{
 		ID:        wish.ID,
 		GuestName: wish.GuestName,
 		Message:   wish.Message,
+		Status:    wish.Status,
 		CreatedAt: wish.CreatedAt,
 	}, nil
 }
 
-func (s *PublicInvitationService) ListWishes(ctx context.Context, input ListWishesInput) ([]model.Wish, error) {
+// ListWishes pages through approved wishes with an opaque keyset cursor instead of offsets.
+func (s *PublicInvitationService) ListWishes(ctx context.Context, input ListWishesInput) (ListWishesResult, error) {
+	var after *query.WishCursor
+	if token := strings.TrimSpace(input.Cursor); token != "" {
+		after = &query.WishCursor{}
+		if err := query.DecodeCursor(token, after); err != nil {
+			return ListWishesResult{}, err
+		}
+	}
+
 	invitation, ok, err := s.InvitationRepo.FindPublishedByCustomerAndSlug(ctx, strings.TrimSpace(input.CustomerID), strings.TrimSpace(input.Slug))
 	if err != nil {
-		return nil, err
+		return ListWishesResult{}, err
+	}
+	if !ok {
+		return ListWishesResult{}, ErrPublicInvitationNotFound
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	items, err := s.WishRepo.ListApprovedByInvitationID(ctx, invitation.ID, after, limit)
+	if err != nil {
+		return ListWishesResult{}, err
+	}
+	total, err := s.WishRepo.CountApprovedByInvitationID(ctx, invitation.ID)
+	if err != nil {
+		return ListWishesResult{}, err
+	}
+
+	result := ListWishesResult{Items: items, Total: total}
+	if len(items) > limit {
+		result.Items = items[:limit]
+		last := result.Items[limit-1]
+		result.NextCursor, err = query.EncodeCursor(query.WishCursor{
+			Pinned:    last.Pinned,
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+		if err != nil {
+			return ListWishesResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// SubscribeWishes opens the live feed of wishes of a published invitation. The caller must call
+// the returned function once it stops reading.
+func (s *PublicInvitationService) SubscribeWishes(ctx context.Context, input StreamWishesInput) (<-chan realtime.Event, func(), error) {
+	if s.Broker == nil {
+		return nil, nil, ErrWishStreamUnavailable
+	}
+
+	invitation, ok, err := s.InvitationRepo.FindPublishedByCustomerAndSlug(ctx, strings.TrimSpace(input.CustomerID), strings.TrimSpace(input.Slug))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrPublicInvitationNotFound
+	}
+
+	events, unsubscribe := s.Broker.Subscribe(invitation.ID)
+	return events, unsubscribe, nil
+}
+
+// GetGuest resolves a personalized invitation link token for a published invitation.
+func (s *PublicInvitationService) GetGuest(ctx context.Context, input GetGuestInput) (model.Guest, error) {
+	invitation, ok, err := s.InvitationRepo.FindPublishedByCustomerAndSlug(ctx, strings.TrimSpace(input.CustomerID), strings.TrimSpace(input.Slug))
+	if err != nil {
+		return model.Guest{}, err
+	}
+	if !ok {
+		return model.Guest{}, ErrPublicInvitationNotFound
+	}
+
+	return s.findGuest(ctx, invitation.ID, strings.TrimSpace(input.Token))
+}
+
+func (s *PublicInvitationService) findGuest(ctx context.Context, invitationID, token string) (model.Guest, error) {
+	if s.GuestRepo == nil || token == "" {
+		return model.Guest{}, ErrInvalidGuestToken
+	}
+
+	guest, ok, err := s.GuestRepo.FindByInvitationAndToken(ctx, invitationID, token)
+	if err != nil {
+		return model.Guest{}, err
 	}
 	if !ok {
-		return nil, ErrPublicInvitationNotFound
+		return model.Guest{}, ErrInvalidGuestToken
 	}
+	return guest, nil
+}
 
-	return s.WishRepo.ListByInvitationID(ctx, invitation.ID, input.Limit)
+// screen runs the moderator and turns a reject decision into ErrMessageRejected.
+func (s *PublicInvitationService) screen(ctx context.Context, input moderation.Input) (moderation.Result, error) {
+	if s.Moderator == nil {
+		return moderation.Result{Decision: moderation.Allow}, nil
+	}
+
+	result, err := s.Moderator.Check(ctx, input)
+	if err != nil {
+		return moderation.Result{}, err
+	}
+	if result.Decision == moderation.Reject {
+		return result, ErrMessageRejected
+	}
+	return result, nil
+}
+
+// wishStatus holds new wishes for review when the invitation requires approval or the
+// moderation filter flagged them.
+func wishStatus(invitation model.Invitation, verdict moderation.Result) string {
+	if invitation.RequireWishApproval || verdict.Decision == moderation.Flag {
+		return model.WishStatusPending
+	}
+	return model.WishStatusApproved
+}
+
+// rsvpIdentityKey identifies who is answering: the invited guest when a personal link is used,
+// otherwise the browser's device id combined with the typed name. Without either, every
+// submission gets its own identity.
+func rsvpIdentityKey(guestID *string, deviceID, guestName string) (string, error) {
+	if guestID != nil {
+		return "guest:" + *guestID, nil
+	}
+
+	name := strings.ToLower(strings.Join(strings.Fields(guestName), " "))
+	if deviceID = strings.TrimSpace(deviceID); deviceID != "" && name != "" {
+		return "device:" + deviceID + ":" + name, nil
+	}
+
+	token, err := slug.GenerateToken(guestTokenSize)
+	if err != nil {
+		return "", err
+	}
+	return "anonymous:" + token, nil
 }
 
 func normalizeAttendance(value string) string {
@@ -0,0 +1,446 @@
+package customer
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/internal/testdb"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/query"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+const (
+	testInvitationID = "3c2b1a09-8f7e-4d6c-9b5a-4e3d2c1b0a9f"
+	testSlug         = "rina-dan-bayu"
+	testGuestID      = "7a6b5c4d-3e2f-4a1b-8c9d-0e1f2a3b4c5d"
+	testGuestToken   = "Zx8kQ2mN4pR6sT1v"
+	testRsvpID       = "2e4f6a8c-1b3d-4e5f-9a7b-6c8d0e2f4a6b"
+)
+
+var guestColumns = []string{"id", "invitation_id", "name", "phone", "group_name", "seat", "max_party_size", "token"}
+
+func newPublicService(t *testing.T) (*PublicInvitationService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := testdb.New(t)
+	return &PublicInvitationService{
+		InvitationRepo: &repository.InvitationRepository{DB: db},
+		RsvpRepo:       &repository.RsvpRepository{DB: db},
+		WishRepo:       &repository.WishRepository{DB: db},
+		GuestRepo:      &repository.GuestRepository{DB: db},
+		Enforcer:       &PlanEnforcer{PaymentRepo: &repository.PaymentRepository{DB: db}},
+	}, mock
+}
+
+func expectPublishedInvitation(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT \* FROM "invitations" WHERE customer_id = \$1 AND slug = \$2 AND is_published = \$3`).
+		WithArgs(testCustomerID, testSlug, true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "slug", "is_published", "published_content"}).
+			AddRow(testInvitationID, testCustomerID, testSlug, true, []byte(allowedContent)))
+}
+
+func expectGuest(mock sqlmock.Sqlmock, token string, rows *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT \* FROM "guests" WHERE invitation_id = \$1 AND token = \$2`).
+		WithArgs(testInvitationID, token, 1).
+		WillReturnRows(rows)
+}
+
+// expectRsvpInsert expects the quota lock and the insert of a new RSVP, which is then counted.
+// A negative total expects no count, as for plans without an RSVP cap.
+func expectRsvpInsert(mock sqlmock.Sqlmock, args []driver.Value, total int) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT 1 FROM invitations WHERE id = \$1 FOR UPDATE`).
+		WithArgs(testInvitationID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "rsvps" .* ON CONFLICT \("invitation_id","identity_key"\) DO NOTHING`).
+		WithArgs(append(args, sqlmock.AnyArg(), sqlmock.AnyArg())...).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testRsvpID))
+	if total < 0 {
+		return
+	}
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "rsvps" WHERE invitation_id = \$1`).
+		WithArgs(testInvitationID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(total))
+}
+
+func expectNoRsvpWish(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT \* FROM "wishes" WHERE rsvp_id = \$1`).
+		WithArgs(testRsvpID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestGetGuest(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		rows    *sqlmock.Rows
+		wantErr error
+	}{
+		{
+			name:  "personal link",
+			token: testGuestToken,
+			rows:  sqlmock.NewRows(guestColumns).AddRow(testGuestID, testInvitationID, "Pak Hendra", "628123", "Keluarga", "A3", 2, testGuestToken),
+		},
+		{
+			name:    "unknown token",
+			token:   "not-a-guest",
+			rows:    sqlmock.NewRows(guestColumns),
+			wantErr: ErrInvalidGuestToken,
+		},
+		{
+			name:    "blank token",
+			token:   "  ",
+			wantErr: ErrInvalidGuestToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock := newPublicService(t)
+			expectPublishedInvitation(mock)
+			if tt.rows != nil {
+				expectGuest(mock, tt.token, tt.rows)
+			}
+
+			guest, err := svc.GetGuest(context.Background(), GetGuestInput{CustomerID: testCustomerID, Slug: testSlug, Token: tt.token})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetGuest err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (guest.ID != testGuestID || guest.Seat != "A3" || guest.MaxPartySize != 2) {
+				t.Fatalf("GetGuest = %+v", guest)
+			}
+		})
+	}
+}
+
+func TestGetGuestUnpublishedInvitation(t *testing.T) {
+	svc, mock := newPublicService(t)
+	mock.ExpectQuery(`SELECT \* FROM "invitations" WHERE customer_id = \$1 AND slug = \$2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := svc.GetGuest(context.Background(), GetGuestInput{CustomerID: testCustomerID, Slug: testSlug, Token: testGuestToken}); !errors.Is(err, ErrPublicInvitationNotFound) {
+		t.Fatalf("GetGuest err = %v, want ErrPublicInvitationNotFound", err)
+	}
+}
+
+func TestCreateRsvpWithGuestLink(t *testing.T) {
+	svc, mock := newPublicService(t)
+
+	// The invited guest's name replaces the typed one, and the party is capped at their seats.
+	expectPublishedInvitation(mock)
+	expectGuest(mock, testGuestToken, sqlmock.NewRows(guestColumns).
+		AddRow(testGuestID, testInvitationID, "Pak Hendra", "", "", "", 2, testGuestToken))
+	expectNoPlan(mock, testCustomerID)
+	expectRsvpInsert(mock, []driver.Value{testInvitationID, testGuestID, "guest:" + testGuestID, "Pak Hendra", "attending", 2, ""}, 1)
+	expectNoRsvpWish(mock)
+	mock.ExpectCommit()
+
+	result, err := svc.CreateRsvp(context.Background(), CreateRsvpInput{
+		CustomerID:  testCustomerID,
+		Slug:        testSlug,
+		GuestToken:  testGuestToken,
+		GuestName:   "Someone Else",
+		Attendance:  "hadir",
+		GuestsCount: 5,
+	})
+	if err != nil {
+		t.Fatalf("CreateRsvp: %v", err)
+	}
+	if result.ID != testRsvpID || result.GuestName != "Pak Hendra" || result.GuestsCount != 2 || result.Updated {
+		t.Fatalf("CreateRsvp = %+v", result)
+	}
+}
+
+func TestCreateRsvpWithUnknownGuestLink(t *testing.T) {
+	svc, mock := newPublicService(t)
+	expectPublishedInvitation(mock)
+	expectGuest(mock, "forged", sqlmock.NewRows(guestColumns))
+
+	// A forged token is refused rather than recorded as an uninvited answer.
+	_, err := svc.CreateRsvp(context.Background(), CreateRsvpInput{
+		CustomerID: testCustomerID,
+		Slug:       testSlug,
+		GuestToken: "forged",
+		GuestName:  "Pak Hendra",
+	})
+	if !errors.Is(err, ErrInvalidGuestToken) {
+		t.Fatalf("CreateRsvp err = %v, want ErrInvalidGuestToken", err)
+	}
+}
+
+func TestRsvpIdentityKey(t *testing.T) {
+	guestID := testGuestID
+	tests := []struct {
+		name      string
+		guestID   *string
+		deviceID  string
+		guestName string
+		want      string
+	}{
+		{name: "personal link wins over the device", guestID: &guestID, deviceID: "device-1", guestName: "Pak Hendra", want: "guest:" + testGuestID},
+		{name: "device and normalized name", deviceID: " device-1 ", guestName: "  Pak   HENDRA ", want: "device:device-1:pak hendra"},
+		{name: "device without a name", deviceID: "device-1", want: "anonymous:"},
+		{name: "name without a device", guestName: "Pak Hendra", want: "anonymous:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rsvpIdentityKey(tt.guestID, tt.deviceID, tt.guestName)
+			if err != nil {
+				t.Fatalf("rsvpIdentityKey: %v", err)
+			}
+			if tt.want == "anonymous:" {
+				if !strings.HasPrefix(got, tt.want) || len(got) == len(tt.want) {
+					t.Fatalf("rsvpIdentityKey = %q, want a fresh anonymous identity", got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("rsvpIdentityKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	first, _ := rsvpIdentityKey(nil, "", "Pak Hendra")
+	second, _ := rsvpIdentityKey(nil, "", "Pak Hendra")
+	if first == second {
+		t.Fatalf("two anonymous submissions share the identity %q", first)
+	}
+}
+
+func TestCreateRsvpResubmission(t *testing.T) {
+	svc, mock := newPublicService(t)
+	answeredAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	identity := "device:device-1:pak hendra"
+
+	expectPublishedInvitation(mock)
+	// The plan allows a single RSVP, which this guest already holds; changing it stays free.
+	expectActivePlan(mock, testCustomerID, "basic", `{"max_rsvps": 1}`)
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT 1 FROM invitations WHERE id = \$1 FOR UPDATE`).
+		WithArgs(testInvitationID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "rsvps" .* ON CONFLICT \("invitation_id","identity_key"\) DO NOTHING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM "rsvps" WHERE invitation_id = \$1 AND identity_key = \$2 .*FOR UPDATE`).
+		WithArgs(testInvitationID, identity, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "invitation_id", "identity_key", "guest_name", "attendance", "guests_count", "message", "created_at", "updated_at"}).
+			AddRow(testRsvpID, testInvitationID, identity, "Pak Hendra", "attending", 3, "Selamat!", answeredAt, answeredAt))
+	// The previous answer goes to the audit history before it is overwritten.
+	mock.ExpectQuery(`INSERT INTO "rsvp_revisions"`).
+		WithArgs(testRsvpID, "Pak Hendra", "attending", 3, "Selamat!", answeredAt, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("revision-1"))
+	// The answer is replaced in place, including the latest spelling of the name.
+	mock.ExpectExec(`UPDATE "rsvps" SET "attendance"=\$1,"guest_id"=\$2,"guest_name"=\$3,"guests_count"=\$4,"message"=\$5,"updated_at"=now\(\) WHERE id = \$6`).
+		WithArgs("not_attending", nil, "pak hendra", 1, "Maaf berhalangan", testRsvpID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The wish of the first answer follows the new message instead of a second wish being added.
+	mock.ExpectQuery(`SELECT \* FROM "wishes" WHERE rsvp_id = \$1`).
+		WithArgs(testRsvpID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "invitation_id", "rsvp_id", "guest_name", "message", "status"}).
+			AddRow("wish-1", testInvitationID, testRsvpID, "Pak Hendra", "Selamat!", model.WishStatusApproved))
+	mock.ExpectExec(`UPDATE "wishes" SET "flag_reason"=\$1,"guest_name"=\$2,"message"=\$3,"status"=\$4 WHERE id = \$5`).
+		WithArgs("", "pak hendra", "Maaf berhalangan", model.WishStatusApproved, "wish-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := svc.CreateRsvp(context.Background(), CreateRsvpInput{
+		CustomerID: testCustomerID,
+		Slug:       testSlug,
+		DeviceID:   "device-1",
+		GuestName:  "pak hendra ",
+		Attendance: "tidak-hadir",
+		Message:    "Maaf berhalangan",
+	})
+	if err != nil {
+		t.Fatalf("CreateRsvp: %v", err)
+	}
+	if !result.Updated || result.ID != testRsvpID || result.Attendance != "not_attending" || result.GuestsCount != 1 {
+		t.Fatalf("CreateRsvp = %+v", result)
+	}
+	if !result.CreatedAt.Equal(answeredAt) {
+		t.Fatalf("CreatedAt = %v, want the first answer's %v", result.CreatedAt, answeredAt)
+	}
+}
+
+// Without a device id nothing ties two submissions together, so each one is a new RSVP and
+// counts against max_rsvps, even when the typed name repeats.
+func TestCreateRsvpAnonymousSubmissionsCountAgainstQuota(t *testing.T) {
+	svc, mock := newPublicService(t)
+	input := CreateRsvpInput{CustomerID: testCustomerID, Slug: testSlug, GuestName: "Pak Hendra"}
+	anonymous := sqlmock.AnyArg()
+
+	expectPublishedInvitation(mock)
+	expectActivePlan(mock, testCustomerID, "basic", `{"max_rsvps": 2}`)
+	expectRsvpInsert(mock, []driver.Value{testInvitationID, nil, anonymous, "Pak Hendra", "attending", 1, ""}, 2)
+	expectNoRsvpWish(mock)
+	mock.ExpectCommit()
+
+	result, err := svc.CreateRsvp(context.Background(), input)
+	if err != nil {
+		t.Fatalf("CreateRsvp: %v", err)
+	}
+	if result.Updated {
+		t.Fatal("an anonymous submission updated an earlier RSVP")
+	}
+
+	expectPublishedInvitation(mock)
+	expectActivePlan(mock, testCustomerID, "basic", `{"max_rsvps": 2}`)
+	expectRsvpInsert(mock, []driver.Value{testInvitationID, nil, anonymous, "Pak Hendra", "attending", 1, ""}, 3)
+	mock.ExpectRollback()
+
+	if _, err := svc.CreateRsvp(context.Background(), input); !errors.Is(err, ErrRsvpQuotaReached) {
+		t.Fatalf("CreateRsvp err = %v, want ErrRsvpQuotaReached", err)
+	}
+}
+
+func TestCreateRsvpQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  string
+		total   int
+		wantErr error
+	}{
+		{name: "last place left", limits: `{"max_rsvps": 2}`, total: 2},
+		{name: "limit reached", limits: `{"max_rsvps": 2}`, total: 3, wantErr: ErrRsvpQuotaReached},
+		{name: "plan default", limits: `{}`, total: 150},
+		{name: "plan default reached", limits: `{}`, total: 151, wantErr: ErrRsvpQuotaReached},
+		{name: "unlimited plan", limits: `{"max_rsvps": -1}`, total: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock := newPublicService(t)
+			expectPublishedInvitation(mock)
+			expectActivePlan(mock, testCustomerID, "premium", tt.limits)
+			expectRsvpInsert(mock, []driver.Value{testInvitationID, nil, "device:phone-1:budi", "Budi", "attending", 1, ""}, tt.total)
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				expectNoRsvpWish(mock)
+				mock.ExpectCommit()
+			}
+
+			result, err := svc.CreateRsvp(context.Background(), CreateRsvpInput{
+				CustomerID: testCustomerID,
+				Slug:       testSlug,
+				DeviceID:   "phone-1",
+				GuestName:  "Budi",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRsvp err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && result.ID != testRsvpID {
+				t.Fatalf("CreateRsvp = %+v", result)
+			}
+		})
+	}
+}
+
+func TestCreateWishQuota(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits string
+		// total is the number of guestbook wishes before this one, or -1 when nothing counts them.
+		total   int
+		wantErr error
+	}{
+		{name: "last place left", limits: `{"max_wishes": 2}`, total: 1},
+		{name: "limit reached", limits: `{"max_wishes": 2}`, total: 2, wantErr: ErrWishQuotaReached},
+		{name: "plan default reached", limits: `{}`, total: 150, wantErr: ErrWishQuotaReached},
+		{name: "unlimited plan", limits: `{"max_wishes": -1}`, total: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock := newPublicService(t)
+			expectPublishedInvitation(mock)
+			expectActivePlan(mock, testCustomerID, "premium", tt.limits)
+			mock.ExpectBegin()
+			mock.ExpectExec(`SELECT 1 FROM invitations WHERE id = \$1 FOR UPDATE`).
+				WithArgs(testInvitationID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			if tt.total >= 0 {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "wishes" WHERE invitation_id = \$1 AND rsvp_id IS NULL`).
+					WithArgs(testInvitationID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.total))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectQuery(`INSERT INTO "wishes"`).
+					WithArgs(testInvitationID, nil, "Budi", "Selamat ya", model.WishStatusApproved, false, "", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wish-1"))
+				mock.ExpectCommit()
+			}
+
+			result, err := svc.CreateWish(context.Background(), CreateWishInput{
+				CustomerID: testCustomerID,
+				Slug:       testSlug,
+				GuestName:  "Budi",
+				Message:    "Selamat ya",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateWish err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && result.ID != "wish-1" {
+				t.Fatalf("CreateWish = %+v", result)
+			}
+		})
+	}
+}
+
+func TestListWishesPagesThroughEqualTimestamps(t *testing.T) {
+	svc, mock := newPublicService(t)
+	// Wishes sent in the same instant are told apart by id.
+	sentAt := time.Date(2026, 10, 1, 9, 30, 0, 123456000, time.UTC)
+	wishColumns := []string{"id", "invitation_id", "guest_name", "message", "status", "pinned", "created_at"}
+	expectPage := func(args []driver.Value, rows *sqlmock.Rows) {
+		expectPublishedInvitation(mock)
+		mock.ExpectQuery(`SELECT \* FROM "wishes" WHERE .* ORDER BY pinned DESC,created_at DESC,id DESC LIMIT \$\d+`).
+			WithArgs(args...).
+			WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "wishes"`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	}
+
+	expectPage([]driver.Value{testInvitationID, model.WishStatusApproved, 3}, sqlmock.NewRows(wishColumns).
+		AddRow("wish-4", testInvitationID, "Bu Sari", "Pinned", model.WishStatusApproved, true, sentAt.Add(-time.Hour)).
+		AddRow("wish-3", testInvitationID, "Budi", "Selamat", model.WishStatusApproved, false, sentAt).
+		AddRow("wish-2", testInvitationID, "Ani", "Bahagia", model.WishStatusApproved, false, sentAt))
+	first, err := svc.ListWishes(context.Background(), ListWishesInput{CustomerID: testCustomerID, Slug: testSlug, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListWishes: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[1].ID != "wish-3" || first.NextCursor == "" || first.Total != 4 {
+		t.Fatalf("first page = %+v", first)
+	}
+
+	// The second page starts after wish-3, so wish-2, which shares its timestamp, is not skipped.
+	expectPage([]driver.Value{testInvitationID, model.WishStatusApproved, false, sentAt, "wish-3", 3}, sqlmock.NewRows(wishColumns).
+		AddRow("wish-2", testInvitationID, "Ani", "Bahagia", model.WishStatusApproved, false, sentAt).
+		AddRow("wish-1", testInvitationID, "Rudi", "Semoga", model.WishStatusApproved, false, sentAt))
+	second, err := svc.ListWishes(context.Background(), ListWishesInput{CustomerID: testCustomerID, Slug: testSlug, Cursor: first.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListWishes: %v", err)
+	}
+	if len(second.Items) != 2 || second.Items[0].ID != "wish-2" || second.NextCursor != "" {
+		t.Fatalf("last page = %+v", second)
+	}
+}
+
+func TestListWishesRejectsGarbledCursor(t *testing.T) {
+	svc, _ := newPublicService(t)
+
+	// The cursor is checked before the database is touched.
+	_, err := svc.ListWishes(context.Background(), ListWishesInput{CustomerID: testCustomerID, Slug: testSlug, Cursor: "eyJpIjoid2lzaC0zIn0"})
+	if !errors.Is(err, query.ErrInvalidCursor) {
+		t.Fatalf("ListWishes err = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,44 @@
+package customer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+var ErrRsvpServiceNotConfigured = errors.New("rsvp service not configured")
+
+type RsvpService struct {
+	Repo           *repository.RsvpRepository
+	InvitationRepo *repository.InvitationRepository
+}
+
+func (s *RsvpService) List(ctx context.Context, customerID string, filters repository.RsvpListFilters) ([]model.RSVP, int64, error) {
+	if err := s.ensureOwnership(ctx, customerID, filters.InvitationID); err != nil {
+		return nil, 0, err
+	}
+	return s.Repo.ListByInvitationID(ctx, filters)
+}
+
+func (s *RsvpService) Summary(ctx context.Context, customerID, invitationID string) (repository.RsvpSummary, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return repository.RsvpSummary{}, err
+	}
+	return s.Repo.SummaryByInvitationID(ctx, invitationID)
+}
+
+func (s *RsvpService) Export(ctx context.Context, customerID string, filters repository.RsvpListFilters, fn func(model.RSVP) error) error {
+	if err := s.ensureOwnership(ctx, customerID, filters.InvitationID); err != nil {
+		return err
+	}
+	return s.Repo.EachByInvitationID(ctx, filters, fn)
+}
+
+func (s *RsvpService) ensureOwnership(ctx context.Context, customerID, invitationID string) error {
+	if s == nil || s.Repo == nil || s.InvitationRepo == nil {
+		return ErrRsvpServiceNotConfigured
+	}
+	return ensureInvitationOwner(ctx, s.InvitationRepo, customerID, invitationID)
+}
@@ -0,0 +1,51 @@
+package customer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/realtime"
+)
+
+// Event names of the live wishes feed. Clients upsert on WishEventPublished and drop the wish on
+// WishEventRemoved.
+const (
+	WishEventPublished = "wish"
+	WishEventRemoved   = "wish_removed"
+)
+
+type wishEventData struct {
+	ID        string    `json:"id"`
+	GuestName string    `json:"guest_name,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Pinned    bool      `json:"pinned"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// announceWish pushes a wish to the live feed of its invitation when it is visible to guests, and
+// tells the feed to drop it otherwise. Delivery is best effort and never fails the request.
+func announceWish(ctx context.Context, broker realtime.Broker, wish model.Wish) {
+	if broker == nil {
+		return
+	}
+
+	name := WishEventPublished
+	data := wishEventData{ID: wish.ID, Pinned: wish.Pinned, CreatedAt: wish.CreatedAt}
+	if wish.Status == model.WishStatusApproved {
+		data.GuestName = wish.GuestName
+		data.Message = wish.Message
+	} else {
+		name = WishEventRemoved
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if err := broker.Publish(ctx, wish.InvitationID, realtime.Event{ID: wish.ID, Name: name, Data: payload}); err != nil {
+		log.Printf("publish %s event for wish %s: %v", name, wish.ID, err)
+	}
+}
@@ -0,0 +1,85 @@
+package customer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/realtime"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+)
+
+var (
+	ErrWishServiceNotConfigured = errors.New("wish service not configured")
+	ErrWishNotFound             = errors.New("wish not found")
+)
+
+type WishService struct {
+	Repo           *repository.WishRepository
+	InvitationRepo *repository.InvitationRepository
+	Broker         realtime.Broker
+}
+
+func (s *WishService) List(ctx context.Context, customerID string, filters repository.WishListFilters) ([]model.Wish, int64, error) {
+	if err := s.ensureOwnership(ctx, customerID, filters.InvitationID); err != nil {
+		return nil, 0, err
+	}
+	return s.Repo.ListByInvitationID(ctx, filters)
+}
+
+// Moderate approves, hides or (un)pins a wish of the customer's invitation.
+func (s *WishService) Moderate(ctx context.Context, customerID, invitationID, wishID string, input repository.WishModerationInput) (model.Wish, error) {
+	wish, err := s.find(ctx, customerID, invitationID, wishID)
+	if err != nil {
+		return model.Wish{}, err
+	}
+
+	if err := s.Repo.UpdateModeration(ctx, wish.ID, input); err != nil {
+		return model.Wish{}, err
+	}
+
+	if input.Status != nil {
+		wish.Status = *input.Status
+	}
+	if input.Pinned != nil {
+		wish.Pinned = *input.Pinned
+	}
+	announceWish(ctx, s.Broker, wish)
+	return wish, nil
+}
+
+func (s *WishService) Delete(ctx context.Context, customerID, invitationID, wishID string) error {
+	wish, err := s.find(ctx, customerID, invitationID, wishID)
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.Delete(ctx, wish.ID); err != nil {
+		return err
+	}
+
+	wish.Status = model.WishStatusHidden
+	announceWish(ctx, s.Broker, wish)
+	return nil
+}
+
+func (s *WishService) find(ctx context.Context, customerID, invitationID, wishID string) (model.Wish, error) {
+	if err := s.ensureOwnership(ctx, customerID, invitationID); err != nil {
+		return model.Wish{}, err
+	}
+
+	wish, ok, err := s.Repo.GetByIDAndInvitation(ctx, wishID, invitationID)
+	if err != nil {
+		return model.Wish{}, err
+	}
+	if !ok {
+		return model.Wish{}, ErrWishNotFound
+	}
+	return wish, nil
+}
+
+func (s *WishService) ensureOwnership(ctx context.Context, customerID, invitationID string) error {
+	if s == nil || s.Repo == nil || s.InvitationRepo == nil {
+		return ErrWishServiceNotConfigured
+	}
+	return ensureInvitationOwner(ctx, s.InvitationRepo, customerID, invitationID)
+}
@@ -3,7 +3,10 @@ package external
 import (
 	"bytes"
 	"context"
+	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,26 +25,6 @@ type MidtransService struct {
 	client    *http.Client
 }
 
-type MidtransCreateTransactionInput struct {
-	OrderID  string
-	Amount   int
-	Currency string
-	Email    string
-	FullName string
-	PlanCode string
-	PlanName string
-}
-
-type MidtransCreateTransactionResult struct {
-	Token       string
-	RedirectURL string
-}
-
-type MidtransTransactionStatus struct {
-	TransactionStatus string
-	FraudStatus       string
-}
-
 type midtransCreateTransactionRequest struct {
 	TransactionDetails midtransTransactionDetails `json:"transaction_details"`
 	CustomerDetails    midtransCustomerDetails    `json:"customer_details"`
@@ -72,8 +55,31 @@ type midtransCreateTransactionResponse struct {
 }
 
 type midtransStatusResponse struct {
+	OrderID           string `json:"order_id"`
 	TransactionStatus string `json:"transaction_status"`
 	FraudStatus       string `json:"fraud_status"`
+	TransactionID     string `json:"transaction_id"`
+	PaymentType       string `json:"payment_type"`
+}
+
+// midtransNotification is the body Midtrans posts to the webhook; it carries the status fields
+// plus what the signature is computed from.
+type midtransNotification struct {
+	midtransStatusResponse
+	StatusCode   string `json:"status_code"`
+	GrossAmount  string `json:"gross_amount"`
+	SignatureKey string `json:"signature_key"`
+}
+
+type midtransRefundRequest struct {
+	RefundKey string `json:"refund_key"`
+	Amount    int    `json:"amount,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type midtransRefundResponse struct {
+	StatusCode    string `json:"status_code"`
+	StatusMessage string `json:"status_message"`
 }
 
 func NewMidtransService(baseURL, clientKey, serverKey string, client *http.Client) *MidtransService {
@@ -93,6 +99,10 @@ func NewMidtransService(baseURL, clientKey, serverKey string, client *http.Clien
 	}
 }
 
+func (s *MidtransService) Provider() string {
+	return PaymentProviderMidtrans
+}
+
 func (s *MidtransService) BaseURL() string {
 	return s.baseURL
 }
@@ -105,7 +115,7 @@ func (s *MidtransService) ServerKey() string {
 	return s.serverKey
 }
 
-func (s *MidtransService) CreateTransaction(ctx context.Context, input MidtransCreateTransactionInput) (MidtransCreateTransactionResult, error) {
+func (s *MidtransService) CreateTransaction(ctx context.Context, input GatewayTransactionInput) (GatewayTransaction, error) {
 	firstName, lastName := splitFullName(input.FullName)
 	planCode := strings.TrimSpace(input.PlanCode)
 	planName := strings.TrimSpace(input.PlanName)
@@ -136,30 +146,120 @@ func (s *MidtransService) CreateTransaction(ctx context.Context, input MidtransC
 
 	var response midtransCreateTransactionResponse
 	if err := s.Post(ctx, "/snap/v1/transactions", payload, &response); err != nil {
-		return MidtransCreateTransactionResult{}, err
+		return GatewayTransaction{}, err
 	}
 
-	return MidtransCreateTransactionResult{
+	return GatewayTransaction{
 		Token:       response.Token,
 		RedirectURL: response.RedirectURL,
+		ClientKey:   s.clientKey,
 	}, nil
 }
 
-func (s *MidtransService) GetTransactionStatus(ctx context.Context, orderID string) (MidtransTransactionStatus, error) {
+func (s *MidtransService) GetTransactionStatus(ctx context.Context, orderID string) (GatewayStatus, error) {
 	orderID = strings.TrimSpace(orderID)
 	if orderID == "" {
-		return MidtransTransactionStatus{}, fmt.Errorf("order id is empty")
+		return GatewayStatus{}, fmt.Errorf("order id is empty")
 	}
 
 	var response midtransStatusResponse
 	if err := s.Get(ctx, "/v2/"+url.PathEscape(orderID)+"/status", &response); err != nil {
-		return MidtransTransactionStatus{}, err
+		return GatewayStatus{}, err
+	}
+	if strings.TrimSpace(response.OrderID) == "" {
+		response.OrderID = orderID
 	}
 
-	return MidtransTransactionStatus{
-		TransactionStatus: strings.TrimSpace(response.TransactionStatus),
-		FraudStatus:       strings.TrimSpace(response.FraudStatus),
-	}, nil
+	return response.gatewayStatus(), nil
+}
+
+// VerifyWebhook checks the notification's signature_key, a SHA-512 of the order id, status code,
+// gross amount and server key.
+func (s *MidtransService) VerifyWebhook(_ http.Header, body []byte) (GatewayStatus, error) {
+	var notification midtransNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return GatewayStatus{}, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	if strings.TrimSpace(notification.OrderID) == "" || strings.TrimSpace(notification.TransactionStatus) == "" {
+		return GatewayStatus{}, fmt.Errorf("%w: order_id and transaction_status are required", ErrInvalidWebhookPayload)
+	}
+	if !s.validSignature(notification) {
+		return GatewayStatus{}, ErrInvalidWebhookSignature
+	}
+	return notification.gatewayStatus(), nil
+}
+
+func (s *MidtransService) validSignature(notification midtransNotification) bool {
+	if s.serverKey == "" {
+		return false
+	}
+	signatureKey := strings.ToLower(strings.TrimSpace(notification.SignatureKey))
+	if signatureKey == "" {
+		return false
+	}
+
+	raw := strings.TrimSpace(notification.OrderID) + strings.TrimSpace(notification.StatusCode) + strings.TrimSpace(notification.GrossAmount) + s.serverKey
+	hash := sha512.Sum512([]byte(raw))
+	expected := hex.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(signatureKey), []byte(expected)) == 1
+}
+
+// Refund refunds a settled transaction; Midtrans then notifies the webhook with the refund status.
+// The order id doubles as the refund key, so a retried request is not refunded twice.
+func (s *MidtransService) Refund(ctx context.Context, input GatewayRefundInput) error {
+	orderID := strings.TrimSpace(input.OrderID)
+	if orderID == "" {
+		return fmt.Errorf("order id is empty")
+	}
+	var response midtransRefundResponse
+	if err := s.Post(ctx, "/v2/"+url.PathEscape(orderID)+"/refund", midtransRefundRequest{
+		RefundKey: "refund-" + orderID,
+		Amount:    input.Amount,
+		Reason:    strings.TrimSpace(input.Reason),
+	}, &response); err != nil {
+		return err
+	}
+	// The core API answers HTTP 200 and reports failures in the body.
+	if code := strings.TrimSpace(response.StatusCode); code != "" && code != "200" {
+		return fmt.Errorf("midtrans refund %s failed: status=%s message=%s", orderID, code, strings.TrimSpace(response.StatusMessage))
+	}
+	return nil
+}
+
+func (r midtransStatusResponse) gatewayStatus() GatewayStatus {
+	return GatewayStatus{
+		OrderID:       strings.TrimSpace(r.OrderID),
+		TransactionID: strings.TrimSpace(r.TransactionID),
+		PaymentType:   strings.TrimSpace(r.PaymentType),
+		RawStatus:     strings.TrimSpace(r.TransactionStatus),
+		Status:        NormalizeMidtransStatus(r.TransactionStatus, r.FraudStatus),
+	}
+}
+
+// NormalizeMidtransStatus maps a Midtrans transaction_status, and the fraud_status of card
+// captures, to a PaymentStatus*. A partial refund or chargeback leaves the payment paid: the
+// customer keeps the plan, and the raw status records what happened for the admins.
+func NormalizeMidtransStatus(transactionStatus, fraudStatus string) string {
+	status := strings.ToLower(strings.TrimSpace(transactionStatus))
+	fraud := strings.ToLower(strings.TrimSpace(fraudStatus))
+
+	switch status {
+	case "capture":
+		if fraud == "challenge" {
+			return PaymentStatusPending
+		}
+		return PaymentStatusPaid
+	case "settlement", "partial_refund", "partial_chargeback":
+		return PaymentStatusPaid
+	case "pending":
+		return PaymentStatusPending
+	case "deny", "cancel", "expire", "failure":
+		return PaymentStatusFailed
+	case "refund", "chargeback":
+		return PaymentStatusRefunded
+	default:
+		return PaymentStatusPending
+	}
 }
 
 func (s *MidtransService) Post(ctx context.Context, path string, payload any, out any) error {
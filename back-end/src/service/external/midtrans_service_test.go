@@ -0,0 +1,243 @@
+package external
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testServerKey = "SB-Mid-server-abc"
+
+// recordedRequest is what a stand-in gateway received.
+type recordedRequest struct {
+	method string
+	path   string
+	query  string
+	header http.Header
+	body   map[string]any
+}
+
+// newGatewayServer answers every request with status and body, recording it for the test.
+func newGatewayServer(t *testing.T, status int, body string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+	got := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got.method, got.path, got.query, got.header = r.Method, r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Clone()
+		got.body = nil
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &got.body); err != nil {
+				t.Errorf("request body is not JSON: %s", raw)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func basicAuth(user string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"))
+}
+
+func TestMidtransCreateTransaction(t *testing.T) {
+	server, got := newGatewayServer(t, http.StatusCreated, `{"token":"snap-token","redirect_url":"https://app.midtrans.com/snap/v4/redirection/snap-token"}`)
+	s := NewMidtransService(server.URL+"/", "client-key", testServerKey, nil)
+
+	tx, err := s.CreateTransaction(context.Background(), GatewayTransactionInput{
+		OrderID:  " ORDER-1 ",
+		Amount:   99000,
+		Email:    "rina@example.com",
+		FullName: "Rina Ayu Lestari",
+		PlanCode: "premium",
+		PlanName: "Premium",
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if tx.Token != "snap-token" || tx.RedirectURL == "" || tx.ClientKey != "client-key" {
+		t.Fatalf("CreateTransaction = %+v", tx)
+	}
+
+	if got.method != http.MethodPost || got.path != "/snap/v1/transactions" {
+		t.Fatalf("request %s %s", got.method, got.path)
+	}
+	if auth := got.header.Get("Authorization"); auth != basicAuth(testServerKey) {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	details := got.body["transaction_details"].(map[string]any)
+	if details["order_id"] != "ORDER-1" || details["gross_amount"] != float64(99000) {
+		t.Fatalf("transaction_details = %v", details)
+	}
+	customer := got.body["customer_details"].(map[string]any)
+	if customer["first_name"] != "Rina" || customer["last_name"] != "Ayu Lestari" {
+		t.Fatalf("customer_details = %v", customer)
+	}
+	items := got.body["item_details"].([]any)
+	if item := items[0].(map[string]any); item["name"] != "Paket Premium" || item["price"] != float64(99000) {
+		t.Fatalf("item_details = %v", items)
+	}
+}
+
+func TestMidtransCreateTransactionError(t *testing.T) {
+	server, _ := newGatewayServer(t, http.StatusUnauthorized, `{"error_messages":["Access denied"]}`)
+	s := NewMidtransService(server.URL, "client-key", testServerKey, nil)
+
+	_, err := s.CreateTransaction(context.Background(), GatewayTransactionInput{OrderID: "ORDER-1", Amount: 1})
+	if err == nil || !strings.Contains(err.Error(), "status=401") {
+		t.Fatalf("CreateTransaction err = %v, want the 401 reported", err)
+	}
+
+	if _, err := NewMidtransService(server.URL, "client-key", "", nil).CreateTransaction(context.Background(), GatewayTransactionInput{}); err == nil {
+		t.Fatal("CreateTransaction without a server key succeeded")
+	}
+}
+
+func TestMidtransGetTransactionStatus(t *testing.T) {
+	server, got := newGatewayServer(t, http.StatusOK, `{"transaction_status":"capture","fraud_status":"accept","transaction_id":"tx-1","payment_type":"credit_card"}`)
+	s := NewMidtransService(server.URL, "client-key", testServerKey, nil)
+
+	status, err := s.GetTransactionStatus(context.Background(), "ORDER 1/2")
+	if err != nil {
+		t.Fatalf("GetTransactionStatus: %v", err)
+	}
+	if got.method != http.MethodGet || got.path != "/v2/ORDER%201%2F2/status" {
+		t.Fatalf("request %s %s", got.method, got.path)
+	}
+	want := GatewayStatus{OrderID: "ORDER 1/2", TransactionID: "tx-1", PaymentType: "credit_card", RawStatus: "capture", Status: PaymentStatusPaid}
+	if status != want {
+		t.Fatalf("GetTransactionStatus = %+v, want %+v", status, want)
+	}
+
+	if _, err := s.GetTransactionStatus(context.Background(), " "); err == nil {
+		t.Fatal("GetTransactionStatus with an empty order id succeeded")
+	}
+}
+
+func TestMidtransRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusOK, body: `{"status_code":"200","status_message":"Success, refund request is approved"}`},
+		{name: "rejected in the body", status: http.StatusOK, body: `{"status_code":"412","status_message":"Transaction status cannot be updated"}`, wantErr: true},
+		{name: "http error", status: http.StatusInternalServerError, body: `{}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, got := newGatewayServer(t, tt.status, tt.body)
+			s := NewMidtransService(server.URL, "client-key", testServerKey, nil)
+
+			err := s.Refund(context.Background(), GatewayRefundInput{OrderID: "ORDER-1", Amount: 99000, Reason: " duplicate "})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Refund err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got.method != http.MethodPost || got.path != "/v2/ORDER-1/refund" {
+				t.Fatalf("request %s %s", got.method, got.path)
+			}
+			if got.body["refund_key"] != "refund-ORDER-1" || got.body["amount"] != float64(99000) || got.body["reason"] != "duplicate" {
+				t.Fatalf("refund body = %v", got.body)
+			}
+		})
+	}
+}
+
+func midtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMidtransVerifyWebhook(t *testing.T) {
+	s := NewMidtransService("", "client-key", testServerKey, nil)
+	notification := func(signature string) []byte {
+		body, _ := json.Marshal(map[string]string{
+			"order_id":           "ORDER-1",
+			"status_code":        "200",
+			"gross_amount":       "99000.00",
+			"transaction_status": "settlement",
+			"transaction_id":     "tx-1",
+			"payment_type":       "bank_transfer",
+			"signature_key":      signature,
+		})
+		return body
+	}
+	valid := midtransSignature("ORDER-1", "200", "99000.00", testServerKey)
+
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr error
+	}{
+		{name: "good signature", body: notification(valid)},
+		{name: "uppercase signature", body: notification(strings.ToUpper(valid))},
+		{name: "signed with another key", body: notification(midtransSignature("ORDER-1", "200", "99000.00", "other-key")), wantErr: ErrInvalidWebhookSignature},
+		{name: "amount changed after signing", body: notification(midtransSignature("ORDER-1", "200", "1.00", testServerKey)), wantErr: ErrInvalidWebhookSignature},
+		{name: "missing signature", body: notification(""), wantErr: ErrInvalidWebhookSignature},
+		{name: "malformed body", body: []byte(`{"order_id": "ORDER-1",`), wantErr: ErrInvalidWebhookPayload},
+		{name: "missing status", body: []byte(`{"order_id": "ORDER-1", "signature_key": "x"}`), wantErr: ErrInvalidWebhookPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := s.VerifyWebhook(http.Header{}, tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyWebhook err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := GatewayStatus{OrderID: "ORDER-1", TransactionID: "tx-1", PaymentType: "bank_transfer", RawStatus: "settlement", Status: PaymentStatusPaid}
+			if status != want {
+				t.Fatalf("VerifyWebhook = %+v, want %+v", status, want)
+			}
+		})
+	}
+
+	if _, err := NewMidtransService("", "client-key", "", nil).VerifyWebhook(http.Header{}, notification(midtransSignature("ORDER-1", "200", "99000.00", ""))); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("VerifyWebhook without a server key err = %v, want ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestNormalizeMidtransStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		fraud  string
+		want   string
+	}{
+		{"capture", "accept", PaymentStatusPaid},
+		{"capture", "", PaymentStatusPaid},
+		{"capture", "challenge", PaymentStatusPending},
+		{" Capture ", "CHALLENGE", PaymentStatusPending},
+		{"settlement", "", PaymentStatusPaid},
+		{"pending", "", PaymentStatusPending},
+		{"deny", "deny", PaymentStatusFailed},
+		{"cancel", "", PaymentStatusFailed},
+		{"expire", "", PaymentStatusFailed},
+		{"failure", "", PaymentStatusFailed},
+		{"refund", "", PaymentStatusRefunded},
+		{"partial_refund", "", PaymentStatusPaid},
+		{"chargeback", "", PaymentStatusRefunded},
+		{"partial_chargeback", "", PaymentStatusPaid},
+		{"authorize", "", PaymentStatusPending},
+		{"", "", PaymentStatusPending},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeMidtransStatus(tt.status, tt.fraud); got != tt.want {
+			t.Errorf("NormalizeMidtransStatus(%q, %q) = %q, want %q", tt.status, tt.fraud, got, tt.want)
+		}
+	}
+}
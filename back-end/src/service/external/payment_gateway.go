@@ -0,0 +1,73 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	PaymentProviderMidtrans = "midtrans"
+	PaymentProviderXendit   = "xendit"
+)
+
+// Payment statuses every gateway's own statuses are normalized to.
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusRefunded = "refunded"
+)
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookPayload   = errors.New("invalid webhook payload")
+	ErrRefundFailed            = errors.New("refund failed")
+)
+
+// PaymentGateway is a payment provider the plan checkout can send customers to.
+type PaymentGateway interface {
+	// Provider is the name stored on payments, e.g. "midtrans".
+	Provider() string
+	CreateTransaction(ctx context.Context, input GatewayTransactionInput) (GatewayTransaction, error)
+	GetTransactionStatus(ctx context.Context, orderID string) (GatewayStatus, error)
+	// VerifyWebhook authenticates a notification sent to the provider's webhook route and
+	// decodes the status it reports.
+	VerifyWebhook(header http.Header, body []byte) (GatewayStatus, error)
+	Refund(ctx context.Context, input GatewayRefundInput) error
+}
+
+type GatewayTransactionInput struct {
+	OrderID  string
+	Amount   int
+	Currency string
+	Email    string
+	FullName string
+	PlanCode string
+	PlanName string
+}
+
+// GatewayTransaction is a created transaction. Token is only set by gateways with an embedded
+// checkout, such as Midtrans Snap; the others are paid at RedirectURL.
+type GatewayTransaction struct {
+	TransactionID string
+	Token         string
+	RedirectURL   string
+	ClientKey     string
+}
+
+// GatewayStatus is the state of a transaction, with Status normalized to PaymentStatus*.
+type GatewayStatus struct {
+	OrderID       string
+	TransactionID string
+	PaymentType   string
+	RawStatus     string
+	Status        string
+}
+
+type GatewayRefundInput struct {
+	OrderID       string
+	TransactionID string
+	Amount        int
+	Reason        string
+}
@@ -0,0 +1,264 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultXenditBaseURL = "https://api.xendit.co"
+
+// XenditService takes payments through Xendit invoices: the customer pays on the hosted invoice
+// page and Xendit calls the webhook once the invoice is paid or expires.
+type XenditService struct {
+	baseURL       string
+	secretKey     string
+	callbackToken string
+	client        *http.Client
+}
+
+type xenditCreateInvoiceRequest struct {
+	ExternalID  string              `json:"external_id"`
+	Amount      int                 `json:"amount"`
+	Currency    string              `json:"currency,omitempty"`
+	PayerEmail  string              `json:"payer_email,omitempty"`
+	Description string              `json:"description"`
+	Customer    *xenditCustomer     `json:"customer,omitempty"`
+	Items       []xenditInvoiceItem `json:"items,omitempty"`
+}
+
+type xenditCustomer struct {
+	GivenNames string `json:"given_names,omitempty"`
+	Surname    string `json:"surname,omitempty"`
+	Email      string `json:"email,omitempty"`
+}
+
+type xenditInvoiceItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+// xenditInvoice is both the API's invoice object and the body of invoice callbacks.
+type xenditInvoice struct {
+	ID             string `json:"id"`
+	ExternalID     string `json:"external_id"`
+	Status         string `json:"status"`
+	InvoiceURL     string `json:"invoice_url"`
+	PaymentMethod  string `json:"payment_method"`
+	PaymentChannel string `json:"payment_channel"`
+}
+
+type xenditRefundRequest struct {
+	InvoiceID string            `json:"invoice_id"`
+	Amount    int               `json:"amount,omitempty"`
+	Reason    string            `json:"reason"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+func NewXenditService(baseURL, secretKey, callbackToken string, client *http.Client) *XenditService {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		baseURL = defaultXenditBaseURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 20 * time.Second}
+	}
+
+	return &XenditService{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		secretKey:     strings.TrimSpace(secretKey),
+		callbackToken: strings.TrimSpace(callbackToken),
+		client:        client,
+	}
+}
+
+func (s *XenditService) Provider() string {
+	return PaymentProviderXendit
+}
+
+func (s *XenditService) BaseURL() string {
+	return s.baseURL
+}
+
+func (s *XenditService) CreateTransaction(ctx context.Context, input GatewayTransactionInput) (GatewayTransaction, error) {
+	firstName, lastName := splitFullName(input.FullName)
+	planName := strings.TrimSpace(input.PlanName)
+	if planName == "" {
+		planName = strings.TrimSpace(input.PlanCode)
+	}
+	if planName == "" {
+		planName = "Undangan"
+	}
+
+	payload := xenditCreateInvoiceRequest{
+		ExternalID:  strings.TrimSpace(input.OrderID),
+		Amount:      input.Amount,
+		Currency:    strings.TrimSpace(input.Currency),
+		PayerEmail:  strings.TrimSpace(input.Email),
+		Description: "Paket " + planName,
+		Items: []xenditInvoiceItem{{
+			Name:     "Paket " + planName,
+			Quantity: 1,
+			Price:    input.Amount,
+		}},
+	}
+	if firstName != "" || payload.PayerEmail != "" {
+		payload.Customer = &xenditCustomer{GivenNames: firstName, Surname: lastName, Email: payload.PayerEmail}
+	}
+
+	var invoice xenditInvoice
+	if err := s.doJSON(ctx, http.MethodPost, "/v2/invoices", nil, payload, &invoice); err != nil {
+		return GatewayTransaction{}, err
+	}
+
+	return GatewayTransaction{
+		TransactionID: invoice.ID,
+		RedirectURL:   invoice.InvoiceURL,
+	}, nil
+}
+
+// GetTransactionStatus looks the invoice up by our order id, which is its external_id.
+func (s *XenditService) GetTransactionStatus(ctx context.Context, orderID string) (GatewayStatus, error) {
+	orderID = strings.TrimSpace(orderID)
+	if orderID == "" {
+		return GatewayStatus{}, fmt.Errorf("order id is empty")
+	}
+
+	var invoices []xenditInvoice
+	if err := s.doJSON(ctx, http.MethodGet, "/v2/invoices?external_id="+url.QueryEscape(orderID), nil, nil, &invoices); err != nil {
+		return GatewayStatus{}, err
+	}
+	if len(invoices) == 0 {
+		return GatewayStatus{}, fmt.Errorf("xendit invoice %s not found", orderID)
+	}
+
+	return invoices[0].gatewayStatus(), nil
+}
+
+// VerifyWebhook compares the x-callback-token header with the verification token from the
+// Xendit dashboard.
+func (s *XenditService) VerifyWebhook(header http.Header, body []byte) (GatewayStatus, error) {
+	token := strings.TrimSpace(header.Get("x-callback-token"))
+	if s.callbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.callbackToken)) != 1 {
+		return GatewayStatus{}, ErrInvalidWebhookSignature
+	}
+
+	var invoice xenditInvoice
+	if err := json.Unmarshal(body, &invoice); err != nil {
+		return GatewayStatus{}, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	if strings.TrimSpace(invoice.ExternalID) == "" || strings.TrimSpace(invoice.Status) == "" {
+		return GatewayStatus{}, fmt.Errorf("%w: external_id and status are required", ErrInvalidWebhookPayload)
+	}
+	return invoice.gatewayStatus(), nil
+}
+
+// Refund refunds a paid invoice. Xendit requires one of its fixed reasons, so the free-text
+// reason travels in the metadata.
+func (s *XenditService) Refund(ctx context.Context, input GatewayRefundInput) error {
+	invoiceID := strings.TrimSpace(input.TransactionID)
+	if invoiceID == "" {
+		return fmt.Errorf("xendit invoice id is empty")
+	}
+
+	payload := xenditRefundRequest{
+		InvoiceID: invoiceID,
+		Amount:    input.Amount,
+		Reason:    "OTHERS",
+	}
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		payload.Metadata = map[string]string{"reason": reason}
+	}
+	header := http.Header{}
+	header.Set("Idempotency-key", "refund-"+invoiceID)
+	return s.doJSON(ctx, http.MethodPost, "/refunds", header, payload, nil)
+}
+
+func (i xenditInvoice) gatewayStatus() GatewayStatus {
+	paymentType := strings.TrimSpace(i.PaymentMethod)
+	if channel := strings.TrimSpace(i.PaymentChannel); channel != "" {
+		paymentType = strings.Trim(paymentType+":"+channel, ":")
+	}
+	return GatewayStatus{
+		OrderID:       strings.TrimSpace(i.ExternalID),
+		TransactionID: strings.TrimSpace(i.ID),
+		PaymentType:   paymentType,
+		RawStatus:     strings.TrimSpace(i.Status),
+		Status:        NormalizeXenditStatus(i.Status),
+	}
+}
+
+// NormalizeXenditStatus maps a Xendit invoice status to a PaymentStatus*.
+func NormalizeXenditStatus(status string) string {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "PAID", "SETTLED":
+		return PaymentStatusPaid
+	case "EXPIRED":
+		return PaymentStatusFailed
+	default:
+		return PaymentStatusPending
+	}
+}
+
+func (s *XenditService) doJSON(ctx context.Context, method, path string, header http.Header, payload any, out any) error {
+	if s.secretKey == "" {
+		return fmt.Errorf("xendit secret key is empty")
+	}
+
+	requestURL := s.baseURL + "/" + strings.TrimLeft(strings.TrimSpace(path), "/")
+
+	var bodyReader io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+		bodyReader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.secretKey+":")))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request xendit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("xendit %s %s failed: status=%d body=%s", method, requestURL, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if out == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
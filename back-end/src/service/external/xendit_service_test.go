@@ -0,0 +1,182 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testXenditKey   = "xnd_development_abc"
+	testXenditToken = "callback-token"
+)
+
+func TestXenditCreateTransaction(t *testing.T) {
+	server, got := newGatewayServer(t, http.StatusOK, `{"id":"inv-1","external_id":"ORDER-1","status":"PENDING","invoice_url":"https://checkout.xendit.co/web/inv-1"}`)
+	s := NewXenditService(server.URL+"/", testXenditKey, testXenditToken, nil)
+
+	tx, err := s.CreateTransaction(context.Background(), GatewayTransactionInput{
+		OrderID:  "ORDER-1",
+		Amount:   99000,
+		Currency: "IDR",
+		Email:    "rina@example.com",
+		FullName: "Rina Ayu",
+		PlanCode: "premium",
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if tx.TransactionID != "inv-1" || tx.RedirectURL != "https://checkout.xendit.co/web/inv-1" || tx.Token != "" {
+		t.Fatalf("CreateTransaction = %+v", tx)
+	}
+
+	if got.method != http.MethodPost || got.path != "/v2/invoices" {
+		t.Fatalf("request %s %s", got.method, got.path)
+	}
+	if auth := got.header.Get("Authorization"); auth != basicAuth(testXenditKey) {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	if got.body["external_id"] != "ORDER-1" || got.body["amount"] != float64(99000) || got.body["currency"] != "IDR" || got.body["description"] != "Paket premium" {
+		t.Fatalf("invoice body = %v", got.body)
+	}
+	customer := got.body["customer"].(map[string]any)
+	if customer["given_names"] != "Rina" || customer["surname"] != "Ayu" || customer["email"] != "rina@example.com" {
+		t.Fatalf("customer = %v", customer)
+	}
+}
+
+func TestXenditCreateTransactionError(t *testing.T) {
+	server, _ := newGatewayServer(t, http.StatusBadRequest, `{"error_code":"API_VALIDATION_ERROR"}`)
+	s := NewXenditService(server.URL, testXenditKey, testXenditToken, nil)
+
+	_, err := s.CreateTransaction(context.Background(), GatewayTransactionInput{OrderID: "ORDER-1", Amount: 1})
+	if err == nil || !strings.Contains(err.Error(), "API_VALIDATION_ERROR") {
+		t.Fatalf("CreateTransaction err = %v, want the gateway's error", err)
+	}
+	if _, err := NewXenditService(server.URL, "", testXenditToken, nil).CreateTransaction(context.Background(), GatewayTransactionInput{}); err == nil {
+		t.Fatal("CreateTransaction without a secret key succeeded")
+	}
+}
+
+func TestXenditGetTransactionStatus(t *testing.T) {
+	server, got := newGatewayServer(t, http.StatusOK, `[{"id":"inv-1","external_id":"ORDER 1&2","status":"SETTLED","payment_method":"BANK_TRANSFER","payment_channel":"BCA"}]`)
+	s := NewXenditService(server.URL, testXenditKey, testXenditToken, nil)
+
+	status, err := s.GetTransactionStatus(context.Background(), "ORDER 1&2")
+	if err != nil {
+		t.Fatalf("GetTransactionStatus: %v", err)
+	}
+	if got.method != http.MethodGet || got.path != "/v2/invoices" || got.query != "external_id=ORDER+1%262" {
+		t.Fatalf("request %s %s?%s", got.method, got.path, got.query)
+	}
+	want := GatewayStatus{OrderID: "ORDER 1&2", TransactionID: "inv-1", PaymentType: "BANK_TRANSFER:BCA", RawStatus: "SETTLED", Status: PaymentStatusPaid}
+	if status != want {
+		t.Fatalf("GetTransactionStatus = %+v, want %+v", status, want)
+	}
+}
+
+func TestXenditGetTransactionStatusNotFound(t *testing.T) {
+	server, _ := newGatewayServer(t, http.StatusOK, `[]`)
+	s := NewXenditService(server.URL, testXenditKey, testXenditToken, nil)
+
+	if _, err := s.GetTransactionStatus(context.Background(), "ORDER-1"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("GetTransactionStatus err = %v, want not found", err)
+	}
+}
+
+func TestXenditRefund(t *testing.T) {
+	server, got := newGatewayServer(t, http.StatusOK, `{"id":"rfd-1","status":"PENDING"}`)
+	s := NewXenditService(server.URL, testXenditKey, testXenditToken, nil)
+
+	if err := s.Refund(context.Background(), GatewayRefundInput{OrderID: "ORDER-1", TransactionID: "inv-1", Amount: 99000, Reason: "duplicate"}); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if got.method != http.MethodPost || got.path != "/refunds" {
+		t.Fatalf("request %s %s", got.method, got.path)
+	}
+	if key := got.header.Get("Idempotency-Key"); key != "refund-inv-1" {
+		t.Fatalf("Idempotency-Key = %q", key)
+	}
+	metadata, _ := got.body["metadata"].(map[string]any)
+	if got.body["invoice_id"] != "inv-1" || got.body["reason"] != "OTHERS" || metadata["reason"] != "duplicate" {
+		t.Fatalf("refund body = %v", got.body)
+	}
+
+	if err := s.Refund(context.Background(), GatewayRefundInput{OrderID: "ORDER-1"}); err == nil {
+		t.Fatal("Refund without an invoice id succeeded")
+	}
+
+	failing, _ := newGatewayServer(t, http.StatusForbidden, `{"error_code":"REFUND_NOT_SUPPORTED"}`)
+	if err := NewXenditService(failing.URL, testXenditKey, testXenditToken, nil).Refund(context.Background(), GatewayRefundInput{TransactionID: "inv-1"}); err == nil {
+		t.Fatal("Refund rejected by Xendit succeeded")
+	}
+}
+
+func TestXenditVerifyWebhook(t *testing.T) {
+	s := NewXenditService("", testXenditKey, testXenditToken, nil)
+	body := []byte(`{"id":"inv-1","external_id":"ORDER-1","status":"PAID","payment_method":"EWALLET","payment_channel":"OVO"}`)
+	header := func(token string) http.Header {
+		h := http.Header{}
+		if token != "" {
+			h.Set("X-Callback-Token", token)
+		}
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    []byte
+		wantErr error
+	}{
+		{name: "good token", header: header(testXenditToken), body: body},
+		{name: "wrong token", header: header("guess"), body: body, wantErr: ErrInvalidWebhookSignature},
+		{name: "missing token", header: header(""), body: body, wantErr: ErrInvalidWebhookSignature},
+		{name: "malformed body", header: header(testXenditToken), body: []byte(`{"external_id":`), wantErr: ErrInvalidWebhookPayload},
+		{name: "missing status", header: header(testXenditToken), body: []byte(`{"external_id":"ORDER-1"}`), wantErr: ErrInvalidWebhookPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := s.VerifyWebhook(tt.header, tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyWebhook err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := GatewayStatus{OrderID: "ORDER-1", TransactionID: "inv-1", PaymentType: "EWALLET:OVO", RawStatus: "PAID", Status: PaymentStatusPaid}
+			if status != want {
+				t.Fatalf("VerifyWebhook = %+v, want %+v", status, want)
+			}
+		})
+	}
+
+	// Without a configured token every callback is refused, even one with an empty header.
+	if _, err := NewXenditService("", testXenditKey, "", nil).VerifyWebhook(header(""), body); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Fatalf("VerifyWebhook without a callback token err = %v, want ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestNormalizeXenditStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"PAID", PaymentStatusPaid},
+		{"SETTLED", PaymentStatusPaid},
+		{" paid ", PaymentStatusPaid},
+		{"EXPIRED", PaymentStatusFailed},
+		{"PENDING", PaymentStatusPending},
+		{"", PaymentStatusPending},
+		{"UNKNOWN", PaymentStatusPending},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeXenditStatus(tt.status); got != tt.want {
+			t.Errorf("NormalizeXenditStatus(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
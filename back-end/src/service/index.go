@@ -2,11 +2,14 @@ package service
 
 import (
 	"github.com/proxima-labs/wedding-invitation-back-end/src/auth"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/moderation"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/realtime"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
 	adminService "github.com/proxima-labs/wedding-invitation-back-end/src/service/admin"
 	customerService "github.com/proxima-labs/wedding-invitation-back-end/src/service/customer"
 	"github.com/proxima-labs/wedding-invitation-back-end/src/service/external"
 	publicService "github.com/proxima-labs/wedding-invitation-back-end/src/service/public"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
 )
 
 type Registry struct {
@@ -17,33 +20,45 @@ type Registry struct {
 	CustomerPayment     *customerService.PaymentService
 	CustomerPlan        *customerService.PlanService
 	CustomerPlanEnforce *customerService.PlanEnforcer
+	Guest               *customerService.GuestService
+	CustomerRsvp        *customerService.RsvpService
+	CustomerWish        *customerService.WishService
+	CustomerMedia       *customerService.MediaService
+	CustomDomain        *customerService.CustomDomainService
 	PublicPlan          *publicService.PlanService
 	AdminAuth           *adminService.AuthService
 	AdminUser           *adminService.UserService
 	AdminInvitation     *adminService.InvitationService
 	AdminCustomer       *adminService.CustomerService
 	AdminPayment        *adminService.PaymentService
+	AdminRsvp           *adminService.RsvpService
 }
 
-func NewRegistry(repos repository.Registry, jwtConfig auth.Config, customerJwtConfig auth.Config, midtransService *external.MidtransService) Registry {
-	customerSvc := &customerService.CustomerService{Repo: repos.Customer}
+func NewRegistry(repos repository.Registry, jwtConfig auth.Config, customerJwtConfig auth.Config, paymentGateways []external.PaymentGateway, checkoutProvider string, manualTransfer *customerService.ManualTransferAccount, moderator moderation.Checker, broker realtime.Broker, mediaStorage storage.Storage, receiptStorage storage.Storage, mediaQueue customerService.MediaQueue, baseDomains []string) Registry {
+	customerSvc := &customerService.CustomerService{Repo: repos.Customer, DomainRepo: repos.CustomDomain, BaseDomains: baseDomains}
 	customerAuthSvc := &customerService.AuthService{
 		CustomerRepo:     repos.Customer,
 		InvitationRepo:   repos.Invitation,
 		RefreshTokenRepo: repos.CustomerRefreshToken,
 		Config:           customerJwtConfig,
 	}
-	invitationSvc := &customerService.InvitationService{Repo: repos.Invitation, CustomerRepo: repos.Customer}
-	publicInvitationSvc := &customerService.PublicInvitationService{InvitationRepo: repos.Invitation, RsvpRepo: repos.Rsvp, WishRepo: repos.Wish}
-	paymentSvc := &customerService.PaymentService{CustomerRepo: repos.Customer, PlanRepo: repos.Plan, PaymentRepo: repos.Payment, Midtrans: midtransService}
+	planEnforcerSvc := &customerService.PlanEnforcer{PaymentRepo: repos.Payment, MediaRepo: repos.Media, Storage: mediaStorage, InvitationRepo: repos.Invitation, RsvpRepo: repos.Rsvp, WishRepo: repos.Wish, DomainRepo: repos.CustomDomain, CustomerRepo: repos.Customer}
+	invitationSvc := &customerService.InvitationService{Repo: repos.Invitation, CustomerRepo: repos.Customer, Enforcer: planEnforcerSvc}
+	publicInvitationSvc := &customerService.PublicInvitationService{InvitationRepo: repos.Invitation, RsvpRepo: repos.Rsvp, WishRepo: repos.Wish, GuestRepo: repos.Guest, Moderator: moderator, Broker: broker, Enforcer: planEnforcerSvc}
+	paymentSvc := &customerService.PaymentService{CustomerRepo: repos.Customer, PlanRepo: repos.Plan, PaymentRepo: repos.Payment, Gateways: paymentGateways, CheckoutProvider: checkoutProvider, ManualTransfer: manualTransfer, Receipts: receiptStorage, Enforcer: planEnforcerSvc}
 	planSvc := &customerService.PlanService{Repo: repos.Plan}
 	publicPlanSvc := &publicService.PlanService{Repo: repos.Plan}
-	planEnforcerSvc := &customerService.PlanEnforcer{PaymentRepo: repos.Payment}
+	guestSvc := &customerService.GuestService{Repo: repos.Guest, InvitationRepo: repos.Invitation}
+	rsvpSvc := &customerService.RsvpService{Repo: repos.Rsvp, InvitationRepo: repos.Invitation}
+	wishSvc := &customerService.WishService{Repo: repos.Wish, InvitationRepo: repos.Invitation, Broker: broker}
+	customDomainSvc := &customerService.CustomDomainService{Repo: repos.CustomDomain, CustomerRepo: repos.Customer, Enforcer: planEnforcerSvc}
+	mediaSvc := &customerService.MediaService{Repo: repos.Media, InvitationRepo: repos.Invitation, Storage: mediaStorage, Enforcer: planEnforcerSvc, Queue: mediaQueue}
 	adminAuthSvc := &adminService.AuthService{Repo: repos.User, Config: jwtConfig}
 	adminUserSvc := &adminService.UserService{Repo: repos.User}
-	adminInvitationSvc := &adminService.InvitationService{Repo: repos.Invitation, CustomerRepo: repos.Customer}
+	adminInvitationSvc := &adminService.InvitationService{Repo: repos.Invitation, CustomerRepo: repos.Customer, Retention: planEnforcerSvc}
 	adminCustomerSvc := &adminService.CustomerService{Repo: repos.Customer}
-	adminPaymentSvc := &adminService.PaymentService{Repo: repos.Payment}
+	adminPaymentSvc := &adminService.PaymentService{Repo: repos.Payment, Receipts: receiptStorage, Settler: paymentSvc}
+	adminRsvpSvc := &adminService.RsvpService{Repo: repos.Rsvp, InvitationRepo: repos.Invitation}
 
 	return Registry{
 		Customer:            customerSvc,
@@ -53,11 +68,17 @@ func NewRegistry(repos repository.Registry, jwtConfig auth.Config, customerJwtCo
 		CustomerPayment:     paymentSvc,
 		CustomerPlan:        planSvc,
 		CustomerPlanEnforce: planEnforcerSvc,
+		Guest:               guestSvc,
+		CustomerRsvp:        rsvpSvc,
+		CustomerWish:        wishSvc,
+		CustomerMedia:       mediaSvc,
+		CustomDomain:        customDomainSvc,
 		PublicPlan:          publicPlanSvc,
 		AdminAuth:           adminAuthSvc,
 		AdminUser:           adminUserSvc,
 		AdminInvitation:     adminInvitationSvc,
 		AdminCustomer:       adminCustomerSvc,
 		AdminPayment:        adminPaymentSvc,
+		AdminRsvp:           adminRsvpSvc,
 	}
 }
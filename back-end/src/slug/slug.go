@@ -3,6 +3,7 @@ package slug
 import (
 	"context"
 	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"strings"
@@ -33,6 +34,18 @@ func GenerateID() (string, error) {
 		b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
 }
 
+// GenerateToken returns a random URL-safe token built from size bytes.
+func GenerateToken(size int) (string, error) {
+	if size <= 0 {
+		size = 12
+	}
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
 // ShortID returns the first 6 hex chars of a UUID (dashes removed).
 func ShortID(id string) string {
 	clean := strings.ReplaceAll(id, "-", "")
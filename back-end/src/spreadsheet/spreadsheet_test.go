@@ -0,0 +1,106 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Pak Hendra", want: "Pak Hendra"},
+		{value: "", want: ""},
+		{value: `=HYPERLINK("https://evil.example","klik")`, want: `'=HYPERLINK("https://evil.example","klik")`},
+		{value: "+6281234", want: "'+6281234"},
+		{value: "-2+3", want: "'-2+3"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\t=1+1", want: "'\t=1+1"},
+		{value: "\r=1+1", want: "'\r=1+1"},
+		{value: "Budi = Ani", want: "Budi = Ani"},
+		{value: "'quoted", want: "'quoted"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeFormula(tt.value); got != tt.want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEscapedRowsReadBack(t *testing.T) {
+	row := []string{"=cmd|' /C calc'!A0", "+6281234", "'quoted", "Pak Hendra"}
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = EscapeFormula(value)
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			if err := w.Write(escaped); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			rows, err := ReadRows(format, buf.Bytes())
+			if err != nil {
+				t.Fatalf("ReadRows: %v", err)
+			}
+			if want := [][]string{row}; !reflect.DeepEqual(rows, want) {
+				t.Fatalf("ReadRows = %q, want %q", rows, want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{name: "comma", data: "name,phone\nPak Hendra,0812\n", want: [][]string{{"name", "phone"}, {"Pak Hendra", "0812"}}},
+		{name: "semicolon with bom", data: "\xef\xbb\xbfname;phone\nPak Hendra;0812\n", want: [][]string{{"name", "phone"}, {"Pak Hendra", "0812"}}},
+		{name: "ragged rows", data: "name,phone,seat\nPak Hendra\n", want: [][]string{{"name", "phone", "seat"}, {"Pak Hendra"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadRows(FormatCSV, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ReadRows: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Fatalf("ReadRows = %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     []byte
+		want     string
+	}{
+		{filename: "tamu.csv", data: []byte("PK\x03\x04"), want: FormatCSV},
+		{filename: "tamu.XLSX", want: FormatXLSX},
+		{filename: "tamu", data: []byte("PK\x03\x04rest"), want: FormatXLSX},
+		{filename: "tamu", data: []byte("name,phone"), want: FormatCSV},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.filename, tt.data); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sheetNS = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"`
+
+// buildXLSX zips the given parts into a workbook the way a spreadsheet app would save it.
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, body := range parts {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("zip %s: %v", name, err)
+		}
+		if _, err := f.Write([]byte(body)); err != nil {
+			t.Fatalf("zip %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func sheetXML(rows string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><worksheet ` + sheetNS + `><sheetData>` + rows + `</sheetData></worksheet>`
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	// 30 columns reach AD, past the single-letter names.
+	wide := make([]string, 30)
+	for i := range wide {
+		wide[i] = columnName(i)
+	}
+	rows := [][]string{
+		{"name", "phone", "seat"},
+		{"Pak Hendra & Bu Sari", "0812 3456", "<A3>"},
+		{"Budi", "", "B1"},
+		{"  leading space", "line\nbreak", "émoji 🎉"},
+		wide,
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got := DetectFormat("guests", buf.Bytes()); got != FormatXLSX {
+		t.Fatalf("DetectFormat = %q, want xlsx", got)
+	}
+	got, err := ReadRows(FormatXLSX, buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadRows: %v", err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("ReadRows = %q, want %q", got, rows)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	workbook := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0"?><workbook ` + sheetNS + ` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+			`<sheet name="Tamu" sheetId="1" r:id="rId3"/><sheet name="Lain" sheetId="2" r:id="rId4"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId4" Target="worksheets/sheet1.xml"/><Relationship Id="rId3" Target="/xl/worksheets/tamu.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0"?><sst ` + sheetNS + `>` +
+			`<si><t>name</t></si><si><t>phone</t></si><si><r><t>Pak </t></r><r><t>Hendra</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="A1" t="inlineStr"><is><t>wrong sheet</t></is></c></row>`),
+	}
+
+	tests := []struct {
+		name  string
+		sheet string
+		want  [][]string
+	}{
+		{
+			name:  "shared strings",
+			sheet: `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>6.2812345678E12</v></c></row>`,
+			want:  [][]string{{"name", "phone"}, {"Pak Hendra", "6281234567800"}},
+		},
+		{
+			name:  "inline strings and booleans",
+			sheet: `<row r="1"><c r="A1" t="inlineStr"><is><t>Budi</t></is></c><c r="B1" t="b"><v>1</v></c><c r="C1" t="n"><v>3.0</v></c><c r="D1" t="str"><v>A1+B1</v></c></row>`,
+			want:  [][]string{{"Budi", "TRUE", "3", "A1+B1"}},
+		},
+		{
+			name:  "empty cells in the middle of a row",
+			sheet: `<row r="1"><c r="A1" t="inlineStr"><is><t>Budi</t></is></c><c r="D1"><v>2</v></c></row>`,
+			want:  [][]string{{"Budi", "", "", "2"}},
+		},
+		{
+			name:  "columns past Z",
+			sheet: `<row r="1"><c r="Z1"><v>26</v></c><c r="AA1"><v>27</v></c><c r="AB1" t="inlineStr"><is><t>28</t></is></c></row>`,
+			want:  [][]string{append(make([]string, 25), "26", "27", "28")},
+		},
+		{
+			name:  "cells without references",
+			sheet: `<row><c t="inlineStr"><is><t>a</t></is></c><c t="inlineStr"><is><t>b</t></is></c></row>`,
+			want:  [][]string{{"a", "b"}},
+		},
+		{
+			name:  "shared string index out of range",
+			sheet: `<row r="1"><c r="A1" t="s"><v>9</v></c><c r="B1" t="s"><v>x</v></c></row>`,
+			want:  [][]string{{"", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := map[string]string{"xl/worksheets/tamu.xml": sheetXML(tt.sheet)}
+			for name, body := range workbook {
+				parts[name] = body
+			}
+
+			got, err := ReadRows(FormatXLSX, buildXLSX(t, parts))
+			if err != nil {
+				t.Fatalf("ReadRows: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ReadRows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXWithoutWorkbook(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/worksheets/sheet1.xml": sheetXML(`<row r="1"><c r="A1" t="inlineStr"><is><t>Budi</t></is></c></row>`),
+	})
+
+	got, err := ReadRows(FormatXLSX, data)
+	if err != nil {
+		t.Fatalf("ReadRows: %v", err)
+	}
+	if want := [][]string{{"Budi"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadRows = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXErrors(t *testing.T) {
+	valid := buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": sheetXML("")})
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "not a zip", data: []byte("name,phone\nBudi,0812"), want: "open xlsx"},
+		{name: "truncated zip", data: valid[:len(valid)/2], want: "open xlsx"},
+		{name: "zip signature only", data: []byte("PK\x03\x04garbage"), want: "open xlsx"},
+		{name: "no worksheet", data: buildXLSX(t, map[string]string{"docProps/app.xml": "<Properties/>"}), want: "worksheet not found"},
+		{name: "broken worksheet", data: buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": "<worksheet><sheetData><row>"}), want: "read worksheet"},
+		{name: "broken shared strings", data: buildXLSX(t, map[string]string{
+			"xl/sharedStrings.xml":     "<sst><si>",
+			"xl/worksheets/sheet1.xml": sheetXML(""),
+		}), want: "read shared strings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadRows(FormatXLSX, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ReadRows err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnNames(t *testing.T) {
+	tests := []struct {
+		idx  int
+		name string
+	}{
+		{0, "A"}, {25, "Z"}, {26, "AA"}, {27, "AB"}, {51, "AZ"}, {52, "BA"}, {701, "ZZ"}, {702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := columnName(tt.idx); got != tt.name {
+			t.Errorf("columnName(%d) = %q, want %q", tt.idx, got, tt.name)
+		}
+		if got, ok := columnIndex(tt.name + "12"); !ok || got != tt.idx {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", tt.name+"12", got, ok, tt.idx)
+		}
+	}
+	if _, ok := columnIndex("12"); ok {
+		t.Error("columnIndex accepted a reference without a column")
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded media files. Keys are slash-separated paths such as
+// "<customer_id>/<random>.jpg"; URL turns a key into the address the front-end embeds in content.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	// KeyFromURL reverses URL. It reports false for addresses this storage did not hand out.
+	KeyFromURL(rawURL string) (string, bool)
+}
+
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
+
+func keyFromURL(base, rawURL string) (string, bool) {
+	prefix := strings.TrimRight(base, "/") + "/"
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(rawURL, prefix)
+	if i := strings.IndexAny(key, "?#"); i >= 0 {
+		key = key[:i]
+	}
+	if !validKey(key) {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/proxima-labs/wedding-invitation-back-end/src/imaging"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/model"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/repository"
+	"github.com/proxima-labs/wedding-invitation-back-end/src/storage"
+)
+
+const (
+	DefaultMediaWorkers = 2
+	mediaQueueSize      = 256
+	mediaSweepInterval  = time.Minute
+	// A claim older than this is assumed to belong to a worker that died mid-job.
+	mediaStaleAfter = 10 * time.Minute
+	mediaJobTimeout = 2 * time.Minute
+)
+
+// MediaPool renders resized variants of uploaded photos in the background. Decoding a phone
+// photo takes tens of megabytes, so a fixed number of workers keeps memory bounded; uploads only
+// enqueue an id and return. Photos whose id was dropped because the queue was full, or that were
+// pending when the process stopped, are picked up by a periodic sweep.
+type MediaPool struct {
+	Repo    *repository.MediaRepository
+	Storage storage.Storage
+	Workers int
+
+	queue chan string
+	once  sync.Once
+}
+
+// Enqueue schedules a photo for processing without blocking. It reports false when the queue is
+// full; the sweep will find the photo later.
+func (p *MediaPool) Enqueue(id string) bool {
+	select {
+	case p.jobs() <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run processes photos until ctx is done, then waits for jobs in progress to finish.
+func (p *MediaPool) Run(ctx context.Context) {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = DefaultMediaWorkers
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-p.jobs():
+					p.process(id)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(mediaSweepInterval)
+	defer ticker.Stop()
+
+	p.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			p.sweep(ctx)
+		}
+	}
+}
+
+func (p *MediaPool) jobs() chan string {
+	p.once.Do(func() {
+		p.queue = make(chan string, mediaQueueSize)
+	})
+	return p.queue
+}
+
+func (p *MediaPool) sweep(ctx context.Context) {
+	ids, err := p.Repo.ListUnprocessedIDs(ctx, time.Now().Add(-mediaStaleAfter), mediaQueueSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("media worker: list pending: %v", err)
+		}
+		return
+	}
+	for _, id := range ids {
+		if !p.Enqueue(id) {
+			return
+		}
+	}
+}
+
+// process runs with its own context so a job started before shutdown is allowed to finish.
+func (p *MediaPool) process(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mediaJobTimeout)
+	defer cancel()
+
+	item, ok, err := p.Repo.Claim(ctx, id, time.Now().Add(-mediaStaleAfter))
+	if err != nil {
+		log.Printf("media worker: claim %s: %v", id, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	if err := p.render(ctx, item); err != nil {
+		log.Printf("media worker: %s: %v", id, err)
+		if markErr := p.Repo.MarkFailed(ctx, id, err.Error()); markErr != nil {
+			log.Printf("media worker: mark %s failed: %v", id, markErr)
+		}
+	}
+}
+
+func (p *MediaPool) render(ctx context.Context, item model.Media) error {
+	data, err := p.Storage.Get(ctx, item.StorageKey)
+	if err != nil {
+		return fmt.Errorf("read original: %w", err)
+	}
+
+	result, err := imaging.Process(data)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(item.StorageKey, path.Ext(item.StorageKey))
+	variants := make([]model.MediaVariant, 0, len(result.Variants))
+	for _, variant := range result.Variants {
+		key := base + "_" + variant.Name + variant.Ext
+		if err := p.Storage.Put(ctx, key, variant.ContentType, variant.Data); err != nil {
+			p.discard(ctx, variants)
+			return fmt.Errorf("store %s: %w", variant.Name, err)
+		}
+		variants = append(variants, model.MediaVariant{
+			Name:        variant.Name,
+			URL:         p.Storage.URL(key),
+			StorageKey:  key,
+			Width:       variant.Width,
+			Height:      variant.Height,
+			ContentType: variant.ContentType,
+			SizeBytes:   int64(len(variant.Data)),
+		})
+	}
+
+	encoded, err := json.Marshal(variants)
+	if err != nil {
+		p.discard(ctx, variants)
+		return err
+	}
+	ok, err := p.Repo.MarkProcessed(ctx, item.ID, result.Width, result.Height, encoded)
+	if err != nil || !ok {
+		// Nothing points at the variants, e.g. because the photo was deleted meanwhile.
+		p.discard(ctx, variants)
+	}
+	return err
+}
+
+// discard removes stored variants that no media row refers to.
+func (p *MediaPool) discard(ctx context.Context, variants []model.MediaVariant) {
+	for _, variant := range variants {
+		if err := p.Storage.Delete(ctx, variant.StorageKey); err != nil {
+			log.Printf("media worker: delete orphaned %s: %v", variant.StorageKey, err)
+		}
+	}
+}